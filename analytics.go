@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	defaultAnalyticsDays = 7
+	maxAnalyticsDays     = 365
+	analyticsTopN        = 10
+)
+
+// queryCount is a query and how often it was searched.
+type queryCount struct {
+	query string
+	count int
+}
+
+// registerQueryAnalyticsTool creates and registers the query_analytics tool with the server.
+func registerQueryAnalyticsTool(s *server.MCPServer, history *queryHistory) {
+	s.AddTool(createQueryAnalyticsTool(), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleQueryAnalyticsRequest(ctx, request, history)
+	})
+}
+
+// createQueryAnalyticsTool creates and configures the query_analytics tool.
+func createQueryAnalyticsTool() mcp.Tool {
+	return mcp.NewTool("query_analytics",
+		mcp.WithDescription("Summarize logged searches: most searched queries, queries with no results and average result counts"),
+		mcp.WithNumber("days",
+			mcp.Description(fmt.Sprintf("Time window in days, counting back from now (max %d, default %d)", maxAnalyticsDays, defaultAnalyticsDays)),
+		),
+	)
+}
+
+// handleQueryAnalyticsRequest processes a query_analytics tool request.
+func handleQueryAnalyticsRequest(_ context.Context,
+	request mcp.CallToolRequest,
+	history *queryHistory,
+) (*mcp.CallToolResult, error) {
+	days := defaultAnalyticsDays
+	if daysFloat, ok := request.Params.Arguments["days"].(float64); ok {
+		days = int(daysFloat)
+		if days < 1 || days > maxAnalyticsDays {
+			return nil, fmt.Errorf("days must be between 1 and %d", maxAnalyticsDays)
+		}
+	}
+
+	records, err := history.since(time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read query history: %v", err)
+	}
+
+	return mcp.NewToolResultText(formatQueryAnalytics(records, days)), nil
+}
+
+// formatQueryAnalytics aggregates records into a report.
+func formatQueryAnalytics(records []queryRecord, days int) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "Search analytics for the last %d days\n\n", days)
+
+	if len(records) == 0 {
+		sb.WriteString("No searches logged in this window.\n")
+
+		return sb.String()
+	}
+
+	searched := map[string]int{}
+	zeroResult := map[string]int{}
+	totalResults := 0
+
+	for _, record := range records {
+		// Group trivially different spellings of the same query
+		query := strings.Join(strings.Fields(strings.ToLower(record.Query)), " ")
+		searched[query]++
+		totalResults += record.Results
+
+		if record.Results == 0 {
+			zeroResult[query]++
+		}
+	}
+
+	fmt.Fprintf(&sb, "Searches: %d (%d distinct queries)\n", len(records), len(searched))
+	fmt.Fprintf(&sb, "Average results per search: %.1f\n", float64(totalResults)/float64(len(records)))
+	fmt.Fprintf(&sb, "Searches with no results: %d\n", sumCounts(zeroResult))
+
+	sb.WriteString("\nMost searched:\n")
+	for i, qc := range topQueries(searched, analyticsTopN) {
+		fmt.Fprintf(&sb, "%d. %s (%d)\n", i+1, qc.query, qc.count)
+	}
+
+	if len(zeroResult) > 0 {
+		sb.WriteString("\nZero-result queries:\n")
+		for i, qc := range topQueries(zeroResult, analyticsTopN) {
+			fmt.Fprintf(&sb, "%d. %s (%d)\n", i+1, qc.query, qc.count)
+		}
+	}
+
+	return sb.String()
+}
+
+// topQueries returns the n most frequent queries, ties broken alphabetically.
+func topQueries(counts map[string]int, n int) []queryCount {
+	list := make([]queryCount, 0, len(counts))
+	for query, count := range counts {
+		list = append(list, queryCount{query, count})
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].count != list[j].count {
+			return list[i].count > list[j].count
+		}
+
+		return list[i].query < list[j].query
+	})
+
+	if len(list) > n {
+		list = list[:n]
+	}
+
+	return list
+}
+
+// sumCounts adds up all counts.
+func sumCounts(counts map[string]int) int {
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+
+	return total
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sentinel errors for API rejections callers may want to handle, matched
+// with errors.Is against the errors searches return.
+var (
+	// errQuotaExceeded matches any *quotaError.
+	errQuotaExceeded = errors.New("search API quota exceeded")
+	// errInvalidAPIKey matches rejections of the configured API key.
+	errInvalidAPIKey = errors.New("search API key invalid")
+)
+
+// quotaResetZone is the time zone in which Google resets daily API quotas.
+var quotaResetZone = loadQuotaResetZone()
+
+// apiErrorResponse is the error body returned by Google APIs.
+type apiErrorResponse struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Status  string `json:"status"`
+		Errors  []struct {
+			Reason string `json:"reason"`
+		} `json:"errors"`
+		Details []struct {
+			Reason   string            `json:"reason"`
+			Metadata map[string]string `json:"metadata"`
+		} `json:"details"`
+	} `json:"error"`
+}
+
+// apiStatusError is a non-200 response from the Custom Search API, with the
+// details of Google's error body when it has one.
+type apiStatusError struct {
+	StatusCode int
+	// Status is Google's error status, e.g. RESOURCE_EXHAUSTED.
+	Status string
+	// Reason is the machine-readable cause, e.g. rateLimitExceeded.
+	Reason string
+	// Message is Google's human-readable explanation.
+	Message string
+	// Body is the raw response body.
+	Body string
+}
+
+// Error implements error.
+func (e *apiStatusError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("API returned non-200 status: %d - %s", e.StatusCode, e.Body)
+	}
+
+	return fmt.Sprintf("API returned non-200 status: %d - %s", e.StatusCode, e.Message)
+}
+
+// Is reports whether the rejection matches one of the sentinel errors.
+func (e *apiStatusError) Is(target error) bool {
+	return target == errInvalidAPIKey && (e.Reason == "keyInvalid" || e.Reason == "API_KEY_INVALID")
+}
+
+// quotaError is an apiStatusError caused by exhausting a quota.
+type quotaError struct {
+	*apiStatusError
+	// ResetAt is when the quota is expected to allow requests again.
+	ResetAt time.Time
+}
+
+// Error implements error.
+func (e *quotaError) Error() string {
+	return fmt.Sprintf("%v (quota resets around %s)", e.apiStatusError, e.ResetAt.UTC().Format(time.RFC3339))
+}
+
+// Is reports whether target is errQuotaExceeded.
+func (e *quotaError) Is(target error) bool {
+	return target == errQuotaExceeded
+}
+
+// Unwrap returns the underlying apiStatusError.
+func (e *quotaError) Unwrap() error {
+	return e.apiStatusError
+}
+
+// newAPIStatusError builds the error for a non-200 response, a *quotaError
+// if a quota ran out and an *apiStatusError otherwise.
+func newAPIStatusError(resp *http.Response, body []byte, now time.Time) error {
+	statusErr := &apiStatusError{StatusCode: resp.StatusCode, Body: string(body)}
+
+	var (
+		parsed     apiErrorResponse
+		quotaLimit string
+	)
+
+	if json.Unmarshal(body, &parsed) == nil {
+		statusErr.Status = parsed.Error.Status
+		statusErr.Message = parsed.Error.Message
+
+		if len(parsed.Error.Errors) > 0 {
+			statusErr.Reason = parsed.Error.Errors[0].Reason
+		}
+
+		// Prefer the more specific reason of the error details, if any
+		for _, detail := range parsed.Error.Details {
+			if detail.Reason != "" {
+				statusErr.Reason = detail.Reason
+				quotaLimit = detail.Metadata["quota_limit"]
+			}
+		}
+	}
+
+	if resp.StatusCode != http.StatusTooManyRequests && statusErr.Status != "RESOURCE_EXHAUSTED" {
+		return statusErr
+	}
+
+	return &quotaError{apiStatusError: statusErr, ResetAt: quotaResetAt(resp.Header, quotaLimit, now)}
+}
+
+// quotaResetAt estimates when an exhausted quota resets: after Retry-After
+// if the API sent one, at the next minute for per-minute limits, and at
+// midnight Pacific time for the daily query limit.
+func quotaResetAt(header http.Header, quotaLimit string, now time.Time) time.Time {
+	if seconds, err := strconv.Atoi(header.Get("Retry-After")); err == nil && seconds >= 0 {
+		return now.Add(time.Duration(seconds) * time.Second)
+	}
+
+	if strings.Contains(strings.ToLower(quotaLimit), "perminute") {
+		return now.Truncate(time.Minute).Add(time.Minute)
+	}
+
+	local := now.In(quotaResetZone)
+
+	return time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, quotaResetZone)
+}
+
+// loadQuotaResetZone returns US Pacific time, approximated without daylight
+// saving time if the system has no time zone database.
+func loadQuotaResetZone() *time.Location {
+	zone, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		return time.FixedZone("PST", -8*60*60)
+	}
+
+	return zone
+}
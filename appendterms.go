@@ -0,0 +1,27 @@
+package main
+
+import "os"
+
+// loadAppendTerms reads GOOGLE_SEARCH_APPEND_TERMS, terms appended to every
+// search (the API's hq parameter) without changing the query itself.
+func loadAppendTerms() (string, error) {
+	return extractTerms(map[string]interface{}{"GOOGLE_SEARCH_APPEND_TERMS": os.Getenv("GOOGLE_SEARCH_APPEND_TERMS")}, "GOOGLE_SEARCH_APPEND_TERMS")
+}
+
+// extractAppendTerms extracts and validates the append_terms parameter,
+// added after the server's own append terms.
+func extractAppendTerms(arguments map[string]interface{}, serverTerms string) (string, error) {
+	terms, err := extractTerms(arguments, "append_terms")
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case serverTerms == "":
+		return terms, nil
+	case terms == "":
+		return serverTerms, nil
+	}
+
+	return extractTerms(map[string]interface{}{"append_terms": serverTerms + " " + terms}, "append_terms")
+}
@@ -0,0 +1,24 @@
+package main
+
+import "strings"
+
+// waybackURL prefixes a page URL to link to its latest Wayback Machine snapshot.
+const waybackURL = "https://web.archive.org/web/"
+
+// archiveURL returns the Wayback Machine URL for link, or "" for links that
+// can't be archived. The URL is constructed rather than looked up, so it
+// costs no requests; the Wayback Machine redirects it to the latest snapshot.
+func archiveURL(link string) string {
+	if !strings.HasPrefix(link, "http://") && !strings.HasPrefix(link, "https://") {
+		return ""
+	}
+
+	return waybackURL + link
+}
+
+// addArchiveLinks sets an archive URL on each result.
+func addArchiveLinks(results []GoogleSearchResult) {
+	for i := range results {
+		results[i].Archive = archiveURL(results[i].Link)
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// printBanner writes a report of the effective configuration to w, which
+// must not be stdout: over stdio, stdout carries MCP messages.
+func printBanner(w io.Writer, s *server.MCPServer, config *Config, opts *Options, history bool) {
+	fmt.Fprint(w, formatBanner(config, opts, serverTools(s), history))
+}
+
+// serverTools returns the names of the tools s serves, in the order
+// tools/list reports them.
+func serverTools(s *server.MCPServer) []string {
+	response, ok := s.HandleMessage(context.Background(), json.RawMessage(`{"jsonrpc":"2.0","id":0,"method":"tools/list"}`)).(mcp.JSONRPCResponse)
+	if !ok {
+		return nil
+	}
+
+	result, ok := response.Result.(mcp.ListToolsResult)
+	if !ok {
+		return nil
+	}
+
+	names := make([]string, 0, len(result.Tools))
+	for _, tool := range result.Tools {
+		names = append(names, tool.Name)
+	}
+
+	return names
+}
+
+// formatBanner formats the startup report: what the server serves, over
+// which transport, and the settings that change its behavior. Credentials
+// are never shown.
+func formatBanner(config *Config, opts *Options, tools []string, history bool) string {
+	var sb strings.Builder
+
+	line := func(name, format string, args ...interface{}) {
+		fmt.Fprintf(&sb, "  %-13s %s\n", name+":", fmt.Sprintf(format, args...))
+	}
+
+	fmt.Fprintf(&sb, "Google Search MCP Server %s (%s, %s/%s)\n", version, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+
+	if opts.Transport == "sse" {
+		line("transport", "sse on %s (%d workers, queue %d)", opts.Addr, opts.Workers, opts.QueueDepth)
+	} else {
+		line("transport", "stdio")
+	}
+
+	line("provider", "%s (engine %s)", providerName, config.SearchEngineID)
+	line("tools", "%d: %s", len(tools), strings.Join(tools, ", "))
+
+	var features []string
+
+	for _, name := range knownFeatures() {
+		if config.Features.enabled(name) {
+			features = append(features, name)
+		}
+	}
+
+	line("features", "%s", orNone(strings.Join(features, ", ")))
+	line("fallbacks", "%s", orNone(strings.Join(config.Fallbacks, ", ")))
+	line("safe search", "%s", orDefault(config.SafeSearch, "API default"))
+	line("locale", "%s", config.Locale)
+	line("stale cache", "up to %d searches, served for %s after %d failed requests", maxStaleEntries, maxStaleAge, degradeAfter)
+	line("results", "last %d searches kept for follow-ups", maxStoredSearches)
+
+	if delay := time.Duration(searchHedgeDelay.Load()); delay > 0 {
+		line("hedging", "after %s", delay)
+	} else {
+		line("hedging", "off")
+	}
+
+	quota := "unlimited"
+	if config.Quota.Daily > 0 {
+		quota = fmt.Sprintf("%d API calls a day", config.Quota.Daily)
+	}
+
+	if reserves := config.Quota.Reserves; len(reserves) > 0 {
+		held := make([]string, len(reserves))
+		for i, reserve := range reserves {
+			held[i] = reserve.String()
+		}
+
+		quota += ", reserving " + strings.Join(held, ", ") + " UTC"
+	}
+
+	for _, tool := range quotaTools {
+		if calls, ok := config.Quota.Tools[tool]; ok {
+			quota += fmt.Sprintf("; %s %d", tool, calls)
+		}
+	}
+
+	line("quota", "%s", quota)
+
+	if spend := config.Quota.Spend; spend.Monthly > 0 {
+		line("spend cap", "$%.2f a month ($%.2f per 1000 calls beyond %d a day)", spend.Monthly, spend.PricePer1000, spend.FreePerDay)
+	} else {
+		line("spend cap", "off")
+	}
+
+	if config.KeepWarm > 0 {
+		line("keep-warm", "every %s", config.KeepWarm)
+	} else {
+		line("keep-warm", "off")
+	}
+
+	if dir, err := cacheDir(); err == nil {
+		line("cache dir", "%s", dir)
+	} else {
+		line("cache dir", "unavailable (%v)", err)
+	}
+
+	line("history", "%s", onOff(history))
+
+	switch {
+	case config.TelemetryEndpoint != "":
+		line("telemetry", "on, sending to %s", config.TelemetryEndpoint)
+	case config.Telemetry:
+		line("telemetry", "on, local only")
+	default:
+		line("telemetry", "off")
+	}
+
+	line("reports", "%s", orDefault(config.ReportDir, "not saved"))
+
+	return sb.String()
+}
+
+// orNone returns value, or "none" if it is empty.
+func orNone(value string) string {
+	return orDefault(value, "none")
+}
+
+// orDefault returns value, or fallback if it is empty.
+func orDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+
+	return value
+}
+
+// onOff formats a switch.
+func onOff(on bool) string {
+	if on {
+		return "on"
+	}
+
+	return "off"
+}
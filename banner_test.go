@@ -0,0 +1,40 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestBanner checks that the startup report lists the served tools and the
+// transport, and leaves out the API key.
+func TestBanner(t *testing.T) {
+	s := newConformanceServer(t, newFakeSearchAPI())
+
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig: %v", err)
+	}
+
+	tools := serverTools(s)
+	if len(tools) == 0 || tools[0] != "bookmark_result" {
+		t.Fatalf("serverTools = %v", tools)
+	}
+
+	banner := formatBanner(config, &Options{Transport: "sse", Addr: ":9000", Workers: 4, QueueDepth: 8}, tools, true)
+	for _, want := range []string{
+		"Google Search MCP Server " + version,
+		"transport:    sse on :9000 (4 workers, queue 8)",
+		"engine " + conformanceCX,
+		"google_image_search, google_search,",
+		"history:      on",
+		"telemetry:    off",
+	} {
+		if !strings.Contains(banner, want) {
+			t.Errorf("banner lacks %q:\n%s", want, banner)
+		}
+	}
+
+	if strings.Contains(banner, conformanceAPIKey) {
+		t.Errorf("banner shows the API key:\n%s", banner)
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// batchRecord is one line of batch output.
+type batchRecord struct {
+	Query   string               `json:"query"`
+	Results []GoogleSearchResult `json:"results"`
+}
+
+// runBatchCommand handles `batch`: it runs every query in a file and appends
+// the results to a JSONL file. Queries already present in the output are
+// skipped, so an interrupted run can simply be restarted.
+func runBatchCommand(args []string) error {
+	fs := flag.NewFlagSet("batch", flag.ContinueOnError)
+	out := fs.String("out", "results.jsonl", "JSONL file to append results to")
+	numResults := fs.Int("n", defaultNumResults, fmt.Sprintf("Number of results per query (max %d)", maxNumResults))
+	rate := fs.Float64("rate", 1, "Maximum queries per second")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s batch <queries.txt|-> [--out results.jsonl] [-n N] [--rate QPS]\n", serviceName)
+		fs.PrintDefaults()
+	}
+
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 1 {
+		fs.Usage()
+
+		return fmt.Errorf("expected exactly one queries file")
+	}
+
+	if *numResults < 1 || *numResults > maxNumResults {
+		return fmt.Errorf("-n must be between 1 and %d", maxNumResults)
+	}
+
+	if *rate <= 0 {
+		return fmt.Errorf("--rate must be positive")
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	queries, err := readQueries(positional[0])
+	if err != nil {
+		return err
+	}
+
+	done, torn, err := completedQueries(*out)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(*out, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open output: %v", err)
+	}
+	defer f.Close()
+
+	// Terminate a torn line so the next record starts on its own line
+	if torn {
+		if _, err := f.WriteString("\n"); err != nil {
+			return fmt.Errorf("failed to write output: %v", err)
+		}
+	}
+
+	enc := json.NewEncoder(f)
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / *rate))
+	defer ticker.Stop()
+
+	ran := 0
+	for i, query := range queries {
+		if done[query] {
+			continue
+		}
+
+		if ran > 0 {
+			<-ticker.C
+		}
+		ran++
+
+		results, err := performGoogleSearch(context.Background(), SearchOptions{Query: query, NumResults: *numResults, Safe: config.SafeSearch, AppendTerms: config.AppendTerms}, config.APIKey, config.SearchEngineID)
+		if err != nil {
+			return fmt.Errorf("query %d (%q) failed: %v; rerun the same command to resume", i+1, query, err)
+		}
+
+		if err := enc.Encode(batchRecord{Query: query, Results: results}); err != nil {
+			return fmt.Errorf("failed to write output: %v", err)
+		}
+
+		done[query] = true
+	}
+
+	fmt.Fprintf(os.Stderr, "Ran %d queries (%d already done), results in %s\n", ran, len(queries)-ran, *out)
+
+	return nil
+}
+
+// readQueries reads one query per line from path ("-" for stdin), skipping
+// blanks, # comments and repeats of an earlier query.
+func readQueries(path string) ([]string, error) {
+	var r io.Reader = os.Stdin
+
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open queries: %v", err)
+		}
+		defer f.Close()
+
+		r = f
+	}
+
+	var queries []string
+
+	seen := map[string]bool{}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || seen[line] {
+			continue
+		}
+
+		seen[line] = true
+		queries = append(queries, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read queries: %v", err)
+	}
+
+	return queries, nil
+}
+
+// completedQueries returns the queries already recorded in an existing output
+// file, and whether the file ends in a torn (unterminated) line.
+func completedQueries(path string) (map[string]bool, bool, error) {
+	done := map[string]bool{}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return done, false, nil
+	}
+
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read existing output: %v", err)
+	}
+
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		var record batchRecord
+		// A torn last line from a crash is simply re-run
+		if err := json.Unmarshal(line, &record); err == nil {
+			done[record.Query] = true
+		}
+	}
+
+	torn := len(data) > 0 && data[len(data)-1] != '\n'
+
+	return done, torn, nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestReadQueriesSkipsRepeats reads each query once, so a resumed run's
+// "already done" count matches the queries it skipped.
+func TestReadQueriesSkipsRepeats(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queries.txt")
+	if err := os.WriteFile(path, []byte("golang\n# comment\n\nrust\n golang \nzig\nrust\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	queries, err := readQueries(path)
+	if err != nil {
+		t.Fatalf("readQueries: %v", err)
+	}
+
+	if want := []string{"golang", "rust", "zig"}; !slices.Equal(queries, want) {
+		t.Errorf("queries = %q, want %q", queries, want)
+	}
+}
+
+// TestBatchRespectsSpendCap refuses the batch's API calls once the monthly
+// spend cap is reached, as it does the server's.
+func TestBatchRespectsSpendCap(t *testing.T) {
+	api := newFakeSearchAPI()
+	newConformanceServer(t, api)
+	t.Cleanup(func() { searchQuota.Store(nil) })
+
+	// No free calls, and a paid one costs more than the cap
+	t.Setenv("GOOGLE_SEARCH_MONTHLY_CAP", "1")
+	t.Setenv("GOOGLE_SEARCH_FREE_CALLS_PER_DAY", "0")
+	t.Setenv("GOOGLE_SEARCH_PRICE_PER_1000", "5000")
+
+	dir := t.TempDir()
+	queries := filepath.Join(dir, "queries.txt")
+	if err := os.WriteFile(queries, []byte("golang\nrust\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	err := runBatchCommand([]string{queries, "--out", filepath.Join(dir, "results.jsonl"), "--rate", "100"})
+	if err == nil || !strings.Contains(err.Error(), "monthly spend cap") {
+		t.Errorf("err = %v, want the spend cap to refuse the batch", err)
+	}
+
+	if n := len(api.Requests()); n != 0 {
+		t.Errorf("API requests = %d, want none past the cap", n)
+	}
+}
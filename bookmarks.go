@@ -0,0 +1,323 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	bookmarksFileName = "bookmarks.jsonl"
+	bookmarksURI      = "bookmarks://reading-list"
+	maxNoteLength     = 1000
+	// legacyBookmarkOwner owns the bookmarks saved before they recorded an
+	// owner: the stdio session, whose user also owns the cache directory.
+	// mcp-go gives the stdio session this ID.
+	legacyBookmarkOwner = "stdio"
+)
+
+// markdownLinkText escapes the characters that would end a Markdown link text.
+var markdownLinkText = strings.NewReplacer(`[`, `\[`, `]`, `\]`)
+
+// errBookmarksUnavailable is returned when there is no cache directory to
+// keep bookmarks in.
+var errBookmarksUnavailable = errors.New("bookmarks are unavailable: no cache directory")
+
+// bookmark is one saved result. Bookmarking a link again replaces its note.
+type bookmark struct {
+	Time time.Time `json:"time"`
+	// Owner is the MCP session that saved the bookmark; other sessions
+	// can't see it.
+	Owner string `json:"owner,omitempty"`
+	// ID is the result's stable ID; see resultID.
+	ID string `json:"id"`
+	// SearchID is the search the result was bookmarked from.
+	SearchID    string `json:"search_id,omitempty"`
+	Query       string `json:"query"`
+	Title       string `json:"title"`
+	Link        string `json:"link"`
+	DisplayLink string `json:"displayLink"`
+	Snippet     string `json:"snippet"`
+	Note        string `json:"note,omitempty"`
+}
+
+// bookmarks persists bookmarked results as a reading list.
+type bookmarks struct {
+	jsonlLog
+}
+
+// openBookmarks returns the bookmark list in the cache directory. Without
+// one, the tools report errBookmarksUnavailable.
+func openBookmarks() *bookmarks {
+	list := &bookmarks{}
+
+	if dir, err := cacheDir(); err != nil {
+		log.Printf("Bookmarks disabled: %v", err)
+	} else {
+		list.path = filepath.Join(dir, bookmarksFileName)
+	}
+
+	return list
+}
+
+// add saves a bookmark.
+func (b *bookmarks) add(entry bookmark) error {
+	if b.path == "" {
+		return errBookmarksUnavailable
+	}
+
+	return b.append(entry)
+}
+
+// list returns owner's bookmarks in the order they were first saved, each
+// as last bookmarked.
+func (b *bookmarks) list(owner string) ([]bookmark, error) {
+	if b.path == "" {
+		return nil, errBookmarksUnavailable
+	}
+
+	var (
+		entries []bookmark
+		index   = make(map[string]int)
+	)
+
+	err := b.scan(func(line []byte) {
+		var entry bookmark
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return
+		}
+
+		if entry.Owner == "" {
+			entry.Owner = legacyBookmarkOwner
+		}
+
+		if entry.Owner != owner {
+			return
+		}
+
+		// Bookmarks saved before results had IDs are identified by link
+		if entry.ID == "" {
+			entry.ID = resultID(entry.Link)
+		}
+
+		// A later bookmark of the same page replaces it in place
+		if i, ok := index[entry.ID]; ok {
+			entry.Time = entries[i].Time
+			entries[i] = entry
+
+			return
+		}
+
+		index[entry.ID] = len(entries)
+		entries = append(entries, entry)
+	})
+
+	return entries, err
+}
+
+// find returns owner's bookmark of the result with the stable ID id.
+func (b *bookmarks) find(owner, id string) (bookmark, bool) {
+	entries, err := b.list(owner)
+	if err != nil {
+		return bookmark{}, false
+	}
+
+	for _, entry := range entries {
+		if entry.ID == id {
+			return entry, true
+		}
+	}
+
+	return bookmark{}, false
+}
+
+// storedResult returns the bookmark as a stored result for the follow-up tools.
+func (entry bookmark) storedResult() storedResult {
+	return storedResult{
+		SearchID: entry.SearchID,
+		Query:    entry.Query,
+		Result: GoogleSearchResult{
+			ID:          entry.ID,
+			Title:       entry.Title,
+			Link:        entry.Link,
+			DisplayLink: entry.DisplayLink,
+			Snippet:     entry.Snippet,
+		},
+	}
+}
+
+// registerBookmarkTools creates and registers the bookmark_result and
+// list_bookmarks tools and the reading list resource with the server.
+func registerBookmarkTools(s *server.MCPServer, store *resultStore, list *bookmarks) {
+	s.AddTool(createBookmarkResultTool(), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleBookmarkResultRequest(ctx, request, store, list)
+	})
+	s.AddTool(createListBookmarksTool(), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleListBookmarksRequest(ctx, request, list)
+	})
+
+	s.AddResource(mcp.NewResource(bookmarksURI, "Reading list",
+		mcp.WithResourceDescription("Bookmarked search results with their notes, as Markdown"),
+		mcp.WithMIMEType("text/markdown"),
+	), func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		entries, err := list.list(sessionOwner(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bookmarks: %v", err)
+		}
+
+		return []mcp.ResourceContents{mcp.TextResourceContents{
+			URI:      bookmarksURI,
+			MIMEType: "text/markdown",
+			Text:     formatReadingList(entries),
+		}}, nil
+	})
+}
+
+// createBookmarkResultTool creates and configures the bookmark_result tool.
+func createBookmarkResultTool() mcp.Tool {
+	options := append([]mcp.ToolOption{
+		mcp.WithDescription("Save one result of an earlier search to the persistent reading list, with an optional note"),
+	}, storedResultOptions()...)
+
+	options = append(options,
+		mcp.WithString("note",
+			mcp.Description("Why the source matters, e.g. what it supports; bookmarking a result again replaces its note"),
+		),
+	)
+
+	return mcp.NewTool("bookmark_result", options...)
+}
+
+// handleBookmarkResultRequest processes a bookmark_result tool request.
+func handleBookmarkResultRequest(ctx context.Context,
+	request mcp.CallToolRequest,
+	store *resultStore,
+	list *bookmarks,
+) (*mcp.CallToolResult, error) {
+	stored, err := extractStoredResult(ctx, request.Params.Arguments, store, list)
+	if err != nil {
+		return nil, err
+	}
+
+	// Extract and validate note parameter
+	note, err := extractTerms(request.Params.Arguments, "note")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(note) > maxNoteLength {
+		return nil, fmt.Errorf("note must be at most %d bytes", maxNoteLength)
+	}
+
+	result := stored.Result
+	if err := list.add(bookmark{
+		Time:        time.Now().UTC(),
+		Owner:       sessionOwner(ctx),
+		ID:          resultID(result.Link),
+		SearchID:    stored.SearchID,
+		Query:       stored.Query,
+		Title:       result.Title,
+		Link:        result.Link,
+		DisplayLink: result.DisplayLink,
+		Snippet:     result.Snippet,
+		Note:        note,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to save bookmark: %v", err)
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Bookmarked %s\n%s\n", result.Title, result.Link)), nil
+}
+
+// createListBookmarksTool creates and configures the list_bookmarks tool.
+func createListBookmarksTool() mcp.Tool {
+	return mcp.NewTool("list_bookmarks",
+		mcp.WithDescription("List the results saved with bookmark_result, with their notes, oldest first"),
+		mcp.WithString("output_format",
+			mcp.Description("text (default) or json"),
+			mcp.Enum(outputText, outputJSON),
+		),
+	)
+}
+
+// handleListBookmarksRequest processes a list_bookmarks tool request.
+func handleListBookmarksRequest(ctx context.Context,
+	request mcp.CallToolRequest,
+	list *bookmarks,
+) (*mcp.CallToolResult, error) {
+	outputFormat, err := extractOutputFormat(request.Params.Arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := list.list(sessionOwner(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bookmarks: %v", err)
+	}
+
+	if outputFormat == outputJSON {
+		if entries == nil {
+			entries = []bookmark{}
+		}
+
+		data, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode bookmarks: %v", err)
+		}
+
+		return mcp.NewToolResultText(string(data)), nil
+	}
+
+	return mcp.NewToolResultText(formatBookmarks(entries)), nil
+}
+
+// formatBookmarks formats the bookmarks as a numbered list.
+func formatBookmarks(entries []bookmark) string {
+	if len(entries) == 0 {
+		return "No bookmarks yet.\n"
+	}
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "%d bookmarks:\n\n", len(entries))
+
+	for i, entry := range entries {
+		fmt.Fprintf(&sb, "%d. %s\n", i+1, entry.Title)
+		fmt.Fprintf(&sb, "   URL: %s\n", entry.Link)
+
+		if entry.Note != "" {
+			fmt.Fprintf(&sb, "   Note: %s\n", entry.Note)
+		}
+
+		fmt.Fprintf(&sb, "   Found for: %s (%s)\n\n", entry.Query, entry.Time.Format(time.DateOnly))
+	}
+
+	return sb.String()
+}
+
+// formatReadingList formats the bookmarks as a Markdown list for the
+// reading list resource.
+func formatReadingList(entries []bookmark) string {
+	var sb strings.Builder
+
+	sb.WriteString("# Reading list\n\n")
+
+	for _, entry := range entries {
+		fmt.Fprintf(&sb, "- [%s](%s)", markdownLinkText.Replace(entry.Title), entry.Link)
+
+		if entry.Note != "" {
+			fmt.Fprintf(&sb, ": %s", entry.Note)
+		}
+
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
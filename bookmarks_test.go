@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestBookmarks(t *testing.T) {
+	ts := server.NewTestServer(newConformanceServer(t, newFakeSearchAPI()))
+	t.Cleanup(ts.Close)
+
+	c := connectClient(t, ts.URL)
+
+	result, err := callTool(t, c, "google_search", map[string]interface{}{"query": "golang", "output_format": outputJSON})
+	if err != nil {
+		t.Fatalf("google_search: %v", err)
+	}
+
+	var response searchResponse
+	if err := json.Unmarshal([]byte(resultText(t, result)), &response); err != nil {
+		t.Fatalf("JSON output doesn't decode: %v", err)
+	}
+
+	// Bookmarking a result again replaces its note but keeps its place
+	for _, args := range []map[string]interface{}{
+		{"rank": float64(2), "note": "first note"},
+		{"rank": float64(1)},
+		{"rank": float64(2), "note": "Cites the [spec]"},
+	} {
+		args["search_id"] = response.Metadata.SearchID
+		if _, err := callTool(t, c, "bookmark_result", args); err != nil {
+			t.Fatalf("bookmark_result %v: %v", args, err)
+		}
+	}
+
+	result, err = callTool(t, c, "list_bookmarks", map[string]interface{}{"output_format": outputJSON})
+	if err != nil {
+		t.Fatalf("list_bookmarks: %v", err)
+	}
+
+	var entries []bookmark
+	if err := json.Unmarshal([]byte(resultText(t, result)), &entries); err != nil {
+		t.Fatalf("list_bookmarks output doesn't decode: %v", err)
+	}
+
+	if len(entries) != 2 || entries[0].Link != "https://example.com/2" || entries[0].Note != "Cites the [spec]" ||
+		entries[1].Link != "https://example.com/1" || entries[1].Query != "golang" {
+		t.Errorf("bookmarks = %+v", entries)
+	}
+
+	request := mcp.ReadResourceRequest{}
+	request.Params.URI = bookmarksURI
+
+	read, err := c.ReadResource(context.Background(), request)
+	if err != nil {
+		t.Fatalf("ReadResource: %v", err)
+	}
+
+	text, _ := read.Contents[0].(mcp.TextResourceContents)
+	want := "# Reading list\n\n- [golang result 2](https://example.com/2): Cites the [spec]\n- [golang result 1](https://example.com/1)\n"
+
+	if text.Text != want {
+		t.Errorf("reading list = %q, want %q", text.Text, want)
+	}
+
+	// The bookmarks are this session's; another session sees none of them
+	other := connectClient(t, ts.URL)
+
+	result, err = callTool(t, other, "list_bookmarks", map[string]interface{}{"output_format": outputJSON})
+	if err != nil {
+		t.Fatalf("list_bookmarks in another session: %v", err)
+	}
+
+	if text := resultText(t, result); text != "[]" {
+		t.Errorf("another session's bookmarks = %s, want none", text)
+	}
+
+	read, err = other.ReadResource(context.Background(), request)
+	if err != nil {
+		t.Fatalf("ReadResource in another session: %v", err)
+	}
+
+	if text, _ := read.Contents[0].(mcp.TextResourceContents); text.Text != "# Reading list\n\n" {
+		t.Errorf("another session's reading list = %q, want it empty", text.Text)
+	}
+
+	if _, err := callTool(t, other, "get_result", map[string]interface{}{"result_id": resultID("https://example.com/2")}); err == nil {
+		t.Error("another session looked up a bookmarked result by its ID")
+	}
+
+	if _, err := callTool(t, c, "bookmark_result", map[string]interface{}{
+		"search_id": response.Metadata.SearchID,
+		"rank":      float64(1),
+		"note":      strings.Repeat("x", maxNoteLength+1),
+	}); err == nil || !strings.Contains(err.Error(), "note must be at most") {
+		t.Errorf("overlong note: err = %v", err)
+	}
+}
+
+// TestBookmarksByOwner lists each session's bookmarks only, giving those
+// saved before bookmarks had owners to the stdio session.
+func TestBookmarksByOwner(t *testing.T) {
+	list := &bookmarks{jsonlLog{path: filepath.Join(t.TempDir(), bookmarksFileName)}}
+
+	for _, entry := range []bookmark{
+		{Link: "https://example.com/old"},
+		{Owner: "session-a", Link: "https://example.com/a"},
+		{Owner: legacyBookmarkOwner, Link: "https://example.com/local"},
+	} {
+		if err := list.add(entry); err != nil {
+			t.Fatalf("add: %v", err)
+		}
+	}
+
+	for owner, want := range map[string][]string{
+		"session-a":         {"https://example.com/a"},
+		legacyBookmarkOwner: {"https://example.com/old", "https://example.com/local"},
+		"session-b":         nil,
+	} {
+		entries, err := list.list(owner)
+		if err != nil {
+			t.Fatalf("list(%q): %v", owner, err)
+		}
+
+		var links []string
+		for _, entry := range entries {
+			links = append(links, entry.Link)
+		}
+
+		if !slices.Equal(links, want) {
+			t.Errorf("list(%q) = %q, want %q", owner, links, want)
+		}
+	}
+
+	if _, ok := list.find("session-b", resultID("https://example.com/a")); ok {
+		t.Error("find returned another session's bookmark")
+	}
+}
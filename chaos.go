@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Fault kinds for GOOGLE_SEARCH_CHAOS.
+const (
+	faultLatency  = "latency"
+	faultStatus   = "status"
+	faultTruncate = "truncate"
+)
+
+// chaosSettings are the faults injected into API requests. Probabilities are
+// per request and independent.
+type chaosSettings struct {
+	LatencyRate  float64
+	Latency      time.Duration
+	StatusRate   float64
+	Status       int
+	TruncateRate float64
+}
+
+// searchChaos holds the active fault injection settings; nil disables it.
+// It is set by loadConfig.
+var searchChaos atomic.Pointer[chaosSettings]
+
+// loadChaos parses GOOGLE_SEARCH_CHAOS, a comma-separated list of faults:
+// latency=P:DURATION, status=P[:CODE] (default 429) and truncate=P, where P
+// is the probability between 0 and 1. It returns nil when unset.
+func loadChaos() (*chaosSettings, error) {
+	value := os.Getenv("GOOGLE_SEARCH_CHAOS")
+	if value == "" {
+		return nil, nil
+	}
+
+	settings := &chaosSettings{Status: http.StatusTooManyRequests}
+
+	for _, fault := range strings.Split(value, ",") {
+		name, spec, _ := strings.Cut(strings.TrimSpace(fault), "=")
+		rateText, param, hasParam := strings.Cut(spec, ":")
+
+		rate, err := strconv.ParseFloat(rateText, 64)
+		if err != nil || rate < 0 || rate > 1 {
+			return nil, fmt.Errorf("GOOGLE_SEARCH_CHAOS: %s needs a probability between 0 and 1, got %q", name, rateText)
+		}
+
+		switch name {
+		case faultLatency:
+			settings.LatencyRate = rate
+
+			settings.Latency, err = time.ParseDuration(param)
+			if err != nil || settings.Latency <= 0 {
+				return nil, fmt.Errorf("GOOGLE_SEARCH_CHAOS: latency needs a delay, e.g. latency=0.2:2s")
+			}
+		case faultStatus:
+			settings.StatusRate = rate
+
+			if hasParam {
+				settings.Status, err = strconv.Atoi(param)
+				if err != nil || settings.Status < 400 || settings.Status > 599 {
+					return nil, fmt.Errorf("GOOGLE_SEARCH_CHAOS: status needs an error status code, e.g. status=0.1:503")
+				}
+			}
+		case faultTruncate:
+			settings.TruncateRate = rate
+		default:
+			return nil, fmt.Errorf("GOOGLE_SEARCH_CHAOS: unknown fault %q (want %s, %s or %s)", name, faultLatency, faultStatus, faultTruncate)
+		}
+	}
+
+	return settings, nil
+}
+
+// chaosTransport injects the configured faults into requests to the API.
+type chaosTransport struct {
+	next http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *chaosTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	settings := searchChaos.Load()
+	if settings == nil {
+		return t.next.RoundTrip(req)
+	}
+
+	if rand.Float64() < settings.LatencyRate {
+		select {
+		case <-time.After(settings.Latency):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+
+	if rand.Float64() < settings.StatusRate {
+		return chaosErrorResponse(req, settings.Status), nil
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || rand.Float64() >= settings.TruncateRate {
+		return resp, err
+	}
+
+	// Cut the body in half, as if the connection dropped mid-transfer
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(body[:len(body)/2]))
+	resp.ContentLength = -1
+	resp.Header.Del("Content-Length")
+
+	return resp, nil
+}
+
+// chaosErrorResponse fabricates an API error response with the given status.
+func chaosErrorResponse(req *http.Request, status int) *http.Response {
+	body := fmt.Sprintf(`{"error": {"code": %d, "message": "Injected fault (GOOGLE_SEARCH_CHAOS)", "status": %q}}`,
+		status, strings.ToUpper(strings.ReplaceAll(http.StatusText(status), " ", "_")))
+
+	return &http.Response{
+		StatusCode:    status,
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"application/json"}},
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
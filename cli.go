@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// runQueryCommand handles `query`: it runs a single search and prints the results
+// without starting the MCP server.
+func runQueryCommand(args []string) error {
+	fs := flag.NewFlagSet("query", flag.ContinueOnError)
+	numResults := fs.Int("n", defaultNumResults, fmt.Sprintf("Number of results to return (max %d)", maxNumResults))
+	asJSON := fs.Bool("json", false, "Print results as JSON")
+	images := fs.Bool("images", false, "Search for images instead of web pages")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s query \"search terms\" [-n N] [--images] [--json]\n", serviceName)
+		fs.PrintDefaults()
+	}
+
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+
+	query := strings.TrimSpace(strings.Join(positional, " "))
+	if query == "" {
+		fs.Usage()
+
+		return fmt.Errorf("query must be a non-empty string")
+	}
+
+	if *numResults < 1 || *numResults > maxNumResults {
+		return fmt.Errorf("-n must be between 1 and %d", maxNumResults)
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	opts := SearchOptions{Query: query, NumResults: *numResults, Safe: config.SafeSearch, AppendTerms: config.AppendTerms}
+	if *images {
+		opts.SearchType = searchTypeImage
+	}
+
+	results, err := performGoogleSearch(context.Background(), opts, config.APIKey, config.SearchEngineID)
+	if err != nil {
+		return fmt.Errorf("search failed: %v", err)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(results)
+	}
+
+	fmt.Print(formatSearchResults(results, config.messages("")))
+
+	return nil
+}
+
+// parseInterspersed parses flags that may appear before or after positional
+// arguments and returns the positional arguments.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
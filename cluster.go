@@ -0,0 +1,280 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	clusterIterations = 20
+	clusterLabelTerms = 3
+)
+
+// clusterStopWords are frequent English words that carry no topical signal.
+var clusterStopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "that": true, "this": true,
+	"from": true, "are": true, "was": true, "you": true, "your": true, "how": true,
+	"what": true, "can": true, "not": true, "but": true, "all": true, "has": true,
+	"have": true, "its": true, "our": true, "more": true, "about": true, "into": true,
+	"will": true, "one": true, "use": true, "using": true, "also": true, "new": true,
+}
+
+// resultCluster is a group of results sharing a topic.
+type resultCluster struct {
+	Label string `json:"label"`
+	// Results holds the 1-based positions of the member results.
+	Results []int `json:"results"`
+}
+
+// termVector is a sparse, L2-normalized TF-IDF vector.
+type termVector map[string]float64
+
+// clusterResults groups results by topic using TF-IDF vectors over titles and
+// snippets and k-means with cosine similarity. It is deterministic for a given input.
+func clusterResults(results []GoogleSearchResult) []resultCluster {
+	if len(results) == 0 {
+		return nil
+	}
+
+	vectors := tfidfVectors(results)
+	k := int(math.Ceil(math.Sqrt(float64(len(results)) / 2)))
+	centroids := seedCentroids(vectors, k)
+	assignment := make([]int, len(vectors))
+
+	for iter := 0; iter < clusterIterations; iter++ {
+		changed := false
+
+		for i, v := range vectors {
+			if best := nearestCentroid(v, centroids); best != assignment[i] || iter == 0 {
+				assignment[i] = best
+				changed = true
+			}
+		}
+
+		if !changed {
+			break
+		}
+
+		centroids = recomputeCentroids(vectors, assignment, len(centroids))
+	}
+
+	var clusters []resultCluster
+
+	for c, centroid := range centroids {
+		var members []int
+
+		for i, a := range assignment {
+			if a == c {
+				members = append(members, i+1)
+			}
+		}
+
+		if len(members) == 0 {
+			continue
+		}
+
+		clusters = append(clusters, resultCluster{
+			Label:   strings.Join(topTerms(centroid, clusterLabelTerms), ", "),
+			Results: members,
+		})
+	}
+
+	// Largest clusters first; ties keep rank order
+	sort.SliceStable(clusters, func(i, j int) bool {
+		return len(clusters[i].Results) > len(clusters[j].Results)
+	})
+
+	return clusters
+}
+
+// tfidfVectors builds a normalized TF-IDF vector per result.
+func tfidfVectors(results []GoogleSearchResult) []termVector {
+	counts := make([]map[string]int, len(results))
+	docFreq := make(map[string]int)
+
+	for i, result := range results {
+		counts[i] = termCounts(result.Title + " " + result.Snippet)
+		for term := range counts[i] {
+			docFreq[term]++
+		}
+	}
+
+	vectors := make([]termVector, len(results))
+	n := float64(len(results))
+
+	for i, tf := range counts {
+		v := make(termVector, len(tf))
+		for term, count := range tf {
+			v[term] = float64(count) * (math.Log((1+n)/(1+float64(docFreq[term]))) + 1)
+		}
+
+		vectors[i] = normalize(v)
+	}
+
+	return vectors
+}
+
+// termCounts counts topical words in text.
+func termCounts(text string) map[string]int {
+	counts := make(map[string]int)
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	for _, word := range words {
+		if len([]rune(word)) > 2 && !clusterStopWords[word] {
+			counts[word]++
+		}
+	}
+
+	return counts
+}
+
+// seedCentroids picks k well-separated starting centroids: the first vector,
+// then repeatedly the vector least similar to any centroid chosen so far.
+func seedCentroids(vectors []termVector, k int) []termVector {
+	centroids := []termVector{vectors[0]}
+
+	for len(centroids) < k {
+		best, bestSim := -1, math.Inf(1)
+
+		for i, v := range vectors {
+			sim := cosine(v, centroids[nearestCentroid(v, centroids)])
+			if sim < bestSim {
+				best, bestSim = i, sim
+			}
+		}
+
+		if best < 0 || bestSim >= 1 {
+			break
+		}
+
+		centroids = append(centroids, vectors[best])
+	}
+
+	return centroids
+}
+
+// nearestCentroid returns the index of the centroid most similar to v.
+func nearestCentroid(v termVector, centroids []termVector) int {
+	best, bestSim := 0, math.Inf(-1)
+
+	for c, centroid := range centroids {
+		if sim := cosine(v, centroid); sim > bestSim {
+			best, bestSim = c, sim
+		}
+	}
+
+	return best
+}
+
+// recomputeCentroids averages the vectors assigned to each cluster.
+func recomputeCentroids(vectors []termVector, assignment []int, k int) []termVector {
+	centroids := make([]termVector, k)
+	for c := range centroids {
+		centroids[c] = termVector{}
+	}
+
+	for i, v := range vectors {
+		for term, weight := range v {
+			centroids[assignment[i]][term] += weight
+		}
+	}
+
+	for c := range centroids {
+		centroids[c] = normalize(centroids[c])
+	}
+
+	return centroids
+}
+
+// sortedTerms returns the terms of v in lexical order. Floating-point sums are
+// accumulated in this order so results don't depend on map iteration order.
+func (v termVector) sortedTerms() []string {
+	terms := make([]string, 0, len(v))
+	for term := range v {
+		terms = append(terms, term)
+	}
+
+	sort.Strings(terms)
+
+	return terms
+}
+
+// cosine returns the cosine similarity of two normalized vectors.
+func cosine(a, b termVector) float64 {
+	if len(b) < len(a) {
+		a, b = b, a
+	}
+
+	sum := 0.0
+	for _, term := range a.sortedTerms() {
+		sum += a[term] * b[term]
+	}
+
+	return sum
+}
+
+// normalize scales v to unit length in place and returns it.
+func normalize(v termVector) termVector {
+	norm := 0.0
+	for _, term := range v.sortedTerms() {
+		norm += v[term] * v[term]
+	}
+
+	if norm == 0 {
+		return v
+	}
+
+	norm = math.Sqrt(norm)
+	for term := range v {
+		v[term] /= norm
+	}
+
+	return v
+}
+
+// topTerms returns the n highest-weighted terms of v, breaking ties alphabetically.
+func topTerms(v termVector, n int) []string {
+	terms := v.sortedTerms()
+
+	sort.Slice(terms, func(i, j int) bool {
+		if v[terms[i]] != v[terms[j]] {
+			return v[terms[i]] > v[terms[j]]
+		}
+
+		return terms[i] < terms[j]
+	})
+
+	if len(terms) > n {
+		terms = terms[:n]
+	}
+
+	return terms
+}
+
+// formatClusters formats clusters as a topic overview appended to text results.
+func formatClusters(clusters []resultCluster, msgs messageCatalog) string {
+	if len(clusters) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString(msgs.text(msgTopics) + "\n")
+
+	for i, cluster := range clusters {
+		label := cluster.Label
+		if label == "" {
+			label = msgs.text(msgUntitled)
+		}
+
+		fmt.Fprintf(&sb, "%d. %s: %s\n", i+1, label, msgs.text(msgClusterResults, formatPositions(cluster.Results)))
+	}
+
+	return sb.String()
+}
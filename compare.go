@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// maxCompareDomains bounds the number of domains in one comparison.
+const maxCompareDomains = 20
+
+// registerCompareDomainsTool creates and registers the compare_domains tool with the server.
+func registerCompareDomainsTool(s *server.MCPServer, config *Config) {
+	s.AddTool(createCompareDomainsTool(), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleCompareDomainsRequest(ctx, request, config)
+	})
+}
+
+// createCompareDomainsTool creates and configures the compare_domains tool.
+func createCompareDomainsTool() mcp.Tool {
+	return mcp.NewTool("compare_domains",
+		mcp.WithDescription("Run a query and summarize how many and which of the top results belong to each of several domains"),
+		mcp.WithString("query",
+			mcp.Required(),
+			mcp.Description("The search query"),
+		),
+		mcp.WithArray("domains",
+			mcp.Required(),
+			mcp.Description(fmt.Sprintf("Domains to compare, e.g. [\"example.com\", \"competitor.com\"] (max %d; subdomains match)", maxCompareDomains)),
+			mcp.Items(map[string]interface{}{"type": "string"}),
+		),
+		mcp.WithNumber("depth",
+			mcp.Description(fmt.Sprintf("How many top results to inspect (max %d, default %d); each 10 results cost one API call", maxSearchDepth, defaultRankDepth)),
+		),
+	)
+}
+
+// handleCompareDomainsRequest processes a compare_domains tool request.
+func handleCompareDomainsRequest(ctx context.Context,
+	request mcp.CallToolRequest,
+	config *Config,
+) (*mcp.CallToolResult, error) {
+	query, ok := request.Params.Arguments["query"].(string)
+	if !ok || query == "" {
+		return nil, fmt.Errorf("query must be a non-empty string")
+	}
+
+	domains, err := extractDomains(request.Params.Arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	depth, err := extractDepth(request.Params.Arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	progress := newProgressReporter(ctx, request, searchPages(depth))
+
+	results, apiCalls, err := searchDepth(ctx, "compare_domains", query, depth, config, progress)
+	if err != nil {
+		return nil, fmt.Errorf("search failed: %v", err)
+	}
+
+	return mcp.NewToolResultText(formatDomainComparison(query, depth, domains, results, apiCalls)), nil
+}
+
+// extractDomains extracts, normalizes and de-duplicates the domains parameter.
+func extractDomains(arguments map[string]interface{}) ([]string, error) {
+	raw, _ := arguments["domains"].([]interface{})
+
+	var domains []string
+
+	seen := map[string]bool{}
+
+	for _, item := range raw {
+		domain, _ := item.(string)
+		domain = normalizeDomain(domain)
+
+		if domain != "" && !seen[domain] {
+			seen[domain] = true
+			domains = append(domains, domain)
+		}
+	}
+
+	if len(domains) == 0 {
+		return nil, fmt.Errorf("domains must be a non-empty array of domain names")
+	}
+
+	if len(domains) > maxCompareDomains {
+		return nil, fmt.Errorf("at most %d domains can be compared at once", maxCompareDomains)
+	}
+
+	return domains, nil
+}
+
+// formatDomainComparison summarizes each domain's share of the results.
+func formatDomainComparison(query string, depth int, domains []string, results []GoogleSearchResult, apiCalls int) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "Domain comparison for %q (top %d, %d results inspected):\n\n", query, depth, len(results))
+
+	// Nested domains (example.com, blog.example.com) may claim the same result
+	matched := map[int]bool{}
+
+	for _, domain := range domains {
+		positions := domainPositions(results, domain)
+		for _, pos := range positions {
+			matched[pos] = true
+		}
+
+		fmt.Fprintf(&sb, "%s: %d results, positions %s\n", domain, len(positions), formatPositions(positions))
+
+		for _, pos := range positions {
+			fmt.Fprintf(&sb, "   %d. %s (%s)\n", pos, results[pos-1].Title, results[pos-1].Link)
+		}
+	}
+
+	fmt.Fprintf(&sb, "\nOther domains: %d results\n", len(results)-len(matched))
+	fmt.Fprintf(&sb, "\n---\nprovider: %s | api_calls: %d\n", providerName, apiCalls)
+
+	return sb.String()
+}
@@ -0,0 +1,419 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/habuvo/mcp-internet-search/searchtest"
+)
+
+// The conformance tests drive the server through a real mcp-go client over
+// an in-process SSE connection, so upgrading the MCP library can't silently
+// change what clients see. The Custom Search API is replaced by the
+// searchtest fake.
+
+const (
+	conformanceAPIKey = "test-key"
+	conformanceCX     = "test-cx"
+)
+
+// roundTripFunc adapts a function to http.RoundTripper.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip implements http.RoundTripper.
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// newFakeSearchAPI returns a fake API accepting the conformance credentials.
+func newFakeSearchAPI() *searchtest.API {
+	api := searchtest.NewAPI()
+	api.APIKey, api.EngineID = conformanceAPIKey, conformanceCX
+
+	return api
+}
+
+// newConformanceClient starts the server as run() builds it, with API calls
+// served by api, and returns an initialized client connected to it.
+func newConformanceClient(t *testing.T, api http.Handler) *client.SSEMCPClient {
+	t.Helper()
+
+	ts := server.NewTestServer(newConformanceServer(t, api))
+	t.Cleanup(ts.Close)
+
+	return connectClient(t, ts.URL)
+}
+
+// newConformanceServer builds the server as run() does, with API calls
+// served by api.
+func newConformanceServer(t *testing.T, api http.Handler) *server.MCPServer {
+	t.Helper()
+
+	// Isolate the configuration from the environment running the tests
+	t.Setenv("GOOGLE_API_KEY", conformanceAPIKey)
+	t.Setenv("GOOGLE_SEARCH_ENGINE_ID", conformanceCX)
+	t.Setenv(configFileEnv, filepath.Join(t.TempDir(), "config.env"))
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("GOOGLE_SEARCH_HISTORY", "true")
+	t.Setenv("GOOGLE_SEARCH_FEATURES", "")
+	t.Setenv("GOOGLE_SEARCH_CHAOS", "")
+	t.Setenv("GOOGLE_SEARCH_HEDGE_DELAY", "")
+	t.Setenv("GOOGLE_SEARCH_REPORT_DIR", "")
+	t.Setenv("GOOGLE_SEARCH_TELEMETRY", "")
+	t.Setenv("GOOGLE_SEARCH_TELEMETRY_ENDPOINT", "")
+	t.Setenv("GOOGLE_SEARCH_DAILY_QUOTA", "")
+	t.Setenv("GOOGLE_SEARCH_QUOTA_RESERVE", "")
+	t.Setenv("GOOGLE_SEARCH_TOOL_QUOTAS", "")
+	t.Setenv("GOOGLE_SEARCH_MONTHLY_CAP", "")
+	t.Setenv("GOOGLE_SEARCH_FREE_CALLS_PER_DAY", "")
+	t.Setenv("GOOGLE_SEARCH_PRICE_PER_1000", "")
+
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig: %v", err)
+	}
+
+	// Serve API requests in-process
+	previous := searchClient
+	searchClient = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		rec := httptest.NewRecorder()
+		api.ServeHTTP(rec, req)
+
+		return rec.Result(), nil
+	})}
+	t.Cleanup(func() { searchClient = previous })
+
+	return buildServer(config, openQueryHistory(), nil)
+}
+
+// connectClient returns an initialized client connected to the SSE server
+// at baseURL.
+func connectClient(t *testing.T, baseURL string) *client.SSEMCPClient {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	c, err := client.NewSSEMCPClient(baseURL + "/sse")
+	if err != nil {
+		t.Fatalf("NewSSEMCPClient: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+
+	if err := c.Start(ctx); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	init := mcp.InitializeRequest{}
+	init.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	init.Params.ClientInfo = mcp.Implementation{Name: "conformance-test", Version: "1.0.0"}
+
+	result, err := c.Initialize(ctx, init)
+	if err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+
+	if result.ServerInfo.Name != "Google Search MCP Server" || result.ServerInfo.Version != version {
+		t.Errorf("server info = %+v", result.ServerInfo)
+	}
+
+	if result.Capabilities.Tools == nil {
+		t.Error("server doesn't advertise the tools capability")
+	}
+
+	return c
+}
+
+// callTool calls a tool with a short timeout.
+func callTool(t *testing.T, c *client.SSEMCPClient, name string, args map[string]interface{}) (*mcp.CallToolResult, error) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = name
+	request.Params.Arguments = args
+
+	return c.CallTool(ctx, request)
+}
+
+// resultText returns the only content block of result, which must be text.
+func resultText(t *testing.T, result *mcp.CallToolResult) string {
+	t.Helper()
+
+	if result.IsError || len(result.Content) != 1 {
+		t.Fatalf("want one content block and no error, got %+v", result)
+	}
+
+	text, ok := result.Content[0].(mcp.TextContent)
+	if !ok {
+		t.Fatalf("content is %T, want mcp.TextContent", result.Content[0])
+	}
+
+	return text.Text
+}
+
+func TestConformanceListTools(t *testing.T) {
+	c := newConformanceClient(t, newFakeSearchAPI())
+
+	listed, err := c.ListTools(context.Background(), mcp.ListToolsRequest{})
+	if err != nil {
+		t.Fatalf("ListTools: %v", err)
+	}
+
+	var names []string
+	for _, tool := range listed.Tools {
+		names = append(names, tool.Name)
+
+		if tool.Description == "" {
+			t.Errorf("%s: empty description", tool.Name)
+		}
+
+		// Every argument must be typed and documented for the model
+		schema := tool.InputSchema
+		if schema.Type != "object" {
+			t.Errorf("%s: schema type %q, want object", tool.Name, schema.Type)
+		}
+
+		for _, required := range schema.Required {
+			if _, ok := schema.Properties[required]; !ok {
+				t.Errorf("%s: required argument %q has no schema", tool.Name, required)
+			}
+		}
+
+		for arg, raw := range schema.Properties {
+			property, _ := raw.(map[string]interface{})
+			if property["type"] == nil || property["description"] == "" {
+				t.Errorf("%s: argument %q lacks a type or description: %v", tool.Name, arg, raw)
+			}
+		}
+	}
+
+	// Tool annotations are not part of the protocol revision mcp-go v0.17.0
+	// implements; check them here once the library supports them.
+	slices.Sort(names)
+
+	want := []string{"bookmark_result", "compare_domains", "export_report", "get_result", "google_image_search", "google_search", "keyword_coverage", "list_bookmarks", "open_result", "query_analytics", "rank_check"}
+	if !slices.Equal(names, want) {
+		t.Fatalf("tools = %v, want %v", names, want)
+	}
+
+	search := listed.Tools[slices.IndexFunc(listed.Tools, func(tool mcp.Tool) bool { return tool.Name == "google_search" })]
+	if !slices.Equal(search.InputSchema.Required, []string{"query"}) {
+		t.Errorf("google_search required = %v, want [query]", search.InputSchema.Required)
+	}
+
+	outputFormat, _ := search.InputSchema.Properties["output_format"].(map[string]interface{})
+	if got := fmt.Sprint(outputFormat["enum"]); got != "[text plain json]" {
+		t.Errorf("output_format enum = %s", got)
+	}
+}
+
+func TestConformanceCallTool(t *testing.T) {
+	c := newConformanceClient(t, newFakeSearchAPI())
+
+	result, err := callTool(t, c, "google_search", map[string]interface{}{"query": "golang"})
+	if err != nil {
+		t.Fatalf("google_search: %v", err)
+	}
+
+	text := resultText(t, result)
+	for _, want := range []string{"golang result 1", "https://example.com/3", fmt.Sprintf("format: v%d", outputFormatVersion)} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text output lacks %q:\n%s", want, text)
+		}
+	}
+
+	result, err = callTool(t, c, "google_search", map[string]interface{}{
+		"query":         "golang",
+		"num_results":   float64(3),
+		"output_format": outputJSON,
+	})
+	if err != nil {
+		t.Fatalf("google_search (json): %v", err)
+	}
+
+	var response searchResponse
+	if err := json.Unmarshal([]byte(resultText(t, result)), &response); err != nil {
+		t.Fatalf("JSON output doesn't decode: %v", err)
+	}
+
+	if len(response.Results) != 3 || response.Metadata.FormatVersion != outputFormatVersion {
+		t.Errorf("JSON output = %d results, format v%d", len(response.Results), response.Metadata.FormatVersion)
+	}
+}
+
+func TestConformanceErrors(t *testing.T) {
+	c := newConformanceClient(t, newFakeSearchAPI())
+
+	// mcp-go reports handler errors as JSON-RPC errors, whose message is
+	// all a client sees
+	tests := []struct {
+		name string
+		tool string
+		args map[string]interface{}
+		want string
+	}{
+		{"missing query", "google_search", map[string]interface{}{}, "query must be a non-empty string"},
+		{"bad output format", "google_search", map[string]interface{}{"query": "go", "output_format": "xml"}, "output_format"},
+		{"bad rights", "google_search", map[string]interface{}{"query": "go", "rights": []interface{}{"cc_everything"}}, "unknown rights value"},
+		{"unknown tool", "bing_search", map[string]interface{}{"query": "go"}, "not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := callTool(t, c, tt.tool, tt.args)
+			if err == nil {
+				t.Fatalf("want an error, got %+v", result)
+			}
+
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error %q doesn't mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestConformanceCancellation(t *testing.T) {
+	release := make(chan struct{})
+	unblock := sync.OnceFunc(func() { close(release) })
+
+	// The first page of "slow" is served at once; later pages hang until
+	// released or abandoned
+	var slowRequests atomic.Int32
+
+	abandoned := make(chan struct{}, 1)
+
+	api := newFakeSearchAPI()
+	c := newConformanceClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("q") == "slow" && slowRequests.Add(1) > 1 {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+				abandoned <- struct{}{}
+
+				return
+			}
+		}
+
+		api.ServeHTTP(w, r)
+	}))
+	t.Cleanup(unblock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "google_search"
+	request.Params.Arguments = map[string]interface{}{"query": "slow", "num_results": 30}
+
+	start := time.Now()
+
+	_, err := c.CallTool(ctx, request)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("CallTool error = %v, want context.DeadlineExceeded", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("cancelled call returned after %v", elapsed)
+	}
+
+	// The server must drop the pending API request, and not ask for the
+	// pages after it, once the call is cancelled
+	select {
+	case <-abandoned:
+	case <-time.After(2 * time.Second):
+		t.Fatal("the server kept waiting on the API after the call was cancelled")
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	if n := slowRequests.Load(); n != 2 {
+		t.Errorf("API requests = %d, want 2: none after the cancellation", n)
+	}
+
+	// The server must keep serving the session after a cancelled call
+	unblock()
+
+	result, err := callTool(t, c, "google_search", map[string]interface{}{"query": "fast"})
+	if err != nil {
+		t.Fatalf("call after cancellation: %v", err)
+	}
+
+	if text := resultText(t, result); !strings.Contains(text, "fast result 1") {
+		t.Errorf("unexpected output after cancellation:\n%s", text)
+	}
+}
+
+func TestConformanceProgress(t *testing.T) {
+	c := newConformanceClient(t, newFakeSearchAPI())
+
+	notifications := make(chan mcp.JSONRPCNotification, 16)
+	c.OnNotification(func(notification mcp.JSONRPCNotification) {
+		if notification.Method == "notifications/progress" {
+			notifications <- notification
+		}
+	})
+
+	keywords := []interface{}{"alpha", "beta", "gamma"}
+	args := map[string]interface{}{"keywords": keywords, "domain": "example.com", "depth": float64(10)}
+
+	// Without a progress token the server must stay silent
+	if _, err := callTool(t, c, "keyword_coverage", args); err != nil {
+		t.Fatalf("keyword_coverage: %v", err)
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "keyword_coverage"
+	request.Params.Arguments = args
+	request.Params.Meta = &struct {
+		ProgressToken mcp.ProgressToken `json:"progressToken,omitempty"`
+	}{ProgressToken: "coverage-1"}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := c.CallTool(ctx, request); err != nil {
+		t.Fatalf("keyword_coverage with progress: %v", err)
+	}
+
+	// Notifications travel over the SSE stream and may trail the response
+	for i := 1; i <= len(keywords); i++ {
+		select {
+		case notification := <-notifications:
+			fields := notification.Params.AdditionalFields
+			if fields["progressToken"] != "coverage-1" || fields["progress"] != float64(i) || fields["total"] != float64(len(keywords)) {
+				t.Errorf("notification %d = %v", i, fields)
+			}
+
+			var row keywordCoverage
+			if err := json.Unmarshal([]byte(fmt.Sprint(fields["message"])), &row); err != nil || row.Keyword != keywords[i-1] {
+				t.Errorf("notification %d message = %v (%v)", i, fields["message"], err)
+			}
+		case <-ctx.Done():
+			t.Fatalf("got %d of %d progress notifications", i-1, len(keywords))
+		}
+	}
+
+	select {
+	case notification := <-notifications:
+		t.Errorf("unexpected notification %v", notification.Params.AdditionalFields)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// containerDrainDelay is how long to keep serving after SIGTERM while the
+// pod is removed from Service endpoints.
+const containerDrainDelay = 5 * time.Second
+
+// applyContainerDefaults configures options for running inside a container.
+// All settings come from the environment; only $PORT is consulted for the listener.
+func applyContainerDefaults(opts *Options) {
+	opts.Transport = "sse"
+
+	if port := os.Getenv("PORT"); port != "" {
+		opts.Addr = ":" + port
+	}
+}
+
+// setupContainerLogging switches all log output to structured JSON on stdout.
+func setupContainerLogging() {
+	slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stdout, nil)))
+}
+
+// handleHealthz reports liveness: the process is up and serving HTTP.
+func handleHealthz(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintln(w, "ok")
+}
+
+// readyzHandler reports readiness: the server accepts MCP traffic and is not shutting down.
+func readyzHandler(ready *atomic.Bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		if !ready.Load() {
+			http.Error(w, "not ready", http.StatusServiceUnavailable)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintln(w, "ok")
+	}
+}
+
+// k8sManifest renders an example Deployment and Service for container mode.
+func k8sManifest() string {
+	return fmt.Sprintf(`apiVersion: v1
+kind: Secret
+metadata:
+  name: %[1]s
+type: Opaque
+stringData:
+  GOOGLE_API_KEY: your_api_key
+  GOOGLE_SEARCH_ENGINE_ID: your_search_engine_id
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: %[1]s
+  labels:
+    app: %[1]s
+spec:
+  replicas: 1
+  selector:
+    matchLabels:
+      app: %[1]s
+  template:
+    metadata:
+      labels:
+        app: %[1]s
+    spec:
+      terminationGracePeriodSeconds: %[2]d
+      containers:
+        - name: %[1]s
+          image: %[1]s:latest
+          args: ["--container"]
+          env:
+            - name: PORT
+              value: "8080"
+          envFrom:
+            - secretRef:
+                name: %[1]s
+          ports:
+            - name: http
+              containerPort: 8080
+          livenessProbe:
+            httpGet:
+              path: /healthz
+              port: http
+          readinessProbe:
+            httpGet:
+              path: /readyz
+              port: http
+            periodSeconds: 2
+          resources:
+            requests:
+              cpu: 50m
+              memory: 32Mi
+            limits:
+              memory: 128Mi
+          securityContext:
+            runAsNonRoot: true
+            readOnlyRootFilesystem: true
+            allowPrivilegeEscalation: false
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: %[1]s
+spec:
+  selector:
+    app: %[1]s
+  ports:
+    - name: http
+      port: 80
+      targetPort: http
+`, serviceName, int((containerDrainDelay+shutdownTimeout)/time.Second)+5)
+}
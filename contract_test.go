@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+// The contract tests run the parser and formatter against sanitized
+// recordings of real Custom Search API responses in testdata/contract, so a
+// field Google adds or changes breaks a test instead of production. To add a
+// recording, save the raw response body (curl, not a browser, to keep the
+// bytes), replace the cx and any project numbers with placeholders, add it to
+// the table below and run with -update to create its golden output.
+
+func TestContractFixtures(t *testing.T) {
+	tests := []struct {
+		fixture string
+		status  int
+		// results is the number of results the response carries.
+		results int
+		// sentinel is the sentinel error an error response must match.
+		sentinel error
+	}{
+		{"normal.json", http.StatusOK, 3, nil},
+		{"empty.json", http.StatusOK, 0, nil},
+		{"pagemap.json", http.StatusOK, 2, nil},
+		{"non_utf8.json", http.StatusOK, 2, nil},
+		{"error_400.json", http.StatusBadRequest, 0, errInvalidAPIKey},
+		{"error_429.json", http.StatusTooManyRequests, 0, errQuotaExceeded},
+		{"error_500.json", http.StatusInternalServerError, 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			body, err := os.ReadFile(filepath.Join("testdata", "contract", tt.fixture))
+			if err != nil {
+				t.Fatalf("failed to read fixture: %v", err)
+			}
+
+			got, err := parseSearchResponse(&http.Response{StatusCode: tt.status, Body: io.NopCloser(bytes.NewReader(body))})
+
+			if tt.status != http.StatusOK {
+				checkContractError(t, err, tt.status, tt.sentinel)
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("parseSearchResponse: %v", err)
+			}
+
+			if len(got.Items) != tt.results {
+				t.Fatalf("got %d results, want %d", len(got.Items), tt.results)
+			}
+
+			// The streaming decoder must agree with plain struct decoding
+			var want GoogleSearchResponse
+			if err := json.Unmarshal(body, &want); err != nil {
+				t.Fatalf("json.Unmarshal: %v", err)
+			}
+
+			if !reflect.DeepEqual(*got, want) {
+				t.Errorf("decodeSearchResponse = %+v, want %+v", *got, want)
+			}
+
+			for i, result := range got.Items {
+				if result.Title == "" || result.Link == "" {
+					t.Errorf("result %d lacks a title or link: %+v", i+1, result)
+				}
+			}
+
+			text := formatSearchResults(got.Items, catalogs[defaultLocale])
+			if !utf8.ValidString(text) {
+				t.Errorf("text output isn't valid UTF-8:\n%q", text)
+			}
+
+			checkGolden(t, "contract_"+strings.TrimSuffix(tt.fixture, ".json")+".golden", text)
+
+			result, err := formatJSONResult(searchResponse{Results: got.Items}, defaultTokenEstimator)
+			if err != nil {
+				t.Fatalf("formatJSONResult: %v", err)
+			}
+
+			if !json.Valid([]byte(resultText(t, result))) {
+				t.Error("JSON output doesn't parse")
+			}
+		})
+	}
+}
+
+// checkContractError checks that an error response surfaces as an
+// apiStatusError matching sentinel, if any, and classified the way degraded
+// mode expects.
+func checkContractError(t *testing.T, err error, status int, sentinel error) {
+	t.Helper()
+
+	var statusErr *apiStatusError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("err = %v, want *apiStatusError", err)
+	}
+
+	if statusErr.StatusCode != status {
+		t.Errorf("StatusCode = %d, want %d", statusErr.StatusCode, status)
+	}
+
+	if statusErr.Message == "" || statusErr.Reason == "" {
+		t.Errorf("message or reason not parsed from %s", statusErr.Body)
+	}
+
+	if sentinel != nil && !errors.Is(err, sentinel) {
+		t.Errorf("err = %v, want it to match %v", err, sentinel)
+	}
+
+	var quotaErr *quotaError
+	if errors.As(err, &quotaErr) && !quotaErr.ResetAt.After(time.Now()) {
+		t.Errorf("quota ResetAt = %v, want a future time", quotaErr.ResetAt)
+	}
+
+	if want := status >= http.StatusInternalServerError; isUnavailable(err) != want {
+		t.Errorf("isUnavailable = %v, want %v", !want, want)
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// countryCodePattern matches a two-letter country code as Google's gl
+// parameter takes it (ISO 3166-1 alpha-2, with uk for the United Kingdom).
+var countryCodePattern = regexp.MustCompile(`^[a-z]{2}$`)
+
+// extractCountry extracts and validates the gl parameter.
+func extractCountry(arguments map[string]interface{}) (string, error) {
+	country, _ := arguments["gl"].(string)
+	country = strings.ToLower(strings.TrimSpace(country))
+
+	if country == "" {
+		return "", nil
+	}
+
+	if !countryCodePattern.MatchString(country) {
+		return "", fmt.Errorf("gl must be a two-letter country code such as us, de or uk, got %q", country)
+	}
+
+	return country, nil
+}
+
+// extractCountryRestrict extracts and validates the country_restrict
+// parameter, given as one country code or an array of codes, as a cr value
+// such as "countryDE|countryAT". Results from any of the countries match.
+func extractCountryRestrict(arguments map[string]interface{}) (string, error) {
+	var codes []string
+
+	switch raw := arguments["country_restrict"].(type) {
+	case nil:
+		return "", nil
+	case string:
+		codes = strings.FieldsFunc(raw, func(r rune) bool { return r == ',' || r == '|' || r == ' ' })
+	case []interface{}:
+		for _, item := range raw {
+			code, ok := item.(string)
+			if !ok {
+				return "", fmt.Errorf("country_restrict must be a country code or an array of country codes")
+			}
+
+			codes = append(codes, code)
+		}
+	default:
+		return "", fmt.Errorf("country_restrict must be a country code or an array of country codes")
+	}
+
+	restricts := make([]string, 0, len(codes))
+
+	for _, code := range codes {
+		// Accept the API's own countryDE form as well as plain codes
+		code = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(code)), "country")
+		if !countryCodePattern.MatchString(code) {
+			return "", fmt.Errorf("country_restrict values must be two-letter country codes such as de or uk, got %q", code)
+		}
+
+		restricts = append(restricts, "country"+strings.ToUpper(code))
+	}
+
+	return strings.Join(restricts, "|"), nil
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// maxCoverageKeywords bounds the API calls a single coverage report can make.
+const maxCoverageKeywords = 50
+
+// keywordCoverage is one row of the coverage matrix.
+type keywordCoverage struct {
+	Keyword   string `json:"keyword"`
+	Covered   bool   `json:"covered"`
+	Positions []int  `json:"positions"`
+	Error     string `json:"error,omitempty"`
+}
+
+// coverageReport is the structured form of a keyword coverage report.
+type coverageReport struct {
+	Domain   string            `json:"domain"`
+	Depth    int               `json:"depth"`
+	Covered  int               `json:"covered"`
+	Total    int               `json:"total"`
+	APICalls int               `json:"api_calls"`
+	Keywords []keywordCoverage `json:"keywords"`
+}
+
+// registerKeywordCoverageTool creates and registers the keyword_coverage tool with the server.
+func registerKeywordCoverageTool(s *server.MCPServer, config *Config) {
+	s.AddTool(createKeywordCoverageTool(), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleKeywordCoverageRequest(ctx, request, config)
+	})
+}
+
+// createKeywordCoverageTool creates and configures the keyword_coverage tool.
+func createKeywordCoverageTool() mcp.Tool {
+	return mcp.NewTool("keyword_coverage",
+		mcp.WithDescription("For a list of keywords, report in which ones a domain appears among the top results"),
+		mcp.WithArray("keywords",
+			mcp.Required(),
+			mcp.Description(fmt.Sprintf("Keywords (queries) to check (max %d)", maxCoverageKeywords)),
+			mcp.Items(map[string]interface{}{"type": "string"}),
+		),
+		mcp.WithString("domain",
+			mcp.Required(),
+			mcp.Description("Domain to look for, e.g. example.com (subdomains match)"),
+		),
+		mcp.WithNumber("depth",
+			mcp.Description(fmt.Sprintf("How many top results to inspect per keyword (max %d, default %d); each 10 results cost one API call per keyword", maxSearchDepth, defaultRankDepth)),
+		),
+		mcp.WithString("output_format",
+			mcp.Description("Report format: text (default, a table), plain (the table restricted to printable ASCII) or json (the coverage matrix)"),
+			mcp.Enum(outputText, outputPlain, outputJSON),
+		),
+	)
+}
+
+// handleKeywordCoverageRequest processes a keyword_coverage tool request.
+func handleKeywordCoverageRequest(ctx context.Context,
+	request mcp.CallToolRequest,
+	config *Config,
+) (*mcp.CallToolResult, error) {
+	keywords, err := extractKeywords(request.Params.Arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	domain, _ := request.Params.Arguments["domain"].(string)
+	domain = normalizeDomain(domain)
+	if domain == "" {
+		return nil, fmt.Errorf("domain must be a non-empty string")
+	}
+
+	outputFormat, err := extractOutputFormat(request.Params.Arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	depth, err := extractDepth(request.Params.Arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	report := coverageReport{
+		Domain: domain,
+		Depth:  depth,
+		Total:  len(keywords),
+	}
+
+	// Stream each keyword's row as soon as it is known
+	progress := newProgressReporter(ctx, request, len(keywords))
+
+	for i, keyword := range keywords {
+		row := keywordCoverage{Keyword: keyword, Positions: []int{}}
+
+		results, apiCalls, err := searchDepth(ctx, "keyword_coverage", keyword, report.Depth, config, nil)
+		report.APICalls += apiCalls
+
+		if err != nil {
+			// Keep going: one bad keyword shouldn't lose the rest of the report
+			row.Error = err.Error()
+		} else if positions := domainPositions(results, domain); len(positions) > 0 {
+			row.Positions = positions
+			row.Covered = true
+			report.Covered++
+		}
+
+		report.Keywords = append(report.Keywords, row)
+		progress.reportJSON(i+1, row)
+	}
+
+	if outputFormat == outputJSON {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode report: %v", err)
+		}
+
+		return mcp.NewToolResultText(string(data)), nil
+	}
+
+	text := formatCoverageReport(report)
+	if outputFormat == outputPlain {
+		text = plainText(text)
+	}
+
+	return mcp.NewToolResultText(text), nil
+}
+
+// extractKeywords extracts and de-duplicates the keywords parameter.
+func extractKeywords(arguments map[string]interface{}) ([]string, error) {
+	raw, _ := arguments["keywords"].([]interface{})
+
+	var keywords []string
+
+	seen := map[string]bool{}
+
+	for _, item := range raw {
+		keyword, _ := item.(string)
+		keyword = strings.TrimSpace(keyword)
+
+		if keyword != "" && !seen[keyword] {
+			seen[keyword] = true
+			keywords = append(keywords, keyword)
+		}
+	}
+
+	if len(keywords) == 0 {
+		return nil, fmt.Errorf("keywords must be a non-empty array of strings")
+	}
+
+	if len(keywords) > maxCoverageKeywords {
+		return nil, fmt.Errorf("at most %d keywords can be checked at once", maxCoverageKeywords)
+	}
+
+	return keywords, nil
+}
+
+// formatCoverageReport renders the coverage matrix as a markdown table.
+func formatCoverageReport(report coverageReport) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "Keyword coverage for %s (top %d): %d of %d keywords covered\n\n", report.Domain, report.Depth, report.Covered, report.Total)
+	sb.WriteString("| Keyword | Covered | Positions |\n")
+	sb.WriteString("|---|---|---|\n")
+
+	for _, row := range report.Keywords {
+		covered, positions := "no", formatPositions(row.Positions)
+
+		switch {
+		case row.Error != "":
+			covered, positions = "error", row.Error
+		case row.Covered:
+			covered = "yes"
+		}
+
+		fmt.Fprintf(&sb, "| %s | %s | %s |\n", escapeTableCell(row.Keyword), covered, escapeTableCell(positions))
+	}
+
+	fmt.Fprintf(&sb, "\n---\nprovider: %s | api_calls: %d\n", providerName, report.APICalls)
+
+	return sb.String()
+}
+
+// escapeTableCell keeps a value from breaking out of its markdown table cell.
+func escapeTableCell(value string) string {
+	return strings.NewReplacer("|", "\\|", "\n", " ").Replace(value)
+}
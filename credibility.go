@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Reputation tiers assigned to result sources.
+const (
+	tierTrusted      = "trusted"
+	tierQuestionable = "questionable"
+	tierUnrated      = "unrated"
+)
+
+// Values of the credibility tool argument.
+const (
+	credibilityAnnotate = "annotate"
+	credibilityDownrank = "downrank"
+	credibilityExclude  = "exclude"
+)
+
+// credibilityLists maps domains to reputation tiers. A nil list disables annotation.
+type credibilityLists struct {
+	tiers map[string]string
+}
+
+// loadCredibilityLists reads GOOGLE_SEARCH_TRUSTED_DOMAINS and
+// GOOGLE_SEARCH_QUESTIONABLE_DOMAINS. Each is a comma-separated list of
+// domains, or @path to a file with one domain per line (# starts a comment).
+func loadCredibilityLists() (*credibilityLists, error) {
+	tiers := map[string]string{}
+
+	for _, list := range []struct{ env, tier string }{
+		{"GOOGLE_SEARCH_TRUSTED_DOMAINS", tierTrusted},
+		{"GOOGLE_SEARCH_QUESTIONABLE_DOMAINS", tierQuestionable},
+	} {
+		domains, err := readDomainList(os.Getenv(list.env))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", list.env, err)
+		}
+
+		for _, domain := range domains {
+			tiers[domain] = list.tier
+		}
+	}
+
+	if len(tiers) == 0 {
+		return nil, nil
+	}
+
+	return &credibilityLists{tiers: tiers}, nil
+}
+
+// readDomainList parses a comma-separated domain list or an @file reference.
+func readDomainList(value string) ([]string, error) {
+	var entries []string
+
+	if path, ok := strings.CutPrefix(value, "@"); ok {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open domain list: %v", err)
+		}
+		defer f.Close()
+
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line, _, _ := strings.Cut(scanner.Text(), "#")
+			entries = append(entries, line)
+		}
+
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read domain list: %v", err)
+		}
+	} else {
+		entries = strings.Split(value, ",")
+	}
+
+	var domains []string
+
+	for _, entry := range entries {
+		domain := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(entry), "*."))
+		if domain != "" {
+			domains = append(domains, domain)
+		}
+	}
+
+	return domains, nil
+}
+
+// tier returns the reputation tier of link's host, matching parent domains too.
+func (c *credibilityLists) tier(link string) string {
+	if tier, ok := lookupDomain(c.tiers, link); ok {
+		return tier
+	}
+
+	return tierUnrated
+}
+
+// lookupDomain finds the entry for link's host in m, falling back to its
+// parent domains (news.example.com matches example.com).
+func lookupDomain[V any](m map[string]V, link string) (V, bool) {
+	var zero V
+
+	u, err := url.Parse(link)
+	if err != nil {
+		return zero, false
+	}
+
+	host := strings.ToLower(u.Hostname())
+	for host != "" {
+		if value, ok := m[host]; ok {
+			return value, true
+		}
+
+		_, parent, found := strings.Cut(host, ".")
+		if !found {
+			break
+		}
+
+		host = parent
+	}
+
+	return zero, false
+}
+
+// apply annotates results with their tier and, depending on mode, moves
+// questionable sources to the end or removes them.
+func (c *credibilityLists) apply(results []GoogleSearchResult, mode string) []GoogleSearchResult {
+	if c == nil {
+		return results
+	}
+
+	var kept, demoted []GoogleSearchResult
+
+	for _, result := range results {
+		result.Credibility = c.tier(result.Link)
+
+		if result.Credibility == tierQuestionable {
+			switch mode {
+			case credibilityExclude:
+				continue
+			case credibilityDownrank:
+				demoted = append(demoted, result)
+
+				continue
+			}
+		}
+
+		kept = append(kept, result)
+	}
+
+	return append(kept, demoted...)
+}
+
+// extractCredibilityMode extracts and validates the credibility parameter.
+func extractCredibilityMode(arguments map[string]interface{}) (string, error) {
+	mode, _ := arguments["credibility"].(string)
+
+	switch mode {
+	case "":
+		return credibilityAnnotate, nil
+	case credibilityAnnotate, credibilityDownrank, credibilityExclude:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("credibility must be one of %s, %s or %s", credibilityAnnotate, credibilityDownrank, credibilityExclude)
+	}
+}
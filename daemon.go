@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// setupDaemon prepares the process to run as a long-lived service.
+// It returns a cleanup function that must be called on exit.
+func setupDaemon(opts *Options) (func(), error) {
+	// journald already timestamps every line, so don't duplicate it
+	if os.Getenv("JOURNAL_STREAM") != "" {
+		log.SetFlags(0)
+	}
+
+	if opts.PIDFile == "" {
+		return func() {}, nil
+	}
+
+	if err := writePIDFile(opts.PIDFile); err != nil {
+		return nil, err
+	}
+
+	return func() {
+		if err := os.Remove(opts.PIDFile); err != nil && !os.IsNotExist(err) {
+			log.Printf("Failed to remove pidfile: %v", err)
+		}
+	}, nil
+}
+
+// writePIDFile writes the current process ID to path, refusing to clobber a live instance.
+func writePIDFile(path string) error {
+	if data, err := os.ReadFile(path); err == nil {
+		if pid, err := strconv.Atoi(strings.TrimSpace(string(data))); err == nil && processAlive(pid) {
+			return fmt.Errorf("pidfile %s is held by running process %d", path, pid)
+		}
+	}
+
+	if err := os.WriteFile(path, []byte(strconv.Itoa(os.Getpid())+"\n"), 0o644); err != nil {
+		return fmt.Errorf("failed to write pidfile: %v", err)
+	}
+
+	return nil
+}
+
+// processAlive reports whether a process with the given ID exists.
+func processAlive(pid int) bool {
+	if pid <= 0 || pid == os.Getpid() {
+		return false
+	}
+
+	_, err := os.Stat("/proc/" + strconv.Itoa(pid))
+
+	return err == nil
+}
+
+// notifyReady tells systemd the service finished starting up.
+func notifyReady() {
+	sdNotify("READY=1\nMAINPID=" + strconv.Itoa(os.Getpid()))
+}
+
+// notifyStopping tells systemd the service is shutting down.
+func notifyStopping() {
+	sdNotify("STOPPING=1")
+}
+
+// sdNotify sends a state update to the systemd notification socket, if there is one.
+func sdNotify(state string) {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return
+	}
+
+	// Abstract namespace sockets are announced with a leading '@'
+	if strings.HasPrefix(socket, "@") {
+		socket = "\x00" + socket[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		log.Printf("sd_notify failed: %v", err)
+
+		return
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(state)); err != nil {
+		log.Printf("sd_notify failed: %v", err)
+	}
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestRunServerRemovesPIDFileOnError removes the pidfile when the server fails
+// to start, so the next start isn't blocked.
+func TestRunServerRemovesPIDFileOnError(t *testing.T) {
+	t.Setenv("GOOGLE_API_KEY", "")
+	t.Setenv("GOOGLE_SEARCH_ENGINE_ID", "")
+	t.Setenv(configFileEnv, filepath.Join(t.TempDir(), "config.env"))
+
+	pidFile := filepath.Join(t.TempDir(), "server.pid")
+
+	if err := runServer(&Options{Transport: "stdio", Daemon: true, PIDFile: pidFile}); err == nil {
+		t.Fatal("runServer succeeded without credentials")
+	}
+
+	if fileExists(pidFile) {
+		t.Errorf("pidfile %s left behind", pidFile)
+	}
+}
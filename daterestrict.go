@@ -0,0 +1,23 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// dateRestrictPattern matches dateRestrict values: d, w, m or y followed by a count.
+var dateRestrictPattern = regexp.MustCompile(`^[dwmy][1-9][0-9]*$`)
+
+// extractDateRestrict extracts and validates the date_restrict parameter.
+func extractDateRestrict(arguments map[string]interface{}) (string, error) {
+	value, _ := arguments["date_restrict"].(string)
+	if value == "" {
+		return "", nil
+	}
+
+	if !dateRestrictPattern.MatchString(value) {
+		return "", fmt.Errorf("date_restrict must be d, w, m or y followed by a number, e.g. d1, w2, m6 or y1; got %q", value)
+	}
+
+	return value, nil
+}
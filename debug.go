@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"sync/atomic"
+)
+
+// debugLogging enables debugf output. --debug sets it on startup and
+// debugSignal toggles it while the server runs.
+var debugLogging atomic.Bool
+
+// debugf logs a message if debug logging is on.
+func debugf(format string, args ...interface{}) {
+	if debugLogging.Load() {
+		log.Printf("DEBUG: "+format, args...)
+	}
+}
+
+// setDebugLogging turns debug logging on or off and logs the change.
+func setDebugLogging(on bool) {
+	debugLogging.Store(on)
+
+	if on {
+		log.Printf("Debug logging on")
+	} else {
+		log.Printf("Debug logging off")
+	}
+}
+
+// toggleDebugOnSignal toggles debug logging each time the process receives
+// debugSignal, until ctx is done. It does nothing where the platform has no
+// such signal.
+func toggleDebugOnSignal(ctx context.Context) {
+	if debugSignal == nil {
+		return
+	}
+
+	// Subscribe before returning: the signal's default action is to exit
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, debugSignal)
+
+	go func() {
+		defer signal.Stop(signals)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-signals:
+				setDebugLogging(!debugLogging.Load())
+			}
+		}
+	}()
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package main
+
+import "os"
+
+// debugSignal is nil: there is no spare signal to toggle debug logging with.
+var debugSignal os.Signal
@@ -0,0 +1,39 @@
+//go:build unix
+
+package main
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestDebugSignalToggles(t *testing.T) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+	t.Cleanup(func() { debugLogging.Store(false) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	toggleDebugOnSignal(ctx)
+
+	for _, want := range []bool{true, false} {
+		if err := syscall.Kill(os.Getpid(), syscall.SIGUSR1); err != nil {
+			t.Fatal(err)
+		}
+
+		deadline := time.Now().Add(5 * time.Second)
+		for debugLogging.Load() != want && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+
+		if debugLogging.Load() != want {
+			t.Fatalf("debug logging = %v after SIGUSR1, want %v", !want, want)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// debugSignal toggles debug logging: kill -USR1 <pid>.
+var debugSignal os.Signal = syscall.SIGUSR1
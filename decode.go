@@ -0,0 +1,254 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// skippedValue discards a JSON value without building it. The decoder hands
+// UnmarshalJSON a slice of its own buffer, so skipping doesn't copy.
+type skippedValue struct{}
+
+// UnmarshalJSON implements json.Unmarshaler by ignoring the value.
+func (*skippedValue) UnmarshalJSON([]byte) error {
+	return nil
+}
+
+// decodeSearchResponse decodes an API response by walking its tokens and
+// extracting only the fields GoogleSearchResponse models. Everything else,
+// notably the site-defined pagemap types, is skipped without being
+// materialized.
+func decodeSearchResponse(r io.Reader) (*GoogleSearchResponse, error) {
+	dec := json.NewDecoder(r)
+	response := &GoogleSearchResponse{}
+
+	_, err := walkObject(dec, func(key string) error {
+		switch key {
+		case "items":
+			return walkArray(dec, func() error {
+				var item GoogleSearchResult
+				if _, err := walkObject(dec, func(key string) error {
+					return decodeItemField(dec, key, &item)
+				}); err != nil {
+					return err
+				}
+
+				response.Items = append(response.Items, item)
+
+				return nil
+			})
+		case "spelling":
+			var spelling SpellingInfo
+
+			found, err := walkObject(dec, func(key string) error {
+				if key == "correctedQuery" {
+					return decodeString(dec, &spelling.CorrectedQuery)
+				}
+
+				return dec.Decode(&skippedValue{})
+			})
+			if found {
+				response.Spelling = &spelling
+			}
+
+			return err
+		case "queries":
+			return dec.Decode(&response.Queries)
+		case "searchInformation":
+			return dec.Decode(&response.Information)
+		case "promotions":
+			return dec.Decode(&response.Promotions)
+		default:
+			return dec.Decode(&skippedValue{})
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// decodeItemField decodes the value of one field of a result item.
+func decodeItemField(dec *json.Decoder, key string, item *GoogleSearchResult) error {
+	switch key {
+	case "title":
+		return decodeString(dec, &item.Title)
+	case "link":
+		return decodeString(dec, &item.Link)
+	case "snippet":
+		return decodeString(dec, &item.Snippet)
+	case "displayLink":
+		return decodeString(dec, &item.DisplayLink)
+	case "htmlTitle":
+		return decodeString(dec, &item.HTMLTitle)
+	case "htmlSnippet":
+		return decodeString(dec, &item.HTMLSnippet)
+	case "formattedUrl":
+		return decodeString(dec, &item.FormattedURL)
+	case "htmlFormattedUrl":
+		return decodeString(dec, &item.HTMLFormattedURL)
+	case "cacheId":
+		return decodeString(dec, &item.CacheID)
+	case "mime":
+		return decodeString(dec, &item.Mime)
+	case "fileFormat":
+		return decodeString(dec, &item.FileFormat)
+	case "labels":
+		return dec.Decode(&item.Labels)
+	case "image":
+		return dec.Decode(&item.Image)
+	case "pagemap":
+		var pagemap ResultPagemap
+
+		found, err := walkObject(dec, func(key string) error {
+			return decodePagemapField(dec, key, &pagemap)
+		})
+		if found {
+			item.Pagemap = &pagemap
+		}
+
+		return err
+	default:
+		return dec.Decode(&skippedValue{})
+	}
+}
+
+// decodePagemapField decodes one pagemap type, skipping the site-defined
+// ones ResultPagemap doesn't model.
+func decodePagemapField(dec *json.Decoder, key string, pagemap *ResultPagemap) error {
+	switch key {
+	case "cse_thumbnail":
+		return dec.Decode(&pagemap.Thumbnails)
+	case "cse_image":
+		return dec.Decode(&pagemap.Images)
+	case "metatags":
+		return walkArray(dec, func() error {
+			tags := map[string]string{}
+			if _, err := walkObject(dec, func(name string) error {
+				return decodeMetatag(dec, name, tags)
+			}); err != nil {
+				return err
+			}
+
+			pagemap.Metatags = append(pagemap.Metatags, tags)
+
+			return nil
+		})
+	default:
+		return dec.Decode(&skippedValue{})
+	}
+}
+
+// decodeMetatag stores a metatag's content in tags. Tags are page markup,
+// so a content that isn't a string is dropped rather than failing the search.
+func decodeMetatag(dec *json.Decoder, name string, tags map[string]string) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	if content, ok := tok.(string); ok {
+		tags[name] = content
+
+		return nil
+	}
+
+	// Skip the rest of an object or array
+	for depth := 0; ; {
+		if delim, ok := tok.(json.Delim); ok {
+			if delim == '{' || delim == '[' {
+				depth++
+			} else {
+				depth--
+			}
+		}
+
+		if depth == 0 {
+			return nil
+		}
+
+		if tok, err = dec.Token(); err != nil {
+			return err
+		}
+	}
+}
+
+// walkObject reads an object, calling field for each key with the decoder
+// positioned at the key's value. field must consume the value. It reports
+// false if the value was null.
+func walkObject(dec *json.Decoder, field func(key string) error) (bool, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return false, err
+	}
+
+	if tok == nil {
+		return false, nil
+	}
+
+	if tok != json.Delim('{') {
+		return false, fmt.Errorf("expected object, got %v", tok)
+	}
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return true, err
+		}
+
+		if err := field(tok.(string)); err != nil {
+			return true, err
+		}
+	}
+
+	_, err = dec.Token()
+
+	return true, err
+}
+
+// walkArray reads an array, calling elem once per element. elem must
+// consume the element. A null array is treated as empty.
+func walkArray(dec *json.Decoder, elem func() error) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	if tok == nil {
+		return nil
+	}
+
+	if tok != json.Delim('[') {
+		return fmt.Errorf("expected array, got %v", tok)
+	}
+
+	for dec.More() {
+		if err := elem(); err != nil {
+			return err
+		}
+	}
+
+	_, err = dec.Token()
+
+	return err
+}
+
+// decodeString reads a string (or null) into dst.
+func decodeString(dec *json.Decoder, dst *string) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	switch v := tok.(type) {
+	case string:
+		*dst = v
+	case nil:
+	default:
+		return fmt.Errorf("expected string, got %v", tok)
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// loadSearchFixture reads a recorded Custom Search API response.
+func loadSearchFixture(tb testing.TB) []byte {
+	tb.Helper()
+
+	data, err := os.ReadFile("testdata/search_response.json")
+	if err != nil {
+		tb.Fatalf("failed to read fixture: %v", err)
+	}
+
+	return data
+}
+
+func TestDecodeSearchResponseMatchesStructDecoding(t *testing.T) {
+	data := loadSearchFixture(t)
+
+	var want GoogleSearchResponse
+	if err := json.Unmarshal(data, &want); err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+
+	got, err := decodeSearchResponse(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("decodeSearchResponse: %v", err)
+	}
+
+	if !reflect.DeepEqual(*got, want) {
+		t.Errorf("decodeSearchResponse = %+v, want %+v", *got, want)
+	}
+}
+
+func TestDecodeSearchResponseEdgeCases(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    GoogleSearchResponse
+		wantErr bool
+	}{
+		{name: "no items", body: `{"kind": "customsearch#search", "queries": {}}`,
+			want: GoogleSearchResponse{Queries: &SearchQueries{}}},
+		{name: "next page", body: `{"queries": {"request": [{"startIndex": 1}], "nextPage": [{"count": 10, "startIndex": 11}]}}`,
+			want: GoogleSearchResponse{Queries: &SearchQueries{NextPage: []PageQuery{{StartIndex: 11}}}}},
+		{name: "null fields", body: `{"items": [{"title": null, "link": "https://a"}], "spelling": null}`,
+			want: GoogleSearchResponse{Items: []GoogleSearchResult{{Link: "https://a"}}}},
+		{name: "pagemap", body: `{"items": [{"link": "https://a", "pagemap": {"metatags": [{"og:title": "T", "og:image:width": 300}], "product": [{"name": "P"}]}}]}`,
+			want: GoogleSearchResponse{Items: []GoogleSearchResult{{Link: "https://a", Pagemap: &ResultPagemap{Metatags: []map[string]string{{"og:title": "T"}}}}}}},
+		{name: "search information", body: `{"searchInformation": {"searchTime": 0.29, "formattedSearchTime": "0.29", "totalResults": "412000"}}`,
+			want: GoogleSearchResponse{Information: &SearchInformation{TotalResults: "412000", SearchTime: 0.29}}},
+		{name: "promotions", body: `{"promotions": [{"title": "Help", "htmlTitle": "<b>Help</b>", "link": "https://a/help", "displayLink": "a", "bodyLines": [{"title": "Contact us", "url": "https://a/contact", "link": "https://a/contact"}], "image": {"source": "https://a/i.png"}}]}`,
+			want: GoogleSearchResponse{Promotions: []Promotion{{Title: "Help", Link: "https://a/help", DisplayLink: "a", BodyLines: []PromotionLine{{Title: "Contact us", Link: "https://a/contact"}}}}}},
+		{name: "truncated", body: `{"items": [{"title": "a"`, wantErr: true},
+		{name: "wrong type", body: `{"items": {"title": "a"}}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeSearchResponse(bytes.NewReader([]byte(tt.body)))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err == nil && !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("got %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func BenchmarkDecodeSearchResponse(b *testing.B) {
+	data := loadSearchFixture(b)
+
+	b.Run("struct", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(data)))
+
+		for i := 0; i < b.N; i++ {
+			var response GoogleSearchResponse
+			if err := json.NewDecoder(bytes.NewReader(data)).Decode(&response); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("tokens", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(data)))
+
+		for i := 0; i < b.N; i++ {
+			if _, err := decodeSearchResponse(bytes.NewReader(data)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
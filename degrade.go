@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	// degradeAfter is how many consecutive unavailability errors switch
+	// google_search to serving cached results.
+	degradeAfter = 3
+	// recoveryProbeInterval is how often a degraded server lets one search
+	// through to check whether the API is back.
+	recoveryProbeInterval = 30 * time.Second
+	// maxStaleEntries bounds the results kept for degraded mode.
+	maxStaleEntries = 500
+	// maxStaleAge is the oldest cached result served in degraded mode.
+	maxStaleAge = 24 * time.Hour
+)
+
+// errUpstreamDegraded is returned instead of calling the API while it is
+// considered unavailable.
+var errUpstreamDegraded = errors.New("the search API has been unreachable for the last few requests")
+
+// isUnavailable reports whether err means the API couldn't be reached or
+// failed on its side, as opposed to rejecting the request.
+func isUnavailable(err error) bool {
+	// The local budget refused the call; the API wasn't asked
+	if errors.Is(err, errQuotaExceeded) {
+		return false
+	}
+
+	var statusErr *apiStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= http.StatusInternalServerError
+	}
+
+	// Only network failures, including timeouts and responses cut off
+	// mid-transfer, mean the API is down; other errors are ours
+	var (
+		netErr net.Error
+		urlErr *url.Error
+	)
+
+	return errors.Is(err, errUpstreamDegraded) || errors.As(err, &netErr) || errors.As(err, &urlErr) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// staleEntry is a cached successful outcome.
+type staleEntry struct {
+	outcome searchOutcome
+	at      time.Time
+}
+
+// degradation tracks API availability and keeps recent google_search
+// outcomes to serve, clearly labeled, while the API is down. It is safe for
+// concurrent use.
+type degradation struct {
+	mu        sync.Mutex
+	failures  int
+	lastProbe time.Time
+	entries   map[string]*staleEntry
+}
+
+// newDegradation creates an empty tracker.
+func newDegradation() *degradation {
+	return &degradation{entries: make(map[string]*staleEntry)}
+}
+
+// staleKey identifies a search by everything that affects its results.
+func staleKey(opts SearchOptions, searchEngineID string) string {
+	return buildSearchParams(opts, "", searchEngineID).Encode()
+}
+
+// shouldSkipUpstream reports whether the API is considered down and this
+// call should not wait on it. One call per recoveryProbeInterval still goes
+// through to detect recovery.
+func (d *degradation) shouldSkipUpstream() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.failures < degradeAfter {
+		return false
+	}
+
+	if time.Since(d.lastProbe) >= recoveryProbeInterval {
+		d.lastProbe = time.Now()
+
+		return false
+	}
+
+	return true
+}
+
+// record notes the outcome of a search and caches successful outcomes.
+func (d *degradation) record(key string, outcome *searchOutcome, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err != nil {
+		if isUnavailable(err) {
+			d.failures++
+		}
+
+		return
+	}
+
+	d.failures = 0
+
+	if _, ok := d.entries[key]; !ok && len(d.entries) >= maxStaleEntries {
+		d.evictOldest()
+	}
+
+	cached := *outcome
+	cached.Results = append([]GoogleSearchResult(nil), outcome.Results...)
+	d.entries[key] = &staleEntry{outcome: cached, at: time.Now()}
+}
+
+// stale returns a copy of the cached outcome for key and its age, if one
+// not older than maxStaleAge exists.
+func (d *degradation) stale(key string) (*searchOutcome, time.Duration, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	entry, ok := d.entries[key]
+	if !ok || time.Since(entry.at) > maxStaleAge {
+		return nil, 0, false
+	}
+
+	outcome := entry.outcome
+	outcome.Results = append([]GoogleSearchResult(nil), entry.outcome.Results...)
+	outcome.APICalls = 0
+
+	return &outcome, time.Since(entry.at), true
+}
+
+// evictOldest drops the oldest cached outcome. Callers must hold mu.
+func (d *degradation) evictOldest() {
+	var (
+		oldestKey string
+		oldest    time.Time
+	)
+
+	for key, entry := range d.entries {
+		if oldestKey == "" || entry.at.Before(oldest) {
+			oldestKey, oldest = key, entry.at
+		}
+	}
+
+	delete(d.entries, oldestKey)
+}
+
+// searchOrStale runs searchWithFallbacks unless the API is known to be down,
+// and falls back to a cached outcome when the API is unavailable or the
+// monthly spend cap refuses paid calls. The returned age is non-zero when
+// the outcome is stale.
+func (d *degradation) searchOrStale(ctx context.Context, opts SearchOptions, config *Config, strategies []string) (*searchOutcome, time.Duration, error) {
+	key := staleKey(opts, config.SearchEngineID)
+
+	var (
+		outcome *searchOutcome
+		err     = errUpstreamDegraded
+	)
+
+	if !d.shouldSkipUpstream() {
+		outcome, err = searchWithFallbacks(ctx, opts, config, strategies)
+		d.record(key, outcome, err)
+	}
+
+	// Cached results cost nothing, so they are served past the spend cap too
+	if err == nil || !(isUnavailable(err) || errors.Is(err, errSpendCapped)) {
+		return outcome, 0, err
+	}
+
+	if cached, age, ok := d.stale(key); ok {
+		debugf("search API unavailable (%v); serving results cached %v ago", err, age)
+
+		return cached, max(age, time.Second), nil
+	}
+
+	return nil, 0, err
+}
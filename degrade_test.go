@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// TestIsUnavailable treats only network failures, server errors and the
+// degraded state as unavailability; rejections and unknown errors are not.
+func TestIsUnavailable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"connection refused", fmt.Errorf("HTTP request failed: %w", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}), true},
+		{"truncated body", fmt.Errorf("failed to parse API response: %w", io.ErrUnexpectedEOF), true},
+		{"server error", &apiStatusError{StatusCode: 503}, true},
+		{"degraded", errUpstreamDegraded, true},
+		{"rejected", fmt.Errorf("fallback (dropped quotes) failed: %w", &apiStatusError{StatusCode: 403}), false},
+		{"budget", fmt.Errorf("fallback (dropped quotes) failed: %w", &budgetError{Used: 1, Allowed: 1}), false},
+		{"unknown", errors.New("failed to parse API response: expected object"), false},
+		{"cancelled", context.Canceled, false},
+		{"nil", nil, false},
+	}
+
+	for _, tt := range tests {
+		if got := isUnavailable(tt.err); got != tt.want {
+			t.Errorf("%s: isUnavailable(%v) = %v, want %v", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
+
+// TestSearchOrStaleServesStaleOnTimeout serves cached results when the API
+// hangs past the adaptive timeout, the outage degraded mode exists for.
+func TestSearchOrStaleServesStaleOnTimeout(t *testing.T) {
+	newConformanceServer(t, newFakeSearchAPI())
+
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig: %v", err)
+	}
+
+	// Enough fast requests to bring the timeout down to its minimum
+	previous := providerLatency
+	providerLatency = newLatencyTracker(latencyWindow)
+	for i := 0; i < minLatencySamples; i++ {
+		providerLatency.observe(10 * time.Millisecond)
+	}
+	t.Cleanup(func() { providerLatency = previous })
+
+	d := newDegradation()
+	opts := SearchOptions{Query: "golang", NumResults: 5}
+
+	if _, _, err := d.searchOrStale(context.Background(), opts, config, nil); err != nil {
+		t.Fatalf("first search: %v", err)
+	}
+
+	// The API now accepts requests but never answers
+	searchClient = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		<-req.Context().Done()
+
+		return nil, req.Context().Err()
+	})}
+
+	outcome, age, err := d.searchOrStale(context.Background(), opts, config, nil)
+	if err != nil {
+		t.Fatalf("search while the API hangs: %v, want cached results", err)
+	}
+
+	if age == 0 || len(outcome.Results) != 5 {
+		t.Errorf("got %d results %v old, want the 5 cached results marked stale", len(outcome.Results), age)
+	}
+}
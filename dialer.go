@@ -0,0 +1,291 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultDNSTTL is how long resolved API addresses are reused.
+	defaultDNSTTL = 5 * time.Minute
+	// happyEyeballsDelay is how long the preferred address family gets before
+	// the other one is tried in parallel (RFC 8305).
+	happyEyeballsDelay = 300 * time.Millisecond
+	// dialTimeout bounds a single connection attempt.
+	dialTimeout = 10 * time.Second
+)
+
+// IP families for GOOGLE_SEARCH_IP_FAMILY.
+const (
+	ipFamilyAuto = "auto"
+	ipFamilyIPv4 = "ipv4"
+	ipFamilyIPv6 = "ipv6"
+)
+
+// searchDialer dials API connections for searchClient.
+var searchDialer = &cachingDialer{
+	ttl:    defaultDNSTTL,
+	family: ipFamilyAuto,
+	cache:  make(map[string]dnsEntry),
+}
+
+// dialAddr connects to a single address. Tests replace it to simulate
+// unreachable hosts.
+var dialAddr = (&net.Dialer{Timeout: dialTimeout, KeepAlive: 30 * time.Second}).DialContext
+
+// searchClient sends Custom Search API requests through searchDialer,
+// injecting faults when chaos mode is enabled.
+var searchClient = newSearchClient()
+
+// newSearchClient creates an HTTP client dialing through searchDialer.
+func newSearchClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = searchDialer.DialContext
+
+	return &http.Client{Transport: &chaosTransport{next: transport}}
+}
+
+// dnsEntry is a cached lookup result.
+type dnsEntry struct {
+	ips     []net.IP
+	expires time.Time
+}
+
+// cachingDialer resolves hosts through a small TTL cache and dials with
+// happy eyeballs, so slow resolvers and broken IPv6 don't add to every
+// connection. It is safe for concurrent use.
+type cachingDialer struct {
+	mu     sync.Mutex
+	ttl    time.Duration
+	family string
+	cache  map[string]dnsEntry
+}
+
+// loadDialerSettings reads GOOGLE_SEARCH_DNS_TTL and GOOGLE_SEARCH_IP_FAMILY.
+func loadDialerSettings() (time.Duration, string, error) {
+	ttl := defaultDNSTTL
+
+	if value := os.Getenv("GOOGLE_SEARCH_DNS_TTL"); value != "" {
+		var err error
+
+		ttl, err = time.ParseDuration(value)
+		if err != nil || ttl < 0 {
+			return 0, "", fmt.Errorf("GOOGLE_SEARCH_DNS_TTL must be a duration such as 5m (0 disables caching), got %q", value)
+		}
+	}
+
+	family := os.Getenv("GOOGLE_SEARCH_IP_FAMILY")
+	switch family {
+	case "":
+		family = ipFamilyAuto
+	case ipFamilyAuto, ipFamilyIPv4, ipFamilyIPv6:
+	default:
+		return 0, "", fmt.Errorf("GOOGLE_SEARCH_IP_FAMILY must be %s, %s or %s, got %q", ipFamilyAuto, ipFamilyIPv4, ipFamilyIPv6, family)
+	}
+
+	return ttl, family, nil
+}
+
+// configure changes the cache TTL and address family and drops cached entries.
+func (d *cachingDialer) configure(ttl time.Duration, family string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.ttl = ttl
+	d.family = family
+	d.cache = make(map[string]dnsEntry)
+}
+
+// lookup resolves host, serving from the cache while entries are fresh. If
+// resolution fails, an expired entry is used rather than failing the request.
+func (d *cachingDialer) lookup(ctx context.Context, host string) ([]net.IP, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return []net.IP{ip}, nil
+	}
+
+	d.mu.Lock()
+	entry, cached := d.cache[host]
+	ttl := d.ttl
+	d.mu.Unlock()
+
+	if cached && time.Now().Before(entry.expires) {
+		return entry.ips, nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		if cached {
+			return entry.ips, nil
+		}
+
+		return nil, err
+	}
+
+	ips := make([]net.IP, len(addrs))
+	for i, addr := range addrs {
+		ips[i] = addr.IP
+	}
+
+	if ttl > 0 {
+		d.mu.Lock()
+		d.cache[host] = dnsEntry{ips: ips, expires: time.Now().Add(ttl)}
+		d.mu.Unlock()
+	}
+
+	return ips, nil
+}
+
+// DialContext connects to addr, trying the preferred address family first
+// and racing the other one after happyEyeballsDelay.
+func (d *cachingDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	ips, err := d.lookup(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	family := d.family
+	d.mu.Unlock()
+
+	primaries, fallbacks := partitionIPs(ips, network, family)
+	if len(primaries) == 0 {
+		return nil, fmt.Errorf("no %s addresses for %s", family, host)
+	}
+
+	if len(fallbacks) == 0 {
+		return dialSerial(ctx, network, primaries, port)
+	}
+
+	return dialParallel(ctx, network, primaries, fallbacks, port)
+}
+
+// partitionIPs splits ips into the preferred family and the fallback family.
+// With the auto family the first address's family is preferred, as resolvers
+// already sort addresses by preference; a forced family has no fallbacks.
+func partitionIPs(ips []net.IP, network, family string) (primaries, fallbacks []net.IP) {
+	switch network {
+	case "tcp4":
+		family = ipFamilyIPv4
+	case "tcp6":
+		family = ipFamilyIPv6
+	}
+
+	for _, ip := range ips {
+		isV4 := ip.To4() != nil
+
+		switch {
+		case family == ipFamilyIPv4 && !isV4, family == ipFamilyIPv6 && isV4:
+			continue
+		case len(primaries) == 0 || (primaries[0].To4() != nil) == isV4:
+			primaries = append(primaries, ip)
+		default:
+			fallbacks = append(fallbacks, ip)
+		}
+	}
+
+	return primaries, fallbacks
+}
+
+// dialSerial tries the addresses in order and returns the first connection.
+func dialSerial(ctx context.Context, network string, ips []net.IP, port string) (net.Conn, error) {
+	var firstErr error
+
+	for _, ip := range ips {
+		conn, err := dialAddr(ctx, network, net.JoinHostPort(ip.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+
+		if firstErr == nil {
+			firstErr = err
+		}
+
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	return nil, firstErr
+}
+
+// dialResult is the outcome of one family's dial attempts.
+type dialResult struct {
+	conn    net.Conn
+	err     error
+	primary bool
+}
+
+// dialParallel starts dialing the primaries, starts the fallbacks after
+// happyEyeballsDelay (or as soon as the primaries fail) and returns the first
+// connection, closing any later one. It returns as soon as ctx is done: the
+// racers then drop their results.
+func dialParallel(ctx context.Context, network string, primaries, fallbacks []net.IP, port string) (net.Conn, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan dialResult)
+	race := func(ips []net.IP, primary bool) {
+		conn, err := dialSerial(ctx, network, ips, port)
+
+		select {
+		case results <- dialResult{conn, err, primary}:
+		case <-ctx.Done():
+			if conn != nil {
+				conn.Close()
+			}
+		}
+	}
+
+	go race(primaries, true)
+
+	timer := time.NewTimer(happyEyeballsDelay)
+	defer timer.Stop()
+
+	var firstErr error
+
+	pending, fallbackStarted := 1, false
+	startFallback := func() {
+		if !fallbackStarted {
+			fallbackStarted = true
+			pending++
+
+			go race(fallbacks, false)
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timer.C:
+			startFallback()
+		case result := <-results:
+			pending--
+
+			if result.err == nil {
+				return result.conn, nil
+			}
+
+			if firstErr == nil || result.primary {
+				firstErr = result.err
+			}
+
+			// Failed primaries don't wait for the timer
+			startFallback()
+
+			if pending == 0 {
+				return nil, firstErr
+			}
+		}
+	}
+}
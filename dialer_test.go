@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestDialParallelCancelled(t *testing.T) {
+	// Every address hangs until the dial is cancelled
+	hungUp := make(chan struct{}, 1)
+
+	previous := dialAddr
+	dialAddr = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		defer func() { hungUp <- struct{}{} }()
+
+		<-ctx.Done()
+
+		return nil, ctx.Err()
+	}
+	t.Cleanup(func() { dialAddr = previous })
+
+	primaries := []net.IP{net.ParseIP("2001:db8::1")}
+	fallbacks := []net.IP{net.ParseIP("192.0.2.1")}
+
+	for i := 0; i < 20; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+
+		done := make(chan error, 1)
+		go func() {
+			_, err := dialParallel(ctx, "tcp", primaries, fallbacks, "443")
+			done <- err
+		}()
+
+		select {
+		case err := <-done:
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("dial %d: err = %v, want context.DeadlineExceeded", i, err)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("dial %d never returned after its context was cancelled", i)
+		}
+
+		// Only the primaries were dialled; wait for that attempt to end
+		<-hungUp
+
+		cancel()
+	}
+}
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	doctorTimeout = 10 * time.Second
+	maxClockSkew  = time.Minute
+)
+
+// checkStatus is the outcome of a single doctor check.
+type checkStatus string
+
+const (
+	checkPass checkStatus = "PASS"
+	checkWarn checkStatus = "WARN"
+	checkFail checkStatus = "FAIL"
+	checkSkip checkStatus = "SKIP"
+)
+
+// doctorReport collects and prints check results.
+type doctorReport struct {
+	failed bool
+}
+
+// add prints one check result.
+func (r *doctorReport) add(status checkStatus, name, detail string) {
+	if status == checkFail {
+		r.failed = true
+	}
+
+	fmt.Printf("[%s] %-18s %s\n", status, name, detail)
+}
+
+// runDoctorCommand handles `doctor`: it diagnoses common environment problems.
+func runDoctorCommand(args []string) error {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	report := &doctorReport{}
+	apiURL, _ := url.Parse(baseURL)
+	host := apiURL.Hostname()
+
+	apiKey, searchEngineID := checkConfig(report)
+	proxied := checkProxy(report, apiURL)
+	reachable := checkNetwork(report, host, proxied)
+
+	if !reachable {
+		report.add(checkSkip, "clock", "network unreachable")
+		report.add(checkSkip, "api key", "network unreachable")
+		report.add(checkSkip, "search engine id", "network unreachable")
+	} else {
+		checkCredentials(report, apiKey, searchEngineID)
+	}
+
+	if report.failed {
+		return fmt.Errorf("one or more checks failed")
+	}
+
+	fmt.Println("All checks passed.")
+
+	return nil
+}
+
+// checkConfig reports where credentials come from and returns them.
+func checkConfig(report *doctorReport) (apiKey, searchEngineID string) {
+	path, err := configFilePath()
+	switch {
+	case err != nil:
+		report.add(checkWarn, "config file", err.Error())
+	case fileExists(path):
+		if err := loadConfigFile(); err != nil {
+			report.add(checkFail, "config file", err.Error())
+		} else {
+			report.add(checkPass, "config file", path)
+		}
+	default:
+		report.add(checkPass, "config file", "not present, using environment only")
+	}
+
+	apiKey = os.Getenv("GOOGLE_API_KEY")
+	searchEngineID = os.Getenv("GOOGLE_SEARCH_ENGINE_ID")
+
+	for _, v := range []struct{ name, value string }{
+		{"GOOGLE_API_KEY", apiKey},
+		{"GOOGLE_SEARCH_ENGINE_ID", searchEngineID},
+	} {
+		if v.value == "" {
+			report.add(checkFail, "env", v.name+" is not set")
+		} else {
+			report.add(checkPass, "env", v.name+" is set")
+		}
+	}
+
+	return apiKey, searchEngineID
+}
+
+// checkProxy reports the proxy, if any, that requests to the API will use.
+func checkProxy(report *doctorReport, apiURL *url.URL) bool {
+	proxy, err := http.ProxyFromEnvironment(&http.Request{URL: apiURL})
+	switch {
+	case err != nil:
+		report.add(checkFail, "proxy", fmt.Sprintf("invalid proxy configuration: %v", err))
+	case proxy != nil:
+		proxy.User = nil
+		report.add(checkPass, "proxy", "requests go through "+proxy.String())
+
+		return true
+	default:
+		report.add(checkPass, "proxy", "none (direct connection)")
+	}
+
+	return false
+}
+
+// checkNetwork verifies DNS resolution and a TLS handshake with host.
+// Behind a proxy, direct failures are only warnings since the proxy resolves and connects.
+func checkNetwork(report *doctorReport, host string, proxied bool) bool {
+	addrs, err := net.LookupHost(host)
+	if err != nil {
+		if proxied {
+			report.add(checkWarn, "dns", fmt.Sprintf("cannot resolve %s locally: %v", host, err))
+
+			return true
+		}
+
+		report.add(checkFail, "dns", fmt.Sprintf("cannot resolve %s: %v", host, err))
+
+		return false
+	}
+
+	report.add(checkPass, "dns", fmt.Sprintf("%s -> %s", host, strings.Join(addrs, ", ")))
+
+	dialer := &net.Dialer{Timeout: doctorTimeout}
+
+	conn, err := tls.DialWithDialer(dialer, "tcp", net.JoinHostPort(host, "443"), &tls.Config{ServerName: host})
+	if err != nil {
+		if proxied {
+			report.add(checkWarn, "tls", fmt.Sprintf("direct connection to %s failed: %v", host, err))
+
+			return true
+		}
+
+		report.add(checkFail, "tls", fmt.Sprintf("connection to %s failed: %v", host, err))
+
+		return false
+	}
+	conn.Close()
+
+	report.add(checkPass, "tls", "handshake with "+host+" succeeded")
+
+	return true
+}
+
+// checkCredentials issues a one-result query and interprets the outcome.
+func checkCredentials(report *doctorReport, apiKey, searchEngineID string) {
+	if apiKey == "" || searchEngineID == "" {
+		report.add(checkSkip, "clock", "credentials missing")
+		report.add(checkSkip, "api key", "credentials missing")
+		report.add(checkSkip, "search engine id", "credentials missing")
+
+		return
+	}
+
+	client := &http.Client{Timeout: doctorTimeout}
+
+	resp, err := client.Get(baseURL + "?" + buildSearchParams(SearchOptions{Query: "test", NumResults: 1}, apiKey, searchEngineID).Encode())
+	if err != nil {
+		// Report the cause only: the request URL contains the API key
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+
+		report.add(checkFail, "api request", err.Error())
+
+		return
+	}
+	defer resp.Body.Close()
+
+	checkClock(report, resp)
+
+	if resp.StatusCode == http.StatusOK {
+		report.add(checkPass, "api key", "accepted")
+		report.add(checkPass, "search engine id", "accepted")
+		checkEngineScope(report, apiKey, searchEngineID)
+
+		return
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	err = newAPIStatusError(resp, body, time.Now())
+
+	var statusErr *apiStatusError
+	errors.As(err, &statusErr)
+
+	message := statusErr.Message
+	if message == "" {
+		message = fmt.Sprintf("status %d", resp.StatusCode)
+	}
+
+	var quotaErr *quotaError
+
+	switch {
+	case errors.Is(err, errInvalidAPIKey) || strings.Contains(message, "API key"):
+		report.add(checkFail, "api key", message)
+		report.add(checkSkip, "search engine id", "api key rejected")
+	case errors.As(err, &quotaErr):
+		report.add(checkFail, "api key", fmt.Sprintf("%s (quota exhausted; resets around %s)", message, quotaErr.ResetAt.Local().Format("2006-01-02 15:04 MST")))
+		report.add(checkSkip, "search engine id", "quota exhausted")
+	case resp.StatusCode == http.StatusForbidden:
+		report.add(checkFail, "api key", message+" (is the Custom Search API enabled?)")
+		report.add(checkSkip, "search engine id", "api key rejected")
+	default:
+		report.add(checkPass, "api key", "accepted")
+		report.add(checkFail, "search engine id", message)
+	}
+}
+
+// checkEngineScope verifies the engine finds results for a query any
+// whole-web engine answers.
+func checkEngineScope(report *doctorReport, apiKey, searchEngineID string) {
+	results, err := performGoogleSearch(context.Background(), SearchOptions{Query: probeQuery, NumResults: 1}, apiKey, searchEngineID)
+
+	switch {
+	case err != nil:
+		report.add(checkSkip, "search scope", err.Error())
+	case len(results) == 0:
+		report.add(checkFail, "search scope", errEngineMisconfigured(catalogs[defaultLocale], searchEngineID).Error())
+	default:
+		report.add(checkPass, "search scope", "engine returns web results")
+	}
+}
+
+// checkClock compares the server's Date header to the local clock.
+func checkClock(report *doctorReport, resp *http.Response) {
+	date, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		report.add(checkSkip, "clock", "server sent no usable Date header")
+
+		return
+	}
+
+	skew := time.Since(date).Round(time.Second)
+	if skew < 0 {
+		skew = -skew
+	}
+
+	if skew > maxClockSkew {
+		report.add(checkWarn, "clock", fmt.Sprintf("local clock is off by %s", skew))
+
+		return
+	}
+
+	report.add(checkPass, "clock", fmt.Sprintf("skew %s", skew))
+}
+
+// fileExists reports whether path exists.
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+
+	return err == nil
+}
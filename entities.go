@@ -0,0 +1,199 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// resultEntities holds named entities detected in a result's title and snippet.
+type resultEntities struct {
+	Organizations []string `json:"organizations,omitempty"`
+	People        []string `json:"people,omitempty"`
+	Dates         []string `json:"dates,omitempty"`
+}
+
+const monthPattern = `(?:Jan(?:uary)?|Feb(?:ruary)?|Mar(?:ch)?|Apr(?:il)?|May|Jun(?:e)?|Jul(?:y)?|Aug(?:ust)?|Sep(?:t(?:ember)?)?|Oct(?:ober)?|Nov(?:ember)?|Dec(?:ember)?)`
+
+var (
+	// datePattern matches ISO dates, "March 5, 2024", "5 March 2024" and "March 2024".
+	datePattern = regexp.MustCompile(`\b(?:\d{4}-\d{2}-\d{2}|` +
+		monthPattern + `\.? \d{1,2}(?:st|nd|rd|th)?,? \d{4}|` +
+		`\d{1,2} ` + monthPattern + `\.? \d{4}|` +
+		monthPattern + `\.? \d{4})\b`)
+
+	// capitalizedRun matches runs of capitalized words, allowing "of"/"and"/"&" inside.
+	capitalizedRun = regexp.MustCompile(`\b[A-Z][\w.'&-]*(?:\s+(?:(?:of|and|for|&)\s+)?[A-Z][\w.'&-]*)*`)
+
+	// acronymPattern matches standalone all-caps acronyms such as NASA or WHO.
+	acronymPattern = regexp.MustCompile(`\b[A-Z]{2,6}\b`)
+)
+
+// orgSuffixes mark a capitalized run as an organization when they appear in it.
+var orgSuffixes = map[string]bool{
+	"inc": true, "inc.": true, "corp": true, "corp.": true, "corporation": true, "llc": true,
+	"ltd": true, "ltd.": true, "gmbh": true, "ag": true, "plc": true, "co.": true, "company": true,
+	"group": true, "foundation": true, "university": true, "institute": true, "association": true,
+	"agency": true, "bank": true, "ministry": true, "department": true, "council": true,
+	"committee": true, "society": true, "labs": true, "technologies": true, "systems": true,
+}
+
+// personTitles introduce a personal name.
+var personTitles = map[string]bool{
+	"mr": true, "mr.": true, "mrs": true, "mrs.": true, "ms": true, "ms.": true,
+	"dr": true, "dr.": true, "prof": true, "prof.": true, "sir": true,
+}
+
+// nonNameWords rule a capitalized run out as a personal name.
+var nonNameWords = map[string]bool{
+	"the": true, "a": true, "an": true, "how": true, "what": true, "why": true, "when": true,
+	"new": true, "best": true, "top": true, "guide": true, "news": true, "official": true,
+	"home": true, "page": true, "about": true, "free": true, "online": true, "review": true,
+	"learn": true, "learning": true, "introduction": true, "tutorial": true, "getting": true,
+	"started": true, "using": true, "with": true, "is": true, "in": true, "on": true, "to": true,
+}
+
+// acronymStopList holds common all-caps tokens that aren't organizations.
+var acronymStopList = map[string]bool{
+	"PDF": true, "HTML": true, "FAQ": true, "USA": true, "UK": true, "EU": true, "AM": true,
+	"PM": true, "OK": true, "TV": true, "CEO": true, "CTO": true, "API": true, "URL": true,
+}
+
+// extractResultEntities runs entity extraction on every result.
+func extractResultEntities(results []GoogleSearchResult) {
+	for i := range results {
+		results[i].Entities = extractEntities(results[i].Title + ". " + results[i].Snippet)
+	}
+}
+
+// extractEntities detects organizations, people and dates in text with
+// lightweight heuristics: date patterns, capitalized word runs with
+// organization suffixes or personal titles, and acronyms.
+func extractEntities(text string) *resultEntities {
+	entities := &resultEntities{}
+	seen := map[string]bool{}
+
+	add := func(list *[]string, value string) {
+		if value != "" && !seen[value] {
+			seen[value] = true
+			*list = append(*list, value)
+		}
+	}
+
+	for _, date := range datePattern.FindAllString(text, -1) {
+		add(&entities.Dates, date)
+	}
+
+	// Remove dates so month names don't start capitalized runs
+	text = datePattern.ReplaceAllString(text, " ")
+
+	for _, words := range capitalizedRuns(text) {
+		run := strings.Join(words, " ")
+
+		switch {
+		case hasOrgSuffix(words):
+			add(&entities.Organizations, strings.TrimRight(run, ".,"))
+		case len(words) >= 2 && personTitles[strings.ToLower(words[0])]:
+			add(&entities.People, strings.Join(words[1:], " "))
+		case looksLikeName(words):
+			add(&entities.People, run)
+		}
+	}
+
+	for _, acronym := range acronymPattern.FindAllString(text, -1) {
+		if !acronymStopList[acronym] && !seenIn(entities.Organizations, acronym) {
+			add(&entities.Organizations, acronym)
+		}
+	}
+
+	if len(entities.Organizations) == 0 && len(entities.People) == 0 && len(entities.Dates) == 0 {
+		return nil
+	}
+
+	return entities
+}
+
+// capitalizedRuns returns the capitalized word runs in text, split where a word
+// ends a sentence (a trailing period that isn't an abbreviation like "Inc.").
+func capitalizedRuns(text string) [][]string {
+	var runs [][]string
+
+	for _, run := range capitalizedRun.FindAllString(text, -1) {
+		var current []string
+
+		for _, word := range strings.Fields(run) {
+			current = append(current, word)
+
+			lower := strings.ToLower(word)
+			if strings.HasSuffix(word, ".") && !orgSuffixes[lower] && !personTitles[lower] {
+				current[len(current)-1] = strings.TrimSuffix(word, ".")
+				runs = append(runs, current)
+				current = nil
+			}
+		}
+
+		if len(current) > 0 {
+			runs = append(runs, current)
+		}
+	}
+
+	return runs
+}
+
+// hasOrgSuffix reports whether any word marks the run as an organization.
+func hasOrgSuffix(words []string) bool {
+	if len(words) < 2 {
+		return false
+	}
+
+	for _, word := range words {
+		if orgSuffixes[strings.ToLower(strings.TrimRight(word, ","))] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// looksLikeName reports whether a capitalized run is plausibly a personal name:
+// two or three words, none of them common title words or acronyms.
+func looksLikeName(words []string) bool {
+	if len(words) < 2 || len(words) > 3 {
+		return false
+	}
+
+	for _, word := range words {
+		if nonNameWords[strings.ToLower(word)] || strings.ToUpper(word) == word || strings.ContainsAny(word, "&0123456789") {
+			return false
+		}
+	}
+
+	return true
+}
+
+// seenIn reports whether value already occurs inside one of the collected names.
+func seenIn(names []string, value string) bool {
+	for _, name := range names {
+		if strings.Contains(name, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// formatEntities appends detected entities to a text result.
+func formatEntities(sb *strings.Builder, entities *resultEntities, msgs messageCatalog) {
+	for _, group := range []struct {
+		label  string
+		values []string
+	}{
+		{msgs.text(msgOrganizations), entities.Organizations},
+		{msgs.text(msgPeople), entities.People},
+		{msgs.text(msgDates), entities.Dates},
+	} {
+		if len(group.values) > 0 {
+			fmt.Fprintf(sb, "   %s: %s\n", group.label, strings.Join(group.values, "; "))
+		}
+	}
+}
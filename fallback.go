@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Zero-result fallback strategies.
+const (
+	fallbackSpelling = "spelling"
+	fallbackQuotes   = "quotes"
+	fallbackSite     = "site"
+	fallbackDate     = "date"
+)
+
+// siteOperator matches site: operators in a query, including negated ones.
+var siteOperator = regexp.MustCompile(`(?i)(?:^|\s)-?site:\S+`)
+
+// fallbackStrategy relaxes a search that returned nothing. It returns the
+// relaxed options, a description of the relaxation, and false if it doesn't apply.
+type fallbackStrategy func(opts SearchOptions, resp *GoogleSearchResponse) (SearchOptions, string, bool)
+
+// fallbackStrategies maps strategy names to implementations.
+var fallbackStrategies = map[string]fallbackStrategy{
+	fallbackSpelling: func(opts SearchOptions, resp *GoogleSearchResponse) (SearchOptions, string, bool) {
+		corrected := resp.suggestion(opts.Query)
+		if corrected == "" {
+			return opts, "", false
+		}
+
+		opts.Query = corrected
+
+		return opts, fmt.Sprintf("spell-corrected to %q", opts.Query), true
+	},
+	fallbackQuotes: func(opts SearchOptions, _ *GoogleSearchResponse) (SearchOptions, string, bool) {
+		if !strings.Contains(opts.Query, `"`) && opts.ExactTerms == "" {
+			return opts, "", false
+		}
+
+		// A required phrase becomes ordinary query terms
+		query := opts.Query + " " + opts.ExactTerms
+		opts.Query = strings.Join(strings.Fields(strings.ReplaceAll(query, `"`, " ")), " ")
+		opts.ExactTerms = ""
+
+		return opts, "dropped quotes", true
+	},
+	fallbackSite: func(opts SearchOptions, _ *GoogleSearchResponse) (SearchOptions, string, bool) {
+		query := strings.TrimSpace(siteOperator.ReplaceAllString(opts.Query, ""))
+		if query == opts.Query && opts.SiteSearch == "" {
+			return opts, "", false
+		}
+
+		// A query consisting only of site: operators can't be relaxed this way
+		if query == "" {
+			return opts, "", false
+		}
+
+		opts.Query = query
+		opts.SiteSearch = ""
+		opts.SiteSearchFilter = ""
+
+		return opts, "removed site filter", true
+	},
+	fallbackDate: func(opts SearchOptions, _ *GoogleSearchResponse) (SearchOptions, string, bool) {
+		if opts.DateRestrict == "" {
+			return opts, "", false
+		}
+
+		opts.DateRestrict = ""
+
+		return opts, "removed date restriction", true
+	},
+}
+
+// searchOutcome is the result of a search, possibly after fallbacks.
+type searchOutcome struct {
+	Results []GoogleSearchResult
+	// Options are the (possibly relaxed) options that produced Results.
+	Options SearchOptions
+	// Relaxations describes the fallbacks applied, in order.
+	Relaxations []string
+	APICalls    int
+	// NextStart is the start of the next page of Results, or 0 if there is none.
+	NextStart int
+	// Information holds the API's statistics about the search behind Results.
+	Information *SearchInformation
+	// Suggestion is the API's spelling correction of the query, if it made
+	// one that wasn't applied.
+	Suggestion string
+	// CorrectedQuery is the spelling correction auto-correct searched for.
+	CorrectedQuery string
+	// Promotions are the search engine's promotions for the query.
+	Promotions []Promotion
+	// Incomplete explains why Results hold fewer pages than asked for.
+	Incomplete string
+}
+
+// loadFallbacks reads the ordered fallback strategies from GOOGLE_SEARCH_FALLBACKS.
+func loadFallbacks() ([]string, error) {
+	var strategies []string
+
+	for _, name := range strings.Split(os.Getenv("GOOGLE_SEARCH_FALLBACKS"), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		if _, ok := fallbackStrategies[name]; !ok {
+			return nil, fmt.Errorf("unknown fallback %q in GOOGLE_SEARCH_FALLBACKS (want %s, %s, %s or %s)",
+				name, fallbackSpelling, fallbackQuotes, fallbackSite, fallbackDate)
+		}
+
+		strategies = append(strategies, name)
+	}
+
+	return strategies, nil
+}
+
+// searchWithFallbacks runs a search and, while it returns no results, applies
+// the given strategies in order. Relaxations accumulate: each fallback builds
+// on the previous one.
+func searchWithFallbacks(ctx context.Context, opts SearchOptions, config *Config, strategies []string) (*searchOutcome, error) {
+	resp, err := searchGoogle(ctx, opts, config.APIKey, config.SearchEngineID)
+	if err != nil {
+		return nil, err
+	}
+
+	outcome := &searchOutcome{Options: opts, APICalls: resp.Pages, Suggestion: resp.suggestion(opts.Query)}
+
+	// Search for the corrected spelling instead, if asked to
+	if opts.AutoCorrect && outcome.Suggestion != "" {
+		corrected := opts
+		corrected.Query = outcome.Suggestion
+
+		next, err := searchGoogle(ctx, corrected, config.APIKey, config.SearchEngineID)
+		if err != nil {
+			return nil, fmt.Errorf("auto-corrected search for %q failed: %w", corrected.Query, err)
+		}
+
+		outcome.APICalls += next.Pages
+		outcome.Options = corrected
+		outcome.CorrectedQuery, outcome.Suggestion = corrected.Query, ""
+		resp = next
+	}
+
+	for _, name := range strategies {
+		if len(resp.Items) > 0 {
+			break
+		}
+
+		relaxed, description, ok := fallbackStrategies[name](outcome.Options, resp)
+		if !ok {
+			continue
+		}
+
+		debugf("no results for %q; retrying with fallback %s", outcome.Options.Query, description)
+
+		next, err := searchGoogle(ctx, relaxed, config.APIKey, config.SearchEngineID)
+		if err != nil {
+			return nil, fmt.Errorf("fallback (%s) failed: %w", description, err)
+		}
+
+		outcome.APICalls += next.Pages
+		outcome.Options = relaxed
+		outcome.Relaxations = append(outcome.Relaxations, description)
+		if name == fallbackSpelling {
+			outcome.Suggestion = ""
+		}
+
+		resp = next
+	}
+
+	outcome.Results = resp.Items
+	outcome.NextStart = resp.nextStart()
+	outcome.Information = resp.Information
+	outcome.Promotions = resp.Promotions
+	outcome.Incomplete = resp.Incomplete
+
+	return outcome, nil
+}
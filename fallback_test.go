@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/habuvo/mcp-internet-search/searchtest"
+)
+
+// TestFallbackErrorKeepsCause lets callers match the cause of a failed
+// fallback search, so a budget refusal isn't mistaken for an outage.
+func TestFallbackErrorKeepsCause(t *testing.T) {
+	api := newFakeSearchAPI()
+	api.Respond(func(params url.Values) []searchtest.Result {
+		if strings.Contains(params.Get("q"), `"`) {
+			return nil
+		}
+
+		return searchtest.Results(params.Get("q"), 5)
+	})
+	newConformanceServer(t, api)
+
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig: %v", err)
+	}
+
+	searchQuota.Store(&quotaTracker{settings: quotaSettings{Daily: 1}, now: time.Now})
+	t.Cleanup(func() { searchQuota.Store(nil) })
+
+	_, err = searchWithFallbacks(context.Background(), SearchOptions{Query: `"golang generics"`, NumResults: 5}, config, []string{fallbackQuotes})
+	if !errors.Is(err, errQuotaExceeded) || isUnavailable(err) {
+		t.Errorf("err = %v, want a quota refusal that isn't an outage", err)
+	}
+}
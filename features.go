@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Feature flags gating optional tools and arguments.
+const (
+	// featureCluster enables the google_search cluster argument.
+	featureCluster = "cluster"
+	// featureEntities enables the google_search extract_entities argument.
+	featureEntities = "entities"
+	// featureSEOTools enables the rank_check, compare_domains and keyword_coverage tools.
+	featureSEOTools = "seo_tools"
+	// featureTranslation enables the google_search translate_results_to
+	// argument, which calls the paid Cloud Translation API.
+	featureTranslation = "translation"
+)
+
+// defaultFeatures lists every known feature and whether it is on when
+// GOOGLE_SEARCH_FEATURES doesn't mention it. Experimental features are off.
+var defaultFeatures = map[string]bool{
+	featureCluster:     false,
+	featureEntities:    false,
+	featureSEOTools:    true,
+	featureTranslation: false,
+}
+
+// featureSet records which features are enabled.
+type featureSet map[string]bool
+
+// enabled reports whether the named feature is on.
+func (f featureSet) enabled(name string) bool {
+	return f[name]
+}
+
+// loadFeatures reads GOOGLE_SEARCH_FEATURES, a comma-separated list of
+// features to enable; a leading "-" disables a feature that is on by default.
+func loadFeatures() (featureSet, error) {
+	features := make(featureSet, len(defaultFeatures))
+	for name, on := range defaultFeatures {
+		features[name] = on
+	}
+
+	for _, name := range strings.Split(os.Getenv("GOOGLE_SEARCH_FEATURES"), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		on := !strings.HasPrefix(name, "-")
+		name = strings.TrimPrefix(name, "-")
+
+		if _, ok := defaultFeatures[name]; !ok {
+			return nil, fmt.Errorf("unknown feature %q in GOOGLE_SEARCH_FEATURES (want one of %s)",
+				name, strings.Join(knownFeatures(), ", "))
+		}
+
+		features[name] = on
+	}
+
+	return features, nil
+}
+
+// knownFeatures returns the feature names in sorted order.
+func knownFeatures() []string {
+	names := make([]string, 0, len(defaultFeatures))
+	for name := range defaultFeatures {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// errFeatureDisabled reports use of an argument whose feature is off.
+func errFeatureDisabled(argument, feature string) error {
+	return fmt.Errorf("%s is not enabled on this server (add %s to GOOGLE_SEARCH_FEATURES)", argument, feature)
+}
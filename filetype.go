@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// supportedFileTypes are the extensions Google indexes and fileType accepts.
+var supportedFileTypes = map[string]bool{
+	"pdf": true, "ps": true, "dwf": true, "kml": true, "kmz": true,
+	"xls": true, "xlsx": true, "ppt": true, "pptx": true, "doc": true, "docx": true,
+	"odp": true, "ods": true, "odt": true, "rtf": true, "svg": true, "swf": true,
+	"tex": true, "txt": true, "wml": true, "wap": true, "xml": true,
+	"bas": true, "c": true, "cc": true, "cpp": true, "cxx": true, "h": true, "hpp": true,
+	"cs": true, "java": true, "pl": true, "py": true,
+}
+
+// extractFileType extracts and validates the file_type parameter.
+func extractFileType(arguments map[string]interface{}) (string, error) {
+	fileType, _ := arguments["file_type"].(string)
+	fileType = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(fileType)), ".")
+
+	if fileType == "" {
+		return "", nil
+	}
+
+	if !supportedFileTypes[fileType] {
+		return "", fmt.Errorf("file_type %q is not supported (want one of %s)", fileType, strings.Join(fileTypeList(), ", "))
+	}
+
+	return fileType, nil
+}
+
+// fileTypeList returns the supported file types in sorted order.
+func fileTypeList() []string {
+	types := make([]string, 0, len(supportedFileTypes))
+	for fileType := range supportedFileTypes {
+		types = append(types, fileType)
+	}
+
+	sort.Strings(types)
+
+	return types
+}
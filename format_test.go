@@ -0,0 +1,187 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files with the current output")
+
+// goldenResponse builds a fixed response exercising every optional field.
+func goldenResponse() searchResponse {
+	results := []GoogleSearchResult{
+		{
+			Title:       "Go 1.22 Release Notes - The Go Programming Language",
+			Link:        "https://go.dev/doc/go1.22",
+			Snippet:     "Go 1.22 was released on 6 February 2024 by Google. The loop variable semantics changed.",
+			DisplayLink: "go.dev",
+			Translation: &ResultTranslation{
+				Language:       "de",
+				SourceLanguage: "en",
+				Title:          "Go 1.22 Versionshinweise - Die Programmiersprache Go",
+				Snippet:        "Go 1.22 wurde am 6. Februar 2024 von Google veröffentlicht. Die Semantik der Schleifenvariablen hat sich geändert.",
+			},
+		},
+		{
+			Title:       "Range over integers in Go — “a small change”",
+			Link:        "https://blog.example.com/go-range",
+			Snippet:     "Russ Cox explains the new range-over-int loops… Café\tnotes.",
+			DisplayLink: "blog.example.com",
+			PageMeta: &PageMeta{
+				Title:       "Range over integers in Go",
+				Description: "Why Go 1.22 lets for loops range over an int, and what it means for existing code.",
+				Published:   "2024-02-06T09:00:00Z",
+				Author:      "Russ Cox",
+			},
+		},
+		{
+			Title:       "Go loop variable changes explained",
+			Link:        "https://www.nytimes.com/tech/go-loops",
+			Snippet:     "The Go team at Google changed loop variable scoping in 2024.",
+			DisplayLink: "www.nytimes.com",
+		},
+		{
+			Title:       "Gardening tips for spring",
+			Link:        "https://garden.example.org/spring",
+			Snippet:     "Plant tomatoes after the last frost; water seedlings daily.",
+			DisplayLink: "garden.example.org",
+			Image: &ResultImage{
+				ContextLink:     "https://garden.example.org/spring",
+				Width:           1600,
+				Height:          1200,
+				ByteSize:        3 << 20,
+				ThumbnailLink:   "https://encrypted-tbn0.gstatic.com/images?q=tbn:tomatoes",
+				ThumbnailWidth:  160,
+				ThumbnailHeight: 120,
+			},
+		},
+	}
+
+	assignResultIDs(results)
+	scoreResults("go 1.22 loop variable", results, 0)
+	results[0].Credibility = tierTrusted
+	results[1].Credibility = tierUnrated
+	results[2].Paywalled = true
+	results[2].Archive = archiveURL(results[2].Link)
+	extractResultEntities(results)
+
+	return searchResponse{
+		Promotions: []Promotion{{
+			Title:       "Go 1.22 release notes",
+			Link:        "https://go.dev/doc/go1.22",
+			DisplayLink: "go.dev",
+			BodyLines:   []PromotionLine{{Title: "Loop variables are now per iteration", Link: "https://go.dev/blog/loopvar-preview"}, {Title: "Released February 2024"}},
+		}},
+		Results:  results,
+		Clusters: clusterResults(results),
+		Metadata: searchMetadata{
+			FormatVersion:   outputFormatVersion,
+			Provider:        providerName,
+			APICalls:        2,
+			ElapsedMS:       123,
+			Filters:         []string{"lr=lang_en"},
+			Relaxations:     []string{"dropped quotes"},
+			SessionID:       "research-1",
+			SessionAPICalls: 7,
+			SearchID:        "s-7f3a",
+			NextStart:       5,
+			TotalResults:    412000,
+			SearchTimeMS:    287,
+		},
+	}
+}
+
+// renderText renders a response the way google_search does for text output.
+func renderText(response searchResponse, msgs messageCatalog) string {
+	text := formatPromotions(response.Promotions, msgs) + formatSearchResults(response.Results, msgs) + formatClusters(response.Clusters, msgs)
+	response.Metadata.EstimatedTokens = estimateTokens(defaultTokenEstimator, text)
+
+	return text + formatMetadata(response.Metadata)
+}
+
+// renderJSON renders a response the way google_search does for JSON output.
+func renderJSON(t *testing.T, response searchResponse) string {
+	t.Helper()
+
+	result, err := formatJSONResult(response, defaultTokenEstimator)
+	if err != nil {
+		t.Fatalf("formatJSONResult: %v", err)
+	}
+
+	return result.Content[0].(mcp.TextContent).Text
+}
+
+// checkGolden compares got with testdata/golden/<name>, rewriting it with -update.
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+
+	path := filepath.Join("testdata", "golden", name)
+
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("failed to update %s: %v", path, err)
+		}
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s (run with -update to create it): %v", path, err)
+	}
+
+	if got != string(want) {
+		t.Errorf("%s mismatch\n--- got ---\n%s\n--- want ---\n%s", name, got, want)
+	}
+}
+
+func TestOutputGolden(t *testing.T) {
+	tests := []struct {
+		name   string
+		render func(t *testing.T) string
+	}{
+		{"text.golden", func(t *testing.T) string {
+			return renderText(goldenResponse(), catalogs[defaultLocale])
+		}},
+		{"text_de.golden", func(t *testing.T) string {
+			return renderText(goldenResponse(), catalogs["de"])
+		}},
+		{"plain.golden", func(t *testing.T) string {
+			return plainText(renderText(goldenResponse(), catalogs[defaultLocale]))
+		}},
+		{"json.golden", func(t *testing.T) string {
+			return renderJSON(t, goldenResponse())
+		}},
+		{"no_results.golden", func(t *testing.T) string {
+			return renderText(searchResponse{Metadata: searchMetadata{
+				FormatVersion: outputFormatVersion,
+				Provider:      providerName,
+				APICalls:      1,
+			}}, catalogs[defaultLocale])
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkGolden(t, tt.name, tt.render(t))
+		})
+	}
+}
+
+func TestOutputDeterministic(t *testing.T) {
+	wantText := renderText(goldenResponse(), catalogs[defaultLocale])
+	wantJSON := renderJSON(t, goldenResponse())
+
+	// Map iteration order differs between runs; repeat to catch dependence on it
+	for i := 0; i < 50; i++ {
+		if got := renderText(goldenResponse(), catalogs[defaultLocale]); got != wantText {
+			t.Fatalf("text output changed on run %d:\n%s\nwant:\n%s", i, got, wantText)
+		}
+
+		if got := renderJSON(t, goldenResponse()); got != wantJSON {
+			t.Fatalf("JSON output changed on run %d:\n%s\nwant:\n%s", i, got, wantJSON)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// searchHedgeDelay is how long a Custom Search API request may run before a
+// duplicate is sent; zero disables hedging. It is set by loadConfig.
+var searchHedgeDelay atomic.Int64
+
+// loadHedgeDelay reads GOOGLE_SEARCH_HEDGE_DELAY, a duration such as "800ms".
+func loadHedgeDelay() (time.Duration, error) {
+	value := os.Getenv("GOOGLE_SEARCH_HEDGE_DELAY")
+	if value == "" {
+		return 0, nil
+	}
+
+	delay, err := time.ParseDuration(value)
+	if err != nil || delay <= 0 {
+		return 0, fmt.Errorf("GOOGLE_SEARCH_HEDGE_DELAY must be a positive duration such as 800ms, got %q", value)
+	}
+
+	return delay, nil
+}
+
+// searchAttempt is the outcome of one request to the API.
+type searchAttempt struct {
+	resp *GoogleSearchResponse
+	err  error
+}
+
+// hedgedSearch requests rawURL and, if no response arrived after the hedge
+// delay, sends one duplicate request, charged to tool's quota like the
+// original; a spent quota means no duplicate. The first success wins and
+// the other request is cancelled; if both fail, the first error is
+// returned. It also returns the number of requests sent.
+func hedgedSearch(ctx context.Context, rawURL, tool string) (*GoogleSearchResponse, int, error) {
+	delay := time.Duration(searchHedgeDelay.Load())
+	if delay <= 0 {
+		resp, err := searchOnce(ctx, rawURL)
+
+		return resp, 1, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	attempts := make(chan searchAttempt, 2)
+	send := func() {
+		resp, err := searchOnce(ctx, rawURL)
+		attempts <- searchAttempt{resp, err}
+	}
+
+	go send()
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	sent, pending := 1, 1
+
+	var firstErr error
+
+	for pending > 0 {
+		select {
+		case <-timer.C:
+			// The duplicate is billed, so it needs quota too
+			if err := searchQuota.Load().take(tool, 1); err != nil {
+				debugf("search API request slower than %v; not sending a duplicate: %v", delay, err)
+
+				continue
+			}
+
+			// The original is slow: race a duplicate against it
+			sent++
+			pending++
+			debugf("search API request slower than %v; sending a duplicate", delay)
+
+			go send()
+		case attempt := <-attempts:
+			pending--
+
+			if attempt.err == nil {
+				return attempt.resp, sent, nil
+			}
+
+			if firstErr == nil {
+				firstErr = attempt.err
+			}
+		}
+	}
+
+	return nil, sent, firstErr
+}
+
+// searchOnce sends a single Custom Search API request.
+func searchOnce(ctx context.Context, rawURL string) (*GoogleSearchResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	return doSearchRequest(req)
+}
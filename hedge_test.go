@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestHedgeChargesQuota charges a hedged duplicate to the quota and counts
+// it as an API call, and doesn't hedge once the quota is spent.
+func TestHedgeChargesQuota(t *testing.T) {
+	api := newFakeSearchAPI()
+
+	// The first request of each search stalls until it is cancelled or
+	// 200ms have passed
+	var requests atomic.Int32
+	c := newConformanceClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1)%2 == 1 {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(200 * time.Millisecond):
+			}
+		}
+
+		api.ServeHTTP(w, r)
+	}))
+
+	searchHedgeDelay.Store(int64(20 * time.Millisecond))
+	t.Cleanup(func() { searchHedgeDelay.Store(0) })
+
+	quota := &quotaTracker{settings: quotaSettings{Daily: 3}, now: time.Now}
+	searchQuota.Store(quota)
+	t.Cleanup(func() { searchQuota.Store(nil) })
+
+	apiCalls := func() int {
+		t.Helper()
+
+		result, err := callTool(t, c, "google_search", map[string]interface{}{"query": "golang", "output_format": outputJSON})
+		if err != nil {
+			t.Fatalf("google_search: %v", err)
+		}
+
+		var response searchResponse
+		if err := json.Unmarshal([]byte(resultText(t, result)), &response); err != nil {
+			t.Fatalf("JSON output doesn't decode: %v", err)
+		}
+
+		return response.Metadata.APICalls
+	}
+
+	if calls := apiCalls(); calls != 2 || quota.usage.Calls != 2 {
+		t.Errorf("hedged search: %d api calls, %d charged; want 2 and 2", calls, quota.usage.Calls)
+	}
+
+	// One call left: the original gets it, the duplicate doesn't
+	if calls := apiCalls(); calls != 1 || quota.usage.Calls != 3 || requests.Load() != 3 {
+		t.Errorf("search with 1 call left: %d api calls, %d charged, %d requests; want 1, 3 and 3", calls, quota.usage.Calls, requests.Load())
+	}
+}
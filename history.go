@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const queryHistoryFileName = "query_history.jsonl"
+
+// jsonlLog is an append-only JSON Lines file. A zero path disables it.
+// It is safe for concurrent use.
+type jsonlLog struct {
+	mu   sync.Mutex
+	path string
+}
+
+// append writes v as one line at the end of the log.
+func (l *jsonlLog) append(v interface{}) error {
+	if l.path == "" {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return json.NewEncoder(f).Encode(v)
+}
+
+// scan calls fn with every line of the log, oldest first.
+func (l *jsonlLog) scan(fn func(line []byte)) error {
+	if l.path == "" {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f, err := os.Open(l.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		fn(scanner.Bytes())
+	}
+
+	return scanner.Err()
+}
+
+// queryRecord is one logged google_search call.
+type queryRecord struct {
+	Time      time.Time `json:"time"`
+	Query     string    `json:"query"`
+	SessionID string    `json:"session_id,omitempty"`
+	Results   int       `json:"results"`
+	// ResultIDs are the stable IDs of the results, in order.
+	ResultIDs []string `json:"result_ids,omitempty"`
+	Filters   []string `json:"filters,omitempty"`
+}
+
+// queryHistory logs searches for analytics. A nil history records nothing.
+type queryHistory struct {
+	jsonlLog
+}
+
+// openQueryHistory returns the query history if GOOGLE_SEARCH_HISTORY enables it, or nil.
+func openQueryHistory() *queryHistory {
+	if enabled, _ := strconv.ParseBool(os.Getenv("GOOGLE_SEARCH_HISTORY")); !enabled {
+		return nil
+	}
+
+	dir, err := cacheDir()
+	if err != nil {
+		log.Printf("Query history disabled: %v", err)
+
+		return nil
+	}
+
+	return &queryHistory{jsonlLog{path: filepath.Join(dir, queryHistoryFileName)}}
+}
+
+// record logs one search, reporting failures without failing the search.
+func (h *queryHistory) record(record queryRecord) {
+	if h == nil {
+		return
+	}
+
+	if err := h.append(record); err != nil {
+		log.Printf("Failed to record query history: %v", err)
+	}
+}
+
+// since returns the records logged at or after t, oldest first.
+func (h *queryHistory) since(t time.Time) ([]queryRecord, error) {
+	var records []queryRecord
+
+	err := h.scan(func(line []byte) {
+		var record queryRecord
+		if err := json.Unmarshal(line, &record); err == nil && !record.Time.Before(t) {
+			records = append(records, record)
+		}
+	})
+
+	return records, err
+}
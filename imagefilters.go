@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Values of the image search filters, as the API's imgSize, imgType,
+// imgColorType and imgDominantColor parameters take them.
+var (
+	imageSizes     = []string{"icon", "small", "medium", "large", "xlarge", "xxlarge", "huge"}
+	imageTypes     = []string{"clipart", "face", "lineart", "stock", "photo", "animated"}
+	imageColors    = []string{"color", "gray", "mono", "trans"}
+	dominantColors = []string{"black", "blue", "brown", "gray", "green", "orange", "pink", "purple", "red", "teal", "white", "yellow"}
+	// imageFileTypes are the image formats fileType accepts in image search.
+	imageFileTypes = []string{"bmp", "gif", "ico", "jpg", "jpeg", "png", "svg", "webp"}
+)
+
+// extractImageFilters extracts and validates the image_size, image_type,
+// color_type, dominant_color and file_type parameters of image search into
+// opts.
+func extractImageFilters(arguments map[string]interface{}, opts *SearchOptions) error {
+	for _, filter := range []struct {
+		name   string
+		values []string
+		field  *string
+	}{
+		{"image_size", imageSizes, &opts.ImageSize},
+		{"image_type", imageTypes, &opts.ImageType},
+		{"color_type", imageColors, &opts.ImageColorType},
+		{"dominant_color", dominantColors, &opts.ImageDominantColor},
+		{"file_type", imageFileTypes, &opts.FileType},
+	} {
+		value, err := extractEnum(arguments, filter.name, filter.values)
+		if err != nil {
+			return err
+		}
+
+		*filter.field = value
+	}
+
+	// Google knows JPEG images by the jpg extension only
+	if opts.FileType == "jpeg" {
+		opts.FileType = "jpg"
+	}
+
+	return nil
+}
+
+// extractEnum extracts a string parameter that must be one of values,
+// ignoring case and a leading dot.
+func extractEnum(arguments map[string]interface{}, name string, values []string) (string, error) {
+	raw, ok := arguments[name]
+	if !ok {
+		return "", nil
+	}
+
+	value, _ := raw.(string)
+	value = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(value)), ".")
+
+	if value == "" {
+		return "", nil
+	}
+
+	if !slices.Contains(values, value) {
+		return "", fmt.Errorf("%s must be one of %s, got %q", name, strings.Join(values, ", "), value)
+	}
+
+	return value, nil
+}
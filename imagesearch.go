@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// searchTypeImage is the searchType value for image search.
+const searchTypeImage = "image"
+
+// registerImageSearchTool creates and registers the google_image_search tool with the server.
+func registerImageSearchTool(s *server.MCPServer, config *Config, store *resultStore) {
+	s.AddTool(createImageSearchTool(), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleImageSearchRequest(ctx, request, config, store)
+	})
+}
+
+// createImageSearchTool creates and configures the google_image_search tool.
+func createImageSearchTool() mcp.Tool {
+	return mcp.NewTool("google_image_search",
+		mcp.WithDescription("Search for images using Google Custom Search, returning each image's URL, the page it appears on, a thumbnail URL and its dimensions"),
+		mcp.WithString("query",
+			mcp.Required(),
+			mcp.Description("The search query"),
+		),
+		mcp.WithNumber("num_results",
+			mcp.Description(fmt.Sprintf("Number of images to return (max %d, default %d); each %d images cost one API call", maxNumResults, defaultNumResults, pageSize)),
+		),
+		mcp.WithNumber("start",
+			mcp.Description(fmt.Sprintf("1-based position of the first image, for paging; pass the next_start of previous results to continue where they ended (default 1; images past %d are not available)", maxSearchDepth)),
+		),
+		mcp.WithString("image_size",
+			mcp.Description("Only return images of this size"),
+			mcp.Enum(imageSizes...),
+		),
+		mcp.WithString("image_type",
+			mcp.Description("Only return images of this kind, e.g. photo or clipart"),
+			mcp.Enum(imageTypes...),
+		),
+		mcp.WithString("color_type",
+			mcp.Description("Only return color, grayscale (gray), black and white (mono) or transparent (trans) images"),
+			mcp.Enum(imageColors...),
+		),
+		mcp.WithString("dominant_color",
+			mcp.Description("Only return images in which this color dominates"),
+			mcp.Enum(dominantColors...),
+		),
+		mcp.WithString("file_type",
+			mcp.Description("Only return images in this format, by extension, e.g. png"),
+			mcp.Enum(imageFileTypes...),
+		),
+		mcp.WithArray("rights",
+			mcp.Description("Only return images under one of these licenses, for reusable material: cc_publicdomain, cc_attribute, cc_sharealike, cc_noncommercial, cc_nonderived"),
+			mcp.Items(map[string]interface{}{"type": "string", "enum": rightsLicenses}),
+		),
+		mcp.WithString("safe",
+			mcp.Description("SafeSearch filtering of adult content: active filters it, off doesn't; defaults to the server setting"),
+			mcp.Enum(safeOff, safeActive),
+		),
+		mcp.WithString("output_format",
+			mcp.Description("text (default), plain (ASCII-only text) or json"),
+			mcp.Enum(outputText, outputPlain, outputJSON),
+		),
+		mcp.WithBoolean("include_thumbnails",
+			mcp.Description("Also return the thumbnails as images, for clients that display them"),
+		),
+	)
+}
+
+// handleImageSearchRequest processes a google_image_search tool request.
+func handleImageSearchRequest(ctx context.Context,
+	request mcp.CallToolRequest,
+	config *Config,
+	store *resultStore,
+) (*mcp.CallToolResult, error) {
+	// Extract and validate query parameter
+	query, ok := request.Params.Arguments["query"].(string)
+	if !ok || query == "" {
+		return nil, fmt.Errorf("query must be a non-empty string")
+	}
+
+	// Extract and validate num_results parameter
+	numResults, err := extractNumResults(request.Params.Arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := SearchOptions{Query: query, NumResults: numResults, SearchType: searchTypeImage, Tool: "google_image_search", AppendTerms: config.AppendTerms}
+
+	// Extract and validate start parameter
+	opts.Start, err = extractStart(request.Params.Arguments, numResults)
+	if err != nil {
+		return nil, err
+	}
+
+	// Extract and validate image_size, image_type, color_type,
+	// dominant_color and file_type parameters
+	if err := extractImageFilters(request.Params.Arguments, &opts); err != nil {
+		return nil, err
+	}
+
+	// Extract and validate rights parameter
+	opts.Rights, err = extractRights(request.Params.Arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	// Extract and validate safe parameter
+	opts.Safe, err = extractSafeSearch(request.Params.Arguments, config.SafeSearch)
+	if err != nil {
+		return nil, err
+	}
+
+	// Extract and validate output_format parameter
+	outputFormat, err := extractOutputFormat(request.Params.Arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	// Call Google Custom Search API
+	start := time.Now()
+
+	resp, err := searchGoogle(ctx, opts, config.APIKey, config.SearchEngineID)
+	if err != nil {
+		return nil, fmt.Errorf("image search failed: %v", err)
+	}
+
+	results := resp.Items
+	meta := searchMetadata{
+		FormatVersion: outputFormatVersion,
+		Provider:      providerName,
+		APICalls:      resp.Pages,
+		ElapsedMS:     time.Since(start).Milliseconds(),
+		Filters:       opts.appliedFilters(),
+		NextStart:     resp.nextStart(),
+		TotalResults:  resp.Information.total(),
+		SearchTimeMS:  resp.Information.duration().Milliseconds(),
+		Incomplete:    resp.Incomplete,
+	}
+
+	// Keep the full results for get_result and open_result
+	if len(results) > 0 {
+		meta.SearchID = store.add(sessionOwner(ctx), query, results)
+	}
+
+	var thumbnails []thumbnail
+	if include, _ := request.Params.Arguments["include_thumbnails"].(bool); include {
+		thumbnails = thumbnailLinks(results)
+	}
+
+	// Keep raw markup and page structure out of the output
+	compactResults(results)
+
+	response := searchResponse{Results: results, Metadata: meta}
+
+	// Format results
+	if outputFormat == outputJSON {
+		result, err := formatJSONResult(response, config.TokenEstimator)
+		if err != nil {
+			return nil, err
+		}
+
+		return attachThumbnails(ctx, result, thumbnails), nil
+	}
+
+	formattedResults := formatImageResults(results)
+	response.Metadata.EstimatedTokens = estimateTokens(config.TokenEstimator, formattedResults)
+	formattedResults += formatMetadata(response.Metadata)
+
+	if outputFormat == outputPlain {
+		formattedResults = plainText(formattedResults)
+	}
+
+	return attachThumbnails(ctx, mcp.NewToolResultText(formattedResults), thumbnails), nil
+}
+
+// formatImageResults formats image search results into a readable string.
+func formatImageResults(results []GoogleSearchResult) string {
+	if len(results) == 0 {
+		return "No images found.\n"
+	}
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "Found %d images:\n\n", len(results))
+
+	for i, result := range results {
+		fmt.Fprintf(&sb, "%d. %s\n", i+1, result.Title)
+		fmt.Fprintf(&sb, "   Image: %s\n", result.Link)
+		fmt.Fprintf(&sb, "   ID: %s\n", result.ID)
+
+		if image := result.Image; image != nil {
+			fmt.Fprintf(&sb, "   Size: %dx%d, %s\n", image.Width, image.Height, formatByteSize(image.ByteSize))
+			fmt.Fprintf(&sb, "   Page: %s\n", image.ContextLink)
+			fmt.Fprintf(&sb, "   Thumbnail: %s (%dx%d)\n", image.ThumbnailLink, image.ThumbnailWidth, image.ThumbnailHeight)
+		}
+
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// formatByteSize formats a size in bytes for people, e.g. "245 KB".
+func formatByteSize(bytes int) string {
+	switch {
+	case bytes >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(bytes)/(1<<20))
+	case bytes >= 1<<10:
+		return fmt.Sprintf("%d KB", bytes>>10)
+	default:
+		return fmt.Sprintf("%d bytes", bytes)
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/habuvo/mcp-internet-search/searchtest"
+)
+
+func TestImageSearch(t *testing.T) {
+	api := newFakeSearchAPI()
+	api.Respond(func(params url.Values) []searchtest.Result {
+		results := searchtest.Results(params.Get("q"), 2)
+		for i := range results {
+			results[i].Link = "https://cdn.example.com/cat.png"
+			results[i].Image = &searchtest.Image{
+				ContextLink:     "https://example.com/cats",
+				Width:           1200,
+				Height:          800,
+				ByteSize:        250 * 1024,
+				ThumbnailLink:   "https://encrypted-tbn0.gstatic.com/images?q=tbn:cat",
+				ThumbnailWidth:  150,
+				ThumbnailHeight: 100,
+			}
+		}
+
+		return results
+	})
+
+	c := newConformanceClient(t, api)
+
+	result, err := callTool(t, c, "google_image_search", map[string]interface{}{"query": "cat", "num_results": float64(2)})
+	if err != nil {
+		t.Fatalf("google_image_search: %v", err)
+	}
+
+	text := resultText(t, result)
+	for _, want := range []string{
+		"Found 2 images",
+		"Image: https://cdn.example.com/cat.png",
+		"Size: 1200x800, 250 KB",
+		"Page: https://example.com/cats",
+		"Thumbnail: https://encrypted-tbn0.gstatic.com/images?q=tbn:cat (150x100)",
+		"filters: searchType=image",
+		"search_id: ",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text output lacks %q:\n%s", want, text)
+		}
+	}
+
+	if requests := api.Requests(); requests[len(requests)-1].Get("searchType") != searchTypeImage {
+		t.Errorf("request = %v, want searchType=image", requests[len(requests)-1])
+	}
+
+	// Large transparent PNG clipart
+	if _, err := callTool(t, c, "google_image_search", map[string]interface{}{
+		"query":      "cat",
+		"image_size": "large",
+		"image_type": "clipart",
+		"color_type": "trans",
+		"file_type":  ".PNG",
+	}); err != nil {
+		t.Fatalf("google_image_search with filters: %v", err)
+	}
+
+	params := api.Requests()[len(api.Requests())-1]
+	for name, want := range map[string]string{"imgSize": "large", "imgType": "clipart", "imgColorType": "trans", "fileType": "png"} {
+		if got := params.Get(name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+
+	if _, err := callTool(t, c, "google_image_search", map[string]interface{}{"query": "cat", "dominant_color": "mauve"}); err == nil ||
+		!strings.Contains(err.Error(), "dominant_color must be one of black, blue") {
+		t.Errorf("bad dominant_color: err = %v", err)
+	}
+
+	result, err = callTool(t, c, "google_image_search", map[string]interface{}{"query": "cat", "output_format": outputJSON})
+	if err != nil {
+		t.Fatalf("google_image_search (json): %v", err)
+	}
+
+	var response searchResponse
+	if err := json.Unmarshal([]byte(resultText(t, result)), &response); err != nil {
+		t.Fatalf("JSON output doesn't decode: %v", err)
+	}
+
+	if image := response.Results[0].Image; image == nil || image.ContextLink != "https://example.com/cats" || image.ThumbnailWidth != 150 {
+		t.Errorf("JSON image = %+v", image)
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// mcpClient describes where an MCP client keeps its server configuration.
+type mcpClient struct {
+	// configPath returns the client's default configuration file.
+	configPath func() (string, error)
+	// serversKey is the top-level key holding the server map.
+	serversKey string
+	// entry builds the server entry for the given executable.
+	entry func(command string) map[string]interface{}
+}
+
+// mcpClients lists the supported MCP clients by name.
+var mcpClients = map[string]mcpClient{
+	"claude": {
+		configPath: func() (string, error) {
+			dir, err := os.UserConfigDir()
+			if err != nil {
+				return "", err
+			}
+
+			return filepath.Join(dir, "Claude", "claude_desktop_config.json"), nil
+		},
+		serversKey: "mcpServers",
+		entry:      stdioEntry,
+	},
+	"cursor": {
+		configPath: func() (string, error) {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return "", err
+			}
+
+			return filepath.Join(home, ".cursor", "mcp.json"), nil
+		},
+		serversKey: "mcpServers",
+		entry:      stdioEntry,
+	},
+	"vscode": {
+		configPath: func() (string, error) {
+			return filepath.Join(".vscode", "mcp.json"), nil
+		},
+		serversKey: "servers",
+		entry: func(command string) map[string]interface{} {
+			entry := stdioEntry(command)
+			entry["type"] = "stdio"
+
+			return entry
+		},
+	},
+}
+
+// stdioEntry builds the common command/args server entry. It has no env
+// block: the server reads its credentials from config.env, and client env
+// entries would override them.
+func stdioEntry(command string) map[string]interface{} {
+	return map[string]interface{}{
+		"command": command,
+		"args":    []string{},
+	}
+}
+
+// runInstallCommand handles `install`: it adds this server to an MCP client's configuration.
+func runInstallCommand(args []string) error {
+	fs := flag.NewFlagSet("install", flag.ContinueOnError)
+	clientName := fs.String("client", "", "MCP client to configure: claude, cursor or vscode")
+	name := fs.String("name", "google-search", "Server name to register in the client")
+	configPath := fs.String("config", "", "Client configuration file (defaults to the client's standard location)")
+	dryRun := fs.Bool("dry-run", false, "Print the resulting configuration instead of writing it")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	client, ok := mcpClients[*clientName]
+	if !ok {
+		return fmt.Errorf("unknown client %q (want claude, cursor or vscode)", *clientName)
+	}
+
+	command, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate executable: %v", err)
+	}
+
+	path := *configPath
+	if path == "" {
+		if path, err = client.configPath(); err != nil {
+			return fmt.Errorf("failed to locate %s configuration: %v", *clientName, err)
+		}
+	}
+
+	config, err := readClientConfig(path)
+	if err != nil {
+		return err
+	}
+
+	servers, _ := config[client.serversKey].(map[string]interface{})
+	if servers == nil {
+		servers = map[string]interface{}{}
+	}
+	servers[*name] = client.entry(command)
+	config[client.serversKey] = servers
+
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode configuration: %v", err)
+	}
+
+	if *dryRun {
+		fmt.Println(string(data))
+
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create configuration directory: %v", err)
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write configuration: %v", err)
+	}
+
+	fmt.Printf("Added %q to %s\n", *name, path)
+
+	if !hasStoredCredentials() {
+		fmt.Printf("No credentials stored yet: run `%s init` to save your API key and search engine ID.\n", serviceName)
+	}
+
+	return nil
+}
+
+// hasStoredCredentials reports whether the config file holds both
+// credentials.
+func hasStoredCredentials() bool {
+	path, err := configFilePath()
+	if err != nil || !fileExists(path) {
+		return false
+	}
+
+	values, err := readConfigFile(path, os.Getenv(profileEnv))
+	if err != nil {
+		return false
+	}
+
+	return values["GOOGLE_API_KEY"] != "" && values["GOOGLE_SEARCH_ENGINE_ID"] != ""
+}
+
+// readClientConfig loads an existing client configuration, or an empty one if the file doesn't exist.
+func readClientConfig(path string) (map[string]interface{}, error) {
+	config := map[string]interface{}{}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read configuration: %v", err)
+	}
+
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+
+	return config, nil
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestInstallWritesNoCredentials keeps credentials out of the client entry,
+// whose env would override the ones init saved.
+func TestInstallWritesNoCredentials(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mcp.json")
+	t.Setenv(configFileEnv, filepath.Join(t.TempDir(), "config.env"))
+
+	if err := runInstallCommand([]string{"--client", "cursor", "--config", path}); err != nil {
+		t.Fatalf("install: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading client config: %v", err)
+	}
+
+	var config struct {
+		MCPServers map[string]map[string]interface{} `json:"mcpServers"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatalf("client config doesn't decode: %v", err)
+	}
+
+	entry, ok := config.MCPServers["google-search"]
+	if !ok || entry["command"] == "" {
+		t.Fatalf("no server entry in %s", data)
+	}
+
+	if env, ok := entry["env"]; ok {
+		t.Errorf("entry has env %v, want none", env)
+	}
+}
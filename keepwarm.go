@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+)
+
+// minKeepWarmInterval keeps the pinger from hammering the API host.
+const minKeepWarmInterval = 5 * time.Second
+
+// loadKeepWarmInterval reads GOOGLE_SEARCH_KEEP_WARM, the interval between
+// keep-warm pings; unset disables them.
+func loadKeepWarmInterval() (time.Duration, error) {
+	value := os.Getenv("GOOGLE_SEARCH_KEEP_WARM")
+	if value == "" {
+		return 0, nil
+	}
+
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval < minKeepWarmInterval {
+		return 0, fmt.Errorf("GOOGLE_SEARCH_KEEP_WARM must be a duration of at least %v such as 30s, got %q", minKeepWarmInterval, value)
+	}
+
+	return interval, nil
+}
+
+// keepWarm pings the API host every interval until ctx is done, so a pooled
+// TLS connection stays open and the first search after a quiet period skips
+// the handshake. Pings are HEAD requests without credentials, which the API
+// rejects without counting them against the quota.
+func keepWarm(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := pingAPI(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("keep-warm ping failed: %v", err)
+			}
+		}
+	}
+}
+
+// pingAPI sends one keep-warm request over searchClient's connection pool.
+func pingAPI(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, defaultSearchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, baseURL, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := searchClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	// Drain the body so the connection returns to the pool
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	return resp.Body.Close()
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// searchLanguages are the language codes the lr parameter accepts, as
+// "lang_" + code.
+var searchLanguages = []string{
+	"ar", "bg", "ca", "cs", "da", "de", "el", "en", "es", "et", "fi", "fr",
+	"hr", "hu", "id", "is", "it", "iw", "ja", "ko", "lt", "lv", "nl", "no",
+	"pl", "pt", "ro", "ru", "sk", "sl", "sr", "sv", "tr", "zh-CN", "zh-TW",
+}
+
+// languageAliases maps current ISO 639-1 codes to the older ones Google uses.
+var languageAliases = map[string]string{
+	"he": "iw",
+	"nb": "no",
+}
+
+// languageRestrict converts a language code such as "de", "lang_de" or
+// "zh-tw" into an lr value.
+func languageRestrict(code string) (string, error) {
+	code = strings.TrimPrefix(strings.TrimSpace(code), "lang_")
+	if alias, ok := languageAliases[strings.ToLower(code)]; ok {
+		code = alias
+	}
+
+	for _, lang := range searchLanguages {
+		if strings.EqualFold(code, lang) {
+			return "lang_" + lang, nil
+		}
+	}
+
+	return "", fmt.Errorf("unsupported language %q (want one of %s)", code, strings.Join(searchLanguages, ", "))
+}
+
+// extractLanguage extracts and validates the language parameter.
+func extractLanguage(arguments map[string]interface{}) (string, error) {
+	language, _ := arguments["language"].(string)
+	if language == "" {
+		return "", nil
+	}
+
+	return languageRestrict(language)
+}
+
+// interfaceLanguages are the hl values Google documents as supported
+// interface languages for Custom Search.
+var interfaceLanguages = []string{
+	"af", "sq", "sm", "ar", "az", "eu", "be", "bn", "bh", "bs", "bg", "ca",
+	"zh-CN", "zh-TW", "hr", "cs", "da", "nl", "en", "eo", "et", "fo", "fi",
+	"fr", "fy", "gl", "ka", "de", "el", "gu", "iw", "hi", "hu", "is", "id",
+	"ia", "ga", "it", "ja", "jw", "kn", "ko", "la", "lv", "lt", "mk", "ms",
+	"ml", "mt", "mr", "ne", "no", "nn", "oc", "fa", "pl", "pt-BR", "pt-PT",
+	"pa", "ro", "ru", "gd", "sr", "si", "sk", "sl", "es", "su", "sw", "sv",
+	"tl", "ta", "te", "th", "ti", "tr", "uk", "ur", "uz", "vi", "cy", "xh",
+	"zu",
+}
+
+// interfaceLanguageAliases maps current codes to the older ones Google uses.
+var interfaceLanguageAliases = map[string]string{
+	"he": "iw",
+	"jv": "jw",
+	"nb": "no",
+	"pt": "pt-PT",
+}
+
+// extractInterfaceLanguage extracts and validates the hl parameter. Regional
+// variants Google doesn't list, such as de-AT, use their base language.
+func extractInterfaceLanguage(arguments map[string]interface{}) (string, error) {
+	hl, _ := arguments["hl"].(string)
+	hl = strings.ReplaceAll(strings.TrimSpace(hl), "_", "-")
+
+	if hl == "" {
+		return "", nil
+	}
+
+	base, _, _ := strings.Cut(hl, "-")
+	for _, code := range []string{hl, base} {
+		if alias, ok := interfaceLanguageAliases[strings.ToLower(code)]; ok {
+			code = alias
+		}
+
+		for _, lang := range interfaceLanguages {
+			if strings.EqualFold(code, lang) {
+				return lang, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("hl %q is not a supported interface language (want one of %s)", hl, strings.Join(interfaceLanguages, ", "))
+}
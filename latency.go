@@ -0,0 +1,83 @@
+package main
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// latencyWindow is the number of recent requests latency percentiles are computed over.
+	latencyWindow = 200
+	// minLatencySamples is how many requests are observed before timeouts adapt.
+	minLatencySamples = 20
+	// defaultSearchTimeout applies until enough latencies have been observed.
+	defaultSearchTimeout = 10 * time.Second
+	// latencyMargin is added to the P99 latency to get the adaptive timeout.
+	latencyMargin = time.Second
+	// minSearchTimeout and maxSearchTimeout bound the adaptive timeout.
+	minSearchTimeout = 2 * time.Second
+	maxSearchTimeout = 30 * time.Second
+)
+
+// providerLatency tracks the latency of Custom Search API requests.
+var providerLatency = newLatencyTracker(latencyWindow)
+
+// latencyTracker keeps a sliding window of request latencies and derives a
+// request timeout from them. It is safe for concurrent use.
+type latencyTracker struct {
+	mu      sync.Mutex
+	samples []time.Duration
+	next    int
+	full    bool
+}
+
+// newLatencyTracker creates a tracker over the last size requests.
+func newLatencyTracker(size int) *latencyTracker {
+	return &latencyTracker{samples: make([]time.Duration, size)}
+}
+
+// observe records the latency of one request. Requests that timed out should
+// be recorded with their timeout so that the window reflects them.
+func (t *latencyTracker) observe(d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.samples[t.next] = d
+	t.next = (t.next + 1) % len(t.samples)
+	t.full = t.full || t.next == 0
+}
+
+// percentile returns the p-th percentile (0-100) of the observed latencies and
+// the number of samples it is based on.
+func (t *latencyTracker) percentile(p float64) (time.Duration, int) {
+	t.mu.Lock()
+	n := t.next
+	if t.full {
+		n = len(t.samples)
+	}
+
+	window := append([]time.Duration(nil), t.samples[:n]...)
+	t.mu.Unlock()
+
+	if n == 0 {
+		return 0, 0
+	}
+
+	sort.Slice(window, func(i, j int) bool { return window[i] < window[j] })
+	rank := int(math.Ceil(p/100*float64(n))) - 1
+
+	return window[max(rank, 0)], n
+}
+
+// timeout returns the timeout for the next request: P99 latency plus a
+// margin, within bounds, or the default while there is too little data.
+func (t *latencyTracker) timeout() time.Duration {
+	p99, n := t.percentile(99)
+	if n < minLatencySamples {
+		return defaultSearchTimeout
+	}
+
+	return min(max(p99+latencyMargin, minSearchTimeout), maxSearchTimeout)
+}
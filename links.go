@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// extractLinksTo extracts and validates the links_to parameter, a URL or
+// domain whose backlinks to search for, as a linkSite value.
+func extractLinksTo(arguments map[string]interface{}) (string, error) {
+	target, _ := arguments["links_to"].(string)
+	target = strings.TrimSpace(target)
+
+	// Accept the link: operator form as well
+	target = strings.TrimPrefix(target, "link:")
+
+	if target == "" {
+		return "", nil
+	}
+
+	if strings.ContainsAny(target, " \t\n") {
+		return "", fmt.Errorf("links_to must be a single URL or domain such as example.com/page, got %q", target)
+	}
+
+	// Google matches linkSite without the scheme
+	site := strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(target, "https://"), "http://"), "/")
+
+	if site == "" || strings.HasPrefix(site, "/") || strings.Contains(site, "://") {
+		return "", fmt.Errorf("links_to must be a URL or domain such as example.com/page, got %q", target)
+	}
+
+	return site, nil
+}
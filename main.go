@@ -3,14 +3,21 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"log"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
+	"os/signal"
 	"strconv"
 	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -18,149 +25,1527 @@ import (
 
 // GoogleSearchResult represents a single search result.
 type GoogleSearchResult struct {
+	// ID is the stable ID of the page; see resultID.
+	ID          string `json:"id,omitempty"`
 	Title       string `json:"title"`
 	Link        string `json:"link"`
 	Snippet     string `json:"snippet"`
 	DisplayLink string `json:"displayLink"`
+	// HTMLTitle, HTMLSnippet and HTMLFormattedURL are the HTML-marked-up
+	// variants, with query terms in <b> tags.
+	HTMLTitle        string `json:"htmlTitle,omitempty"`
+	HTMLSnippet      string `json:"htmlSnippet,omitempty"`
+	FormattedURL     string `json:"formattedUrl,omitempty"`
+	HTMLFormattedURL string `json:"htmlFormattedUrl,omitempty"`
+	// CacheID identifies Google's cached copy of the page.
+	CacheID string `json:"cacheId,omitempty"`
+	// Mime and FileFormat describe non-HTML documents, e.g. application/pdf and "PDF/Adobe Acrobat".
+	Mime       string `json:"mime,omitempty"`
+	FileFormat string `json:"fileFormat,omitempty"`
+	// Labels are the search engine's refinement labels matching the result.
+	Labels []ResultLabel `json:"labels,omitempty"`
+	// Image describes the image of an image search result.
+	Image *ResultImage `json:"image,omitempty"`
+	// Pagemap holds the structured data Google extracted from the page.
+	Pagemap *ResultPagemap `json:"pagemap,omitempty"`
+	// PageMeta is the page's own title, description, date and author,
+	// taken from the pagemap's metatags.
+	PageMeta *PageMeta `json:"page_meta,omitempty"`
+	// Translation is set when translate_results_to was requested and the
+	// result is in another language.
+	Translation *ResultTranslation `json:"translation,omitempty"`
+	// Score is a computed relevance signal in [0, 1]; see scoreResults.
+	Score float64 `json:"score"`
+	// Credibility is the source's reputation tier when credibility lists are configured.
+	Credibility string `json:"credibility,omitempty"`
+	// Paywalled is set when the source is on the paywalled domain list.
+	Paywalled bool `json:"paywalled,omitempty"`
+	// Archive is a Wayback Machine URL for the page when archive links were requested.
+	Archive string `json:"archive,omitempty"`
+	// Entities is set when entity extraction was requested.
+	Entities *resultEntities `json:"entities,omitempty"`
+}
+
+// ResultLabel is a search engine refinement label attached to a result.
+type ResultLabel struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+	// LabelWithOp is the query operator that restricts a search to the label.
+	LabelWithOp string `json:"label_with_op"`
+}
+
+// ResultImage is the image block of an image search result. Sizes are in
+// pixels, ByteSize in bytes.
+type ResultImage struct {
+	ContextLink     string `json:"contextLink"`
+	Height          int    `json:"height"`
+	Width           int    `json:"width"`
+	ByteSize        int    `json:"byteSize"`
+	ThumbnailLink   string `json:"thumbnailLink"`
+	ThumbnailHeight int    `json:"thumbnailHeight"`
+	ThumbnailWidth  int    `json:"thumbnailWidth"`
+}
+
+// ResultPagemap holds the pagemap types whose shape Google controls. Other
+// types come from site markup (schema.org, custom PageMaps), vary from page
+// to page, and are dropped.
+type ResultPagemap struct {
+	Thumbnails []PagemapImage `json:"cse_thumbnail,omitempty"`
+	Images     []PagemapImage `json:"cse_image,omitempty"`
+	// Metatags are the page's <meta> tags by name or property, e.g. og:title.
+	Metatags []map[string]string `json:"metatags,omitempty"`
+}
+
+// PagemapImage is a pagemap image reference. Sizes are decimal strings, as
+// the API sends them, and are often missing.
+type PagemapImage struct {
+	Src    string `json:"src"`
+	Width  string `json:"width,omitempty"`
+	Height string `json:"height,omitempty"`
 }
 
 // GoogleSearchResponse represents the response from Google Custom Search API.
 type GoogleSearchResponse struct {
-	Items []GoogleSearchResult `json:"items"`
+	Items    []GoogleSearchResult `json:"items"`
+	Spelling *SpellingInfo        `json:"spelling,omitempty"`
+	Queries  *SearchQueries       `json:"queries,omitempty"`
+	// Information describes the whole search rather than the returned page.
+	Information *SearchInformation `json:"searchInformation,omitempty"`
+	// Promotions are the search engine's own result blocks for the query.
+	Promotions []Promotion `json:"promotions,omitempty"`
+	// Pages is the number of API requests the response took.
+	Pages int `json:"-"`
+	// Incomplete explains why a paged search returned fewer pages than
+	// asked for: a later page failed, and Items holds the pages before it.
+	Incomplete string `json:"-"`
+}
+
+// Promotion is a result block configured for the query in the search
+// engine's settings, such as a link to a site's own help page.
+type Promotion struct {
+	Title       string `json:"title"`
+	Link        string `json:"link"`
+	DisplayLink string `json:"displayLink"`
+	// BodyLines are the block's lines of text, each optionally a link.
+	BodyLines []PromotionLine `json:"bodyLines,omitempty"`
+}
+
+// PromotionLine is one line of a promotion.
+type PromotionLine struct {
+	Title string `json:"title"`
+	Link  string `json:"link,omitempty"`
+}
+
+// SearchInformation holds the API's statistics about a search.
+type SearchInformation struct {
+	// TotalResults estimates the number of matching pages, as a decimal string.
+	TotalResults string `json:"totalResults"`
+	// SearchTime is the time the search took in Google, in seconds.
+	SearchTime float64 `json:"searchTime"`
+}
+
+// total returns the estimated number of matching pages, or 0 if unknown.
+func (i *SearchInformation) total() int64 {
+	if i == nil {
+		return 0
+	}
+
+	total, _ := strconv.ParseInt(i.TotalResults, 10, 64)
+
+	return total
+}
+
+// duration returns the time the search took in Google, or 0 if unknown.
+func (i *SearchInformation) duration() time.Duration {
+	if i == nil {
+		return 0
+	}
+
+	return time.Duration(i.SearchTime * float64(time.Second))
+}
+
+// SearchQueries describes the API's queries for neighboring result pages.
+type SearchQueries struct {
+	// NextPage is set when there are more results; it holds one query.
+	NextPage []PageQuery `json:"nextPage,omitempty"`
+}
+
+// PageQuery is the query for a page of results.
+type PageQuery struct {
+	StartIndex int `json:"startIndex"`
+}
+
+// nextStart returns the start of the next page of results, or 0 if there
+// is none the API would serve.
+func (r *GoogleSearchResponse) nextStart() int {
+	if r.Queries == nil || len(r.Queries.NextPage) == 0 {
+		return 0
+	}
+
+	if start := r.Queries.NextPage[0].StartIndex; start <= maxSearchDepth {
+		return start
+	}
+
+	return 0
+}
+
+// SpellingInfo holds the API's suggested spelling correction for a query.
+type SpellingInfo struct {
+	CorrectedQuery string `json:"correctedQuery"`
+}
+
+// suggestion returns the API's spelling correction of query, or "" if it
+// suggests none.
+func (r *GoogleSearchResponse) suggestion(query string) string {
+	if r.Spelling == nil || r.Spelling.CorrectedQuery == query {
+		return ""
+	}
+
+	return r.Spelling.CorrectedQuery
+}
+
+// SearchOptions holds the parameters of a single search request.
+type SearchOptions struct {
+	Query      string
+	NumResults int
+	// Start is the 1-based index of the first result to return; 0 means the first page.
+	Start int
+	// SearchType is "image" for image search; empty searches web pages.
+	SearchType string
+	// Tool names the tool the search is for, whose budget it is charged to.
+	// It isn't sent to the API.
+	Tool string
+	// AutoCorrect re-runs the search with the API's spelling correction of
+	// the query, if it suggests one.
+	AutoCorrect bool
+	// ImageSize, ImageType, ImageColorType and ImageDominantColor filter
+	// image search results, as imgSize, imgType, imgColorType and
+	// imgDominantColor values.
+	ImageSize          string
+	ImageType          string
+	ImageColorType     string
+	ImageDominantColor string
+	// Language restricts results to documents in a language, as an lr value (e.g. "lang_de").
+	Language string
+	// Country biases results toward a country's Google index, as a gl value (e.g. "de").
+	Country string
+	// CountryRestrict restricts results to documents from countries, as a cr value (e.g. "countryDE").
+	CountryRestrict string
+	// InterfaceLanguage is the hl value (e.g. "de"), which affects snippets and ranking.
+	InterfaceLanguage string
+	// SiteSearch restricts results to pages from a single site, or excludes it.
+	SiteSearch string
+	// SiteSearchFilter is "i" to include only SiteSearch (the API default) or "e" to exclude it.
+	SiteSearchFilter string
+	// LinkSite restricts results to pages that link to this URL or domain.
+	LinkSite string
+	// ExactTerms is a phrase every result must contain.
+	ExactTerms string
+	// ExcludeTerms are words or a phrase no result may contain.
+	ExcludeTerms string
+	// OrTerms are words of which every result must contain at least one.
+	OrTerms string
+	// AppendTerms are terms added to the query by the API (hq), e.g. a
+	// server-wide "golang", without changing the query itself.
+	AppendTerms string
+	// Safe is the SafeSearch level: off or active; empty leaves the API default.
+	Safe string
+	// Rights restricts results to licenses, as a rights value (e.g. "cc_publicdomain|cc_attribute").
+	Rights string
+	// Sort orders results by date, as a sort value (e.g. "date:d,date:r:20240101:20241231").
+	Sort string
+	// LowRange and HighRange bound numbers in matching pages, inclusively.
+	LowRange  string
+	HighRange string
+	// KeepDuplicates turns off the API's filtering of near-duplicate pages.
+	KeepDuplicates bool
+	// NoChineseConversion turns off matching Simplified and Traditional
+	// Chinese forms of the query against each other.
+	NoChineseConversion bool
+	// FileType restricts results to documents with this extension, e.g. "pdf".
+	FileType string
+	// DateRestrict limits results to a recent period, as a dateRestrict value (e.g. "w2").
+	DateRestrict string
+}
+
+// searchFilter is a result filter as an API parameter name and value.
+type searchFilter struct {
+	name, value string
+}
+
+// filters returns the result filters set on the request, in a stable order.
+func (o SearchOptions) filters() []searchFilter {
+	var filters []searchFilter
+
+	add := func(name, value string) {
+		if value != "" {
+			filters = append(filters, searchFilter{name, value})
+		}
+	}
+
+	add("searchType", o.SearchType)
+	add("imgSize", o.ImageSize)
+	add("imgType", o.ImageType)
+	add("imgColorType", o.ImageColorType)
+	add("imgDominantColor", o.ImageDominantColor)
+	add("lr", o.Language)
+	add("gl", o.Country)
+	add("cr", o.CountryRestrict)
+	add("hl", o.InterfaceLanguage)
+
+	if o.SiteSearch != "" {
+		add("siteSearch", o.SiteSearch)
+		add("siteSearchFilter", o.SiteSearchFilter)
+	}
+
+	add("linkSite", o.LinkSite)
+
+	add("exactTerms", o.ExactTerms)
+	add("excludeTerms", o.ExcludeTerms)
+	add("orTerms", o.OrTerms)
+	add("hq", o.AppendTerms)
+	add("fileType", o.FileType)
+	add("rights", o.Rights)
+	add("sort", o.Sort)
+	add("lowRange", o.LowRange)
+	add("highRange", o.HighRange)
+
+	if o.KeepDuplicates {
+		add("filter", "0")
+	}
+
+	if o.NoChineseConversion {
+		add("c2coff", "1")
+	}
+	add("safe", o.Safe)
+	add("dateRestrict", o.DateRestrict)
+
+	return filters
+}
+
+// appliedFilters lists the result filters set on the request as name=value pairs.
+func (o SearchOptions) appliedFilters() []string {
+	var filters []string
+
+	for _, filter := range o.filters() {
+		filters = append(filters, filter.name+"="+filter.value)
+	}
+
+	return filters
+}
+
+// searchMetadata describes how a tool result was produced.
+type searchMetadata struct {
+	// FormatVersion is the output format contract these results follow.
+	FormatVersion int      `json:"format_version"`
+	Provider      string   `json:"provider"`
+	APICalls      int      `json:"api_calls"`
+	ElapsedMS     int64    `json:"elapsed_ms"`
+	Filters       []string `json:"filters"`
+	// Relaxations lists the zero-result fallbacks applied to get these results.
+	Relaxations []string `json:"relaxations,omitempty"`
+	// EstimatedTokens approximates the size of the result content for budgeting.
+	EstimatedTokens int `json:"estimated_tokens"`
+	// SessionID and SessionAPICalls are set when the caller passed a session_id.
+	SessionID       string `json:"session_id,omitempty"`
+	SessionAPICalls int    `json:"session_api_calls,omitempty"`
+	// SearchID refers to these results in get_result and open_result calls.
+	SearchID string `json:"search_id,omitempty"`
+	// StaleSeconds is set when the API was unreachable and cached results
+	// of this age were served instead.
+	StaleSeconds int64 `json:"stale_seconds,omitempty"`
+	// NextStart is the start argument for the next page of results, if the
+	// API has more.
+	NextStart int `json:"next_start,omitempty"`
+	// DidYouMean is the API's spelling correction of the query, which the
+	// results are not for.
+	DidYouMean string `json:"did_you_mean,omitempty"`
+	// CorrectedQuery is set when auto_correct re-ran the search with the
+	// API's spelling correction; the results are for it.
+	CorrectedQuery string `json:"corrected_query,omitempty"`
+	// TotalResults is the API's estimate of all matching pages, which tells
+	// how broad the query is; SearchTimeMS is the time Google took.
+	TotalResults int64 `json:"total_results,omitempty"`
+	SearchTimeMS int64 `json:"search_time_ms,omitempty"`
+	// Incomplete is set when a later page of the search failed; the
+	// results are those of the pages before it.
+	Incomplete string `json:"incomplete,omitempty"`
+	// TranslationError is set when translate_results_to was requested but
+	// the results couldn't be translated; they are returned untranslated.
+	TranslationError string `json:"translation_error,omitempty"`
 }
 
-// Config holds the application configuration.
+// searchResponse is the structured form of a tool result.
+type searchResponse struct {
+	// Promotions are shown before the results; they aren't ranked results.
+	Promotions []Promotion          `json:"promotions,omitempty"`
+	Results    []GoogleSearchResult `json:"results"`
+	Clusters   []resultCluster      `json:"clusters,omitempty"`
+	Metadata   searchMetadata       `json:"metadata"`
+}
+
+// Options holds the command-line options.
+type Options struct {
+	Transport string
+	Addr      string
+	BaseURL   string
+	Daemon    bool
+	PIDFile   string
+	Container bool
+	PrintK8s  bool
+	REPL      bool
+	// Workers and QueueDepth size the sse transport's message worker pool.
+	Workers    int
+	QueueDepth int
+	// Quiet suppresses the startup banner.
+	Quiet bool
+	// Profile selects a profile of the config file.
+	Profile string
+	// Debug turns on debug logging from the start.
+	Debug bool
+}
+
+// Config holds the application configuration. It is read-only once
+// loadConfig returns, so concurrent tool calls share it without locking; only
+// the single-threaded REPL changes it.
 type Config struct {
 	APIKey         string
 	SearchEngineID string
+	// TokenEstimator names the heuristic used to estimate result token counts.
+	TokenEstimator string
+	// Credibility holds the configured domain reputation lists.
+	Credibility *credibilityLists
+	// PaywalledDomains lists domains whose content is likely behind a paywall.
+	PaywalledDomains map[string]bool
+	// Fallbacks are the zero-result fallback strategies to apply, in order.
+	Fallbacks []string
+	// Features are the enabled optional tools and arguments.
+	Features featureSet
+	// Locale selects the message catalog for human-facing text.
+	Locale string
+	// Snippets are the default snippet length and fragment limits.
+	Snippets snippetOptions
+	// KeepWarm is the interval between keep-warm pings; zero disables them.
+	KeepWarm time.Duration
+	// SafeSearch is the default safe level; empty leaves the API default.
+	SafeSearch string
+	// AppendTerms are terms appended to every search as hq.
+	AppendTerms string
+	// ReportDir is where export_report saves reports; empty disables saving.
+	ReportDir string
+	// Quota limits the API calls made per day.
+	Quota quotaSettings
+	// Telemetry turns on local usage counting.
+	Telemetry bool
+	// TelemetryEndpoint is where daily usage summaries are sent; empty
+	// keeps them local.
+	TelemetryEndpoint string
+}
+
+// version is the server version, overridden at release time via -ldflags "-X main.version=...".
+var version = "1.0.0"
+
+// subcommands maps subcommand names to their entry points.
+var subcommands = map[string]func(args []string) error{
+	"service":  runServiceCommand,
+	"update":   runUpdateCommand,
+	"install":  runInstallCommand,
+	"init":     runInitCommand,
+	"doctor":   runDoctorCommand,
+	"validate": runValidateCommand,
+	"query":    runQueryCommand,
+	"batch":    runBatchCommand,
+}
+
+const (
+	// pageSize is the most results the API returns per request.
+	pageSize = 10
+	// maxNumResults is the most results a search returns, in pages of
+	// pageSize; the API serves no results past maxSearchDepth.
+	maxNumResults     = maxSearchDepth
+	defaultNumResults = 5
+	baseURL           = "https://www.googleapis.com/customsearch/v1"
+	shutdownTimeout   = 10 * time.Second
+	providerName      = "google_cse"
+	outputText        = "text"
+	outputJSON        = "json"
+	outputPlain       = "plain"
+	// outputFormatVersion is bumped whenever the text or JSON layout of tool
+	// results changes incompatibly, so clients can pin expectations.
+	outputFormatVersion = 2
+)
+
+func main() {
+	// Dispatch subcommands
+	if len(os.Args) > 1 {
+		if cmd, ok := subcommands[os.Args[1]]; ok {
+			if err := cmd(os.Args[2:]); err != nil {
+				log.Fatal(err)
+			}
+
+			return
+		}
+	}
+
+	// Parse command-line options
+	opts, err := parseOptions(os.Args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if opts.PrintK8s {
+		fmt.Print(k8sManifest())
+
+		return
+	}
+
+	// Select the config file profile for loadConfig
+	if opts.Profile != "" {
+		os.Setenv(profileEnv, opts.Profile)
+	}
+
+	if opts.REPL {
+		if err := runREPL(os.Stdin, os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+
+		return
+	}
+
+	// Set up daemon/container conveniences before anything else gets logged
+	if opts.Container {
+		setupContainerLogging()
+	}
+
+	if err := runServer(opts); err != nil {
+		log.Fatalf("Server error: %v", err)
+	}
+}
+
+// runServer runs the server until it is interrupted. It returns errors rather
+// than exiting, so the deferred cleanup, such as removing the pidfile,
+// always runs.
+func runServer(opts *Options) error {
+	if opts.Daemon {
+		cleanup, err := setupDaemon(opts)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if opts.Debug {
+		setDebugLogging(true)
+	}
+
+	return run(ctx, opts)
+}
+
+// run loads the configuration and serves MCP requests until ctx is done.
+func run(ctx context.Context, opts *Options) error {
+	// Load configuration
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	// Create MCP server with its tools
+	usage := openTelemetry(config)
+	history := openQueryHistory()
+	s := buildServer(config, history, usage)
+
+	// Report the effective configuration to operators
+	if !opts.Quiet {
+		printBanner(os.Stderr, s, config, opts, history != nil)
+	}
+
+	// Send usage summaries, if the operator opted in
+	if usage != nil {
+		go usage.run(ctx)
+	}
+
+	// Let operators capture verbose logs without a restart
+	toggleDebugOnSignal(ctx)
+
+	// Keep the API connection warm between searches
+	if config.KeepWarm > 0 {
+		go keepWarm(ctx, config.KeepWarm)
+	}
+
+	// Start the server
+	return serve(ctx, s, opts)
+}
+
+// parseOptions parses and validates the command-line options.
+func parseOptions(args []string) (*Options, error) {
+	opts := &Options{}
+
+	fs := flag.NewFlagSet("mcp-internet-search", flag.ContinueOnError)
+	fs.StringVar(&opts.Transport, "transport", "stdio", "Transport to serve MCP over: stdio or sse")
+	fs.StringVar(&opts.Addr, "addr", ":8080", "Listen address for the sse transport")
+	fs.StringVar(&opts.BaseURL, "base-url", "", "Public base URL advertised to sse clients (optional)")
+	fs.BoolVar(&opts.Daemon, "daemon", false, "Run as a long-lived service: sd_notify readiness, pidfile and journald-friendly logs")
+	fs.StringVar(&opts.PIDFile, "pidfile", "", "Write the process ID to this file while running (daemon mode)")
+	fs.BoolVar(&opts.Container, "container", false, "Container mode: sse transport on $PORT, JSON logs to stdout, graceful SIGTERM draining")
+	fs.BoolVar(&opts.PrintK8s, "print-k8s", false, "Print an example Kubernetes Deployment/Service manifest and exit")
+	fs.BoolVar(&opts.REPL, "repl", false, "Read queries from stdin and print results interactively instead of serving MCP")
+	fs.IntVar(&opts.Workers, "workers", defaultWorkers, "Maximum MCP messages processed concurrently by the sse transport")
+	fs.IntVar(&opts.QueueDepth, "queue", defaultQueueDepth, "Maximum MCP messages waiting for a worker before new ones are rejected with 503")
+	fs.BoolVar(&opts.Quiet, "quiet", false, "Don't print the configuration report to stderr on startup")
+	fs.BoolVar(&opts.Debug, "debug", false, "Log search API requests and fallbacks; SIGUSR1 toggles this while running")
+	fs.StringVar(&opts.Profile, "profile", "", "Config file profile to use, e.g. prod (default: $"+profileEnv+")")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if opts.Container {
+		applyContainerDefaults(opts)
+	}
+
+	switch opts.Transport {
+	case "stdio", "sse":
+	default:
+		return nil, fmt.Errorf("unknown transport %q (want stdio or sse)", opts.Transport)
+	}
+
+	if opts.Daemon && opts.Transport != "sse" {
+		return nil, fmt.Errorf("--daemon requires --transport sse")
+	}
+
+	if opts.Workers < 1 || opts.QueueDepth < 0 {
+		return nil, fmt.Errorf("--workers must be at least 1 and --queue at least 0")
+	}
+
+	return opts, nil
+}
+
+// serve runs the MCP server over the configured transport until it is stopped.
+func serve(ctx context.Context, s *server.MCPServer, opts *Options) error {
+	if opts.Transport == "sse" {
+		return serveSSE(ctx, s, opts)
+	}
+
+	return server.ServeStdio(s)
 }
 
-const (
-	maxNumResults     = 10
-	defaultNumResults = 5
-	baseURL           = "https://www.googleapis.com/customsearch/v1"
-)
+// serveSSE serves the MCP server over HTTP/SSE and shuts it down gracefully once ctx is done.
+func serveSSE(ctx context.Context, s *server.MCPServer, opts *Options) error {
+	// Listen first so readiness is only reported once connections are accepted
+	ln, err := net.Listen("tcp", opts.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %v", opts.Addr, err)
+	}
+
+	var ready atomic.Bool
+
+	httpServer := &http.Server{ReadHeaderTimeout: 10 * time.Second}
+	sseServer := server.NewSSEServer(s,
+		server.WithBaseURL(opts.BaseURL),
+		server.WithHTTPServer(httpServer),
+	)
+
+	pool := newWorkerPool("messages", opts.Workers, opts.QueueDepth)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", handleHealthz)
+	mux.HandleFunc("/readyz", readyzHandler(&ready))
+	mux.HandleFunc("/metrics", pool.handleMetrics)
+	mux.Handle("/", pool.wrap(sseServer))
+	httpServer.Handler = mux
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- httpServer.Serve(ln)
+	}()
+
+	log.Printf("Serving MCP over SSE on %s", ln.Addr())
+	ready.Store(true)
+	notifyReady()
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Printf("Shutting down")
+	ready.Store(false)
+	notifyStopping()
+
+	// Give load balancers time to observe /readyz failing before connections are closed
+	if opts.Container {
+		time.Sleep(containerDrainDelay)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	return sseServer.Shutdown(shutdownCtx)
+}
+
+// loadConfig loads and validates the application configuration.
+// Environment variables take precedence over the config file written by `init`.
+// It also opens the tracker that applies the quotas to every API call.
+func loadConfig() (*Config, error) {
+	if err := loadConfigFile(); err != nil {
+		return nil, err
+	}
+
+	// Check for required environment variables
+	apiKey := os.Getenv("GOOGLE_API_KEY")
+	searchEngineID := os.Getenv("GOOGLE_SEARCH_ENGINE_ID")
+
+	if apiKey == "" || searchEngineID == "" {
+		return nil, fmt.Errorf("GOOGLE_API_KEY and GOOGLE_SEARCH_ENGINE_ID environment variables are required (or run `%s init`)", serviceName)
+	}
+
+	tokenEstimator := os.Getenv("GOOGLE_SEARCH_TOKEN_ESTIMATOR")
+	if tokenEstimator == "" {
+		tokenEstimator = defaultTokenEstimator
+	}
+
+	if _, ok := tokenEstimators[tokenEstimator]; !ok {
+		return nil, fmt.Errorf("unknown GOOGLE_SEARCH_TOKEN_ESTIMATOR %q (want chars or words)", tokenEstimator)
+	}
+
+	credibility, err := loadCredibilityLists()
+	if err != nil {
+		return nil, err
+	}
+
+	paywalled, err := loadPaywalledDomains()
+	if err != nil {
+		return nil, err
+	}
+
+	fallbacks, err := loadFallbacks()
+	if err != nil {
+		return nil, err
+	}
+
+	features, err := loadFeatures()
+	if err != nil {
+		return nil, err
+	}
+
+	locale, err := loadLocale()
+	if err != nil {
+		return nil, err
+	}
+
+	snippets, err := loadSnippetOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	safeSearch, err := loadSafeSearch()
+	if err != nil {
+		return nil, err
+	}
+
+	appendTerms, err := loadAppendTerms()
+	if err != nil {
+		return nil, err
+	}
+
+	reportDir, err := loadReportDir()
+	if err != nil {
+		return nil, err
+	}
+
+	telemetryEnabled, telemetryEndpoint, err := loadTelemetry()
+	if err != nil {
+		return nil, err
+	}
+
+	hedgeDelay, err := loadHedgeDelay()
+	if err != nil {
+		return nil, err
+	}
+
+	searchHedgeDelay.Store(int64(hedgeDelay))
+
+	dnsTTL, ipFamily, err := loadDialerSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	searchDialer.configure(dnsTTL, ipFamily)
+
+	keepWarmInterval, err := loadKeepWarmInterval()
+	if err != nil {
+		return nil, err
+	}
+
+	quota, err := loadQuota()
+	if err != nil {
+		return nil, err
+	}
+
+	chaos, err := loadChaos()
+	if err != nil {
+		return nil, err
+	}
+
+	if chaos != nil {
+		log.Printf("WARNING: GOOGLE_SEARCH_CHAOS is set; injecting faults into search API requests")
+	}
+
+	searchChaos.Store(chaos)
+
+	config := &Config{
+		APIKey:            apiKey,
+		SearchEngineID:    searchEngineID,
+		TokenEstimator:    tokenEstimator,
+		Credibility:       credibility,
+		PaywalledDomains:  paywalled,
+		Fallbacks:         fallbacks,
+		Features:          features,
+		Locale:            locale,
+		Snippets:          snippets,
+		KeepWarm:          keepWarmInterval,
+		SafeSearch:        safeSearch,
+		AppendTerms:       appendTerms,
+		ReportDir:         reportDir,
+		Telemetry:         telemetryEnabled,
+		TelemetryEndpoint: telemetryEndpoint,
+		Quota:             quota,
+	}
+
+	// Count API calls against the budgets and spend cap, if any are set, in
+	// the CLI commands as well as the server
+	searchQuota.Store(openQuota(config))
+
+	return config, nil
+}
+
+// createServer creates and configures the MCP server.
+func createServer(options ...server.ServerOption) *server.MCPServer {
+	return server.NewMCPServer(
+		"Google Search MCP Server",
+		version,
+		append([]server.ServerOption{server.WithLogging()}, options...)...,
+	)
+}
+
+// buildServer creates the MCP server and registers every tool the
+// configuration enables. query_analytics needs history, so it is left out
+// when history is nil. usage, if not nil, counts sessions and tool calls.
+func buildServer(config *Config, history *queryHistory, usage *telemetry) *server.MCPServer {
+	s := createServer(usage.serverOptions()...)
+
+	// Create and register the search tools and their follow-up tools
+	results := newResultStore(maxStoredSearches)
+	registerGoogleSearchTool(s, config, history, results)
+	registerImageSearchTool(s, config, results)
+	reading := openBookmarks()
+	registerGetResultTool(s, results, reading)
+	registerOpenResultTool(s, results, reading)
+	registerBookmarkTools(s, results, reading)
+	registerExportReportTool(s, config, results, reading)
+
+	if config.Features.enabled(featureSEOTools) {
+		registerRankCheckTool(s, config)
+		registerCompareDomainsTool(s, config)
+		registerKeywordCoverageTool(s, config)
+	}
+
+	if history != nil {
+		registerQueryAnalyticsTool(s, history)
+	}
+
+	return s
+}
+
+// registerGoogleSearchTool creates and registers the Google Search tool with the server.
+func registerGoogleSearchTool(s *server.MCPServer, config *Config, history *queryHistory, results *resultStore) {
+	// Create Google Search tool
+	googleSearchTool := createGoogleSearchTool(config.Features)
+	sessions := newSessionUsage(maxTrackedSessions)
+	probe := &engineProbe{}
+	degraded := newDegradation()
+
+	// Add Google Search tool handler
+	s.AddTool(googleSearchTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleGoogleSearchRequest(ctx, request, config, sessions, history, probe, degraded, results)
+	})
+}
+
+// createGoogleSearchTool creates and configures the Google Search tool,
+// advertising only the arguments whose features are enabled.
+func createGoogleSearchTool(features featureSet) mcp.Tool {
+	options := []mcp.ToolOption{
+		mcp.WithDescription("Search the web using Google Custom Search"),
+		mcp.WithString("query",
+			mcp.Required(),
+			mcp.Description("The search query"),
+		),
+		mcp.WithNumber("num_results",
+			mcp.Description(fmt.Sprintf("Number of results to return (max %d, default %d); each %d results cost one API call", maxNumResults, defaultNumResults, pageSize)),
+		),
+		mcp.WithNumber("start",
+			mcp.Description(fmt.Sprintf("1-based position of the first result, for paging: 11 returns results 11-20 with num_results 10. Pass the next_start of previous results to continue where they ended (default 1; results past %d are not available)", maxSearchDepth)),
+		),
+		mcp.WithString("site_search",
+			mcp.Description("Restrict results to a single site (e.g. go.dev or go.dev/doc), or exclude it with site_search_filter"),
+		),
+		mcp.WithString("site_search_filter",
+			mcp.Description("Whether site_search includes only that site (include, default) or excludes it (exclude)"),
+			mcp.Enum(siteFilterInclude, siteFilterExclude),
+		),
+		mcp.WithString("links_to",
+			mcp.Description("Only return pages that link to this URL or domain (e.g. example.com/page), to find backlinks, citations or coverage of a page"),
+		),
+		mcp.WithString("exact_terms",
+			mcp.Description("A phrase that every result must contain exactly, without quoting it in the query"),
+		),
+		mcp.WithString("exclude_terms",
+			mcp.Description("Words or a phrase that must not appear in any result, applied by the search engine instead of filtering snippets afterwards"),
+		),
+		mcp.WithString("or_terms",
+			mcp.Description("Space-separated alternatives (e.g. synonyms); every result contains at least one of them. An array of terms is also accepted, where an element of several words is one phrase"),
+		),
+		mcp.WithString("append_terms",
+			mcp.Description("Terms Google appends to the query, e.g. golang to keep an ambiguous query on topic, added after any the server appends"),
+		),
+		mcp.WithString("file_type",
+			mcp.Description("Only return documents of this type, by extension: pdf, doc, docx, xls, xlsx, ppt, pptx, txt, rtf, odt, ..."),
+		),
+		mcp.WithArray("rights",
+			mcp.Description("Only return content under one of these licenses, for reusable material: cc_publicdomain, cc_attribute, cc_sharealike, cc_noncommercial, cc_nonderived"),
+			mcp.Items(map[string]interface{}{"type": "string", "enum": rightsLicenses}),
+		),
+		mcp.WithString("safe",
+			mcp.Description("SafeSearch filtering of adult content: active filters it, off doesn't; defaults to the server setting"),
+			mcp.Enum(safeOff, safeActive),
+		),
+		mcp.WithString("language",
+			mcp.Description("Only return documents written in this language, by code: en, de, fr, es, ja, zh-CN, zh-TW, ..."),
+		),
+		mcp.WithString("gl",
+			mcp.Description("Two-letter country code (e.g. us, de, uk) to bias results toward that country's Google index, for localized prices, news or regulations"),
+		),
+		mcp.WithArray("country_restrict",
+			mcp.Description("Only return documents from these countries, by two-letter code (e.g. de, uk); unlike gl this excludes everything else. A single code is also accepted"),
+			mcp.Items(map[string]interface{}{"type": "string"}),
+		),
+		mcp.WithObject("sort_by",
+			mcp.Description("Order results by publication date instead of relevance, e.g. for news monitoring; optionally only within a date range"),
+			mcp.Properties(map[string]interface{}{
+				"order": map[string]interface{}{
+					"type":        "string",
+					"description": "newest first (default) or oldest first",
+					"enum":        []string{sortNewest, sortOldest},
+				},
+				"from": map[string]interface{}{
+					"type":        "string",
+					"description": "Earliest publication date, YYYY-MM-DD",
+				},
+				"to": map[string]interface{}{
+					"type":        "string",
+					"description": "Latest publication date, YYYY-MM-DD (default today)",
+				},
+			}),
+		),
+		mcp.WithNumber("low_range",
+			mcp.Description("Only return pages mentioning a number of at least this value, e.g. a price or year; combine with high_range for a range"),
+		),
+		mcp.WithNumber("high_range",
+			mcp.Description("Only return pages mentioning a number of at most this value; combine with low_range for a range"),
+		),
+		mcp.WithBoolean("dedupe",
+			mcp.Description("Set to false to turn off Google's duplicate content filter, e.g. to see mirrors or near-identical pages (default true)"),
+		),
+		mcp.WithBoolean("chinese_conversion",
+			mcp.Description("Set to false to stop matching Simplified Chinese queries against Traditional Chinese pages and vice versa (default true)"),
+		),
+		mcp.WithString("date_restrict",
+			mcp.Description("Only return pages from the past period: d (days), w (weeks), m (months) or y (years) followed by a number, e.g. d1, w2, m6"),
+		),
+		mcp.WithString("session_id",
+			mcp.Description("Optional identifier grouping related calls (e.g. one research task); cumulative API usage for the session is reported with each result"),
+		),
+		mcp.WithString("credibility",
+			mcp.Description("How to use the configured source reputation lists: annotate (default), downrank (move questionable sources last) or exclude (drop questionable sources)"),
+			mcp.Enum(credibilityAnnotate, credibilityDownrank, credibilityExclude),
+		),
+		mcp.WithBoolean("exclude_paywalled",
+			mcp.Description("Drop results from sources that are likely paywalled instead of just flagging them"),
+		),
+		mcp.WithBoolean("auto_correct",
+			mcp.Description("If Google suggests a spelling correction of the query, search for the corrected query instead (one more API call) and say so; by default the suggestion is only reported as did_you_mean"),
+		),
+		mcp.WithBoolean("fallback",
+			mcp.Description("Set to false to disable the server's zero-result fallbacks (spelling correction, dropping quotes or site filters) for this call"),
+		),
+		mcp.WithString("output_format",
+			mcp.Description("Result format: text (default), plain (text restricted to printable ASCII, for terminals and legacy systems) or json (structured results with relevance scores and metadata)"),
+			mcp.Enum(outputText, outputPlain, outputJSON),
+		),
+		mcp.WithBoolean("archive_links",
+			mcp.Description("Add a Wayback Machine (web.archive.org) link per result so a stable copy can be cited if the live page changes"),
+		),
+		mcp.WithBoolean("include_thumbnails",
+			mcp.Description("Also return the results' thumbnails as images, for clients that display them; results without a thumbnail are skipped"),
+		),
+		mcp.WithNumber("snippet_length",
+			mcp.Description("Cut each snippet to at most this many characters (0 for no limit); overrides the server default"),
+		),
+		mcp.WithNumber("snippets_per_result",
+			mcp.Description("Keep at most this many snippet fragments (separated by \"...\") per result (0 for no limit); overrides the server default"),
+		),
+		mcp.WithString("hl",
+			mcp.Description("Interface language, e.g. de, fr or zh-TW: Google matches snippets and ranking to it, and the result text is translated where a translation exists (otherwise the server's default is used)"),
+		),
+	}
+
+	if features.enabled(featureCluster) {
+		options = append(options, mcp.WithBoolean("cluster",
+			mcp.Description("Group results into topic clusters labeled by their most distinctive terms"),
+		))
+	}
+
+	if features.enabled(featureEntities) {
+		options = append(options, mcp.WithBoolean("extract_entities",
+			mcp.Description("Detect organizations, people and dates in titles and snippets (heuristic, English-oriented)"),
+		))
+	}
+
+	if features.enabled(featureTranslation) {
+		options = append(options, mcp.WithString("translate_results_to",
+			mcp.Description("Language code (e.g. en, de, zh-TW) to machine-translate titles and snippets into; translations are shown next to the originals of results in other languages"),
+		))
+	}
+
+	return mcp.NewTool("google_search", options...)
+}
+
+// handleGoogleSearchRequest processes a Google Search tool request.
+func handleGoogleSearchRequest(ctx context.Context,
+	request mcp.CallToolRequest,
+	config *Config,
+	sessions *sessionUsage,
+	history *queryHistory,
+	probe *engineProbe,
+	degraded *degradation,
+	store *resultStore,
+) (*mcp.CallToolResult, error) {
+	// Extract and validate query parameter
+	query, ok := request.Params.Arguments["query"].(string)
+	if !ok || query == "" {
+		return nil, fmt.Errorf("query must be a non-empty string")
+	}
+
+	// Extract and validate num_results parameter
+	numResults, err := extractNumResults(request.Params.Arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := SearchOptions{Query: query, NumResults: numResults, Tool: "google_search"}
+
+	// Extract and validate credibility parameter
+	credibilityMode, err := extractCredibilityMode(request.Params.Arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	// Extract and validate start parameter
+	opts.Start, err = extractStart(request.Params.Arguments, numResults)
+	if err != nil {
+		return nil, err
+	}
+
+	// Extract and validate output_format parameter
+	outputFormat, err := extractOutputFormat(request.Params.Arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	// Extract and validate site_search and site_search_filter parameters
+	if err := extractSiteSearch(request.Params.Arguments, &opts); err != nil {
+		return nil, err
+	}
+
+	// Extract and validate exact_terms parameter
+	opts.ExactTerms, err = extractTerms(request.Params.Arguments, "exact_terms")
+	if err != nil {
+		return nil, err
+	}
+
+	// Extract and validate exclude_terms parameter
+	opts.ExcludeTerms, err = extractTerms(request.Params.Arguments, "exclude_terms")
+	if err != nil {
+		return nil, err
+	}
+
+	// Extract and validate or_terms parameter
+	opts.OrTerms, err = extractOrTerms(request.Params.Arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	// Extract and validate append_terms parameter
+	opts.AppendTerms, err = extractAppendTerms(request.Params.Arguments, config.AppendTerms)
+	if err != nil {
+		return nil, err
+	}
+
+	// Extract and validate file_type parameter
+	opts.FileType, err = extractFileType(request.Params.Arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	// Extract and validate rights parameter
+	opts.Rights, err = extractRights(request.Params.Arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	// Extract and validate safe parameter
+	opts.Safe, err = extractSafeSearch(request.Params.Arguments, config.SafeSearch)
+	if err != nil {
+		return nil, err
+	}
+
+	// Extract and validate language parameter
+	opts.Language, err = extractLanguage(request.Params.Arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	// Extract and validate gl parameter
+	opts.Country, err = extractCountry(request.Params.Arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	// Extract and validate country_restrict parameter
+	opts.CountryRestrict, err = extractCountryRestrict(request.Params.Arguments)
+	if err != nil {
+		return nil, err
+	}
 
-func main() {
-	// Load configuration
-	config, err := loadConfig()
+	// Extract and validate sort_by parameter
+	opts.Sort, err = extractSortBy(request.Params.Arguments, time.Now().UTC())
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
-	// Create MCP server
-	s := createServer()
+	// Extract and validate low_range and high_range parameters
+	opts.LowRange, opts.HighRange, err = extractNumericRange(request.Params.Arguments)
+	if err != nil {
+		return nil, err
+	}
 
-	// Create and register Google Search tool
-	registerGoogleSearchTool(s, config)
+	// Extract auto_correct parameter
+	opts.AutoCorrect, _ = request.Params.Arguments["auto_correct"].(bool)
 
-	// Start the server
-	if err := server.ServeStdio(s); err != nil {
-		log.Fatalf("Server error: %v", err)
+	// Extract dedupe parameter
+	if dedupe, ok := request.Params.Arguments["dedupe"].(bool); ok {
+		opts.KeepDuplicates = !dedupe
 	}
-}
 
-// loadConfig loads and validates the application configuration.
-func loadConfig() (*Config, error) {
-	// Check for required environment variables
-	apiKey := os.Getenv("GOOGLE_API_KEY")
-	searchEngineID := os.Getenv("GOOGLE_SEARCH_ENGINE_ID")
+	// Extract chinese_conversion parameter
+	if convert, ok := request.Params.Arguments["chinese_conversion"].(bool); ok {
+		opts.NoChineseConversion = !convert
+	}
 
-	if apiKey == "" || searchEngineID == "" {
-		return nil, fmt.Errorf("GOOGLE_API_KEY and GOOGLE_SEARCH_ENGINE_ID environment variables are required")
+	// Extract and validate links_to parameter
+	opts.LinkSite, err = extractLinksTo(request.Params.Arguments)
+	if err != nil {
+		return nil, err
 	}
 
-	return &Config{
-		APIKey:         apiKey,
-		SearchEngineID: searchEngineID,
-	}, nil
-}
+	// Extract and validate date_restrict parameter
+	opts.DateRestrict, err = extractDateRestrict(request.Params.Arguments)
+	if err != nil {
+		return nil, err
+	}
 
-// createServer creates and configures the MCP server.
-func createServer() *server.MCPServer {
-	return server.NewMCPServer(
-		"Google Search MCP Server",
-		"1.0.0",
-		server.WithLogging(),
-	)
-}
+	// Extract and validate snippet_length and snippets_per_result parameters
+	snippets, err := extractSnippetOptions(request.Params.Arguments, config.Snippets)
+	if err != nil {
+		return nil, err
+	}
 
-// registerGoogleSearchTool creates and registers the Google Search tool with the server.
-func registerGoogleSearchTool(s *server.MCPServer, config *Config) {
-	// Create Google Search tool
-	googleSearchTool := createGoogleSearchTool()
+	// Extract and validate hl parameter, which also selects the language of
+	// human-facing text
+	opts.InterfaceLanguage, err = extractInterfaceLanguage(request.Params.Arguments)
+	if err != nil {
+		return nil, err
+	}
 
-	// Add Google Search tool handler
-	s.AddTool(googleSearchTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		return handleGoogleSearchRequest(ctx, request, config)
-	})
-}
+	msgs := config.messages(opts.InterfaceLanguage)
 
-// createGoogleSearchTool creates and configures the Google Search tool.
-func createGoogleSearchTool() mcp.Tool {
-	return mcp.NewTool("google_search",
-		mcp.WithDescription("Search the web using Google Custom Search"),
-		mcp.WithString("query",
-			mcp.Required(),
-			mcp.Description("The search query"),
-		),
-		mcp.WithNumber("num_results",
-			mcp.Description(fmt.Sprintf("Number of results to return (max %d, default %d)", maxNumResults, defaultNumResults)),
-		),
-	)
-}
+	// Reject arguments whose features are disabled before spending API calls
+	if doCluster, _ := request.Params.Arguments["cluster"].(bool); doCluster && !config.Features.enabled(featureCluster) {
+		return nil, errFeatureDisabled("cluster", featureCluster)
+	}
 
-// handleGoogleSearchRequest processes a Google Search tool request.
-func handleGoogleSearchRequest(_ context.Context,
-	request mcp.CallToolRequest,
-	config *Config,
-) (*mcp.CallToolResult, error) {
-	// Extract and validate query parameter
-	query, ok := request.Params.Arguments["query"].(string)
-	if !ok || query == "" {
-		return nil, fmt.Errorf("query must be a non-empty string")
+	if doExtract, _ := request.Params.Arguments["extract_entities"].(bool); doExtract && !config.Features.enabled(featureEntities) {
+		return nil, errFeatureDisabled("extract_entities", featureEntities)
 	}
 
-	// Extract and validate num_results parameter
-	numResults := extractNumResults(request.Params.Arguments)
+	// Extract and validate translate_results_to parameter
+	translateTo, err := extractTranslateTo(request.Params.Arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	if translateTo != "" && !config.Features.enabled(featureTranslation) {
+		return nil, errFeatureDisabled("translate_results_to", featureTranslation)
+	}
+
+	// Relax zero-result searches unless the caller opted out
+	fallbacks := config.Fallbacks
+	if useFallback, ok := request.Params.Arguments["fallback"].(bool); ok && !useFallback {
+		fallbacks = nil
+	}
 
 	// Call Google Custom Search API
-	results, err := performGoogleSearch(query, numResults, config.APIKey, config.SearchEngineID)
+	start := time.Now()
+
+	// Serve recent cached results, labeled, while the API is unreachable
+	outcome, staleAge, err := degraded.searchOrStale(ctx, opts, config, fallbacks)
 	if err != nil {
 		return nil, fmt.Errorf("search failed: %v", err)
 	}
 
+	results := outcome.Results
+
+	// No results at all may mean the engine only searches a few sites
+	if len(results) == 0 {
+		if err := probe.check(ctx, config, msgs); err != nil {
+			return nil, err
+		}
+	}
+
+	meta := searchMetadata{
+		FormatVersion:  outputFormatVersion,
+		Provider:       providerName,
+		APICalls:       outcome.APICalls,
+		ElapsedMS:      time.Since(start).Milliseconds(),
+		Filters:        outcome.Options.appliedFilters(),
+		Relaxations:    outcome.Relaxations,
+		StaleSeconds:   int64(staleAge / time.Second),
+		NextStart:      outcome.NextStart,
+		DidYouMean:     outcome.Suggestion,
+		CorrectedQuery: outcome.CorrectedQuery,
+		TotalResults:   outcome.Information.total(),
+		SearchTimeMS:   outcome.Information.duration().Milliseconds(),
+		Incomplete:     outcome.Incomplete,
+	}
+
+	// Attribute usage to the caller's session, if any
+	if sessionID, _ := request.Params.Arguments["session_id"].(string); sessionID != "" {
+		meta.SessionID = sessionID
+		meta.SessionAPICalls = sessions.add(sessionID, meta.APICalls)
+	}
+
+	history.record(queryRecord{
+		Time:      time.Now().UTC(),
+		Query:     query,
+		SessionID: meta.SessionID,
+		Results:   len(results),
+		ResultIDs: resultIDs(results),
+		Filters:   meta.Filters,
+	})
+
+	// Annotate, down-rank or drop results by source reputation
+	results = config.Credibility.apply(results, credibilityMode)
+
+	// Flag likely paywalled results, dropping them if asked to
+	excludePaywalled, _ := request.Params.Arguments["exclude_paywalled"].(bool)
+	results = markPaywalled(results, config.PaywalledDomains, excludePaywalled)
+
+	response := searchResponse{Promotions: outcome.Promotions, Results: results, Metadata: meta}
+
+	// Optionally group results by topic
+	if doCluster, _ := request.Params.Arguments["cluster"].(bool); doCluster {
+		response.Clusters = clusterResults(results)
+	}
+
+	// Optionally extract named entities
+	if doExtract, _ := request.Params.Arguments["extract_entities"].(bool); doExtract {
+		extractResultEntities(results)
+	}
+
+	// Optionally link archived copies
+	if doArchive, _ := request.Params.Arguments["archive_links"].(bool); doArchive {
+		addArchiveLinks(results)
+	}
+
+	// Keep the full results for get_result and open_result
+	if len(results) > 0 {
+		response.Metadata.SearchID = store.add(sessionOwner(ctx), query, results)
+	}
+
+	// Shorten snippets last so clustering and entities see the full text
+	trimSnippets(results, snippets)
+
+	// Translate the shortened snippets, keeping the results if that fails
+	if translateTo != "" && len(results) > 0 {
+		if err := translateResults(ctx, config.APIKey, translateTo, results); err != nil {
+			response.Metadata.TranslationError = err.Error()
+		}
+	}
+
+	// Note thumbnails before the pagemap holding them is dropped
+	var thumbnails []thumbnail
+	if include, _ := request.Params.Arguments["include_thumbnails"].(bool); include {
+		thumbnails = thumbnailLinks(results)
+	}
+
+	// Keep the page's metadata before the pagemap holding it is dropped
+	addPageMeta(results)
+
+	// Keep raw markup and page structure out of the output
+	compactResults(results)
+
 	// Format results
-	formattedResults := formatSearchResults(results)
+	if outputFormat == outputJSON {
+		result, err := formatJSONResult(response, config.TokenEstimator)
+		if err != nil {
+			return nil, err
+		}
+
+		return attachThumbnails(ctx, result, thumbnails), nil
+	}
+
+	formattedResults := formatPromotions(response.Promotions, msgs) + formatSearchResults(response.Results, msgs) + formatClusters(response.Clusters, msgs)
+
+	// Make a file type restriction visible up front
+	if fileType := outcome.Options.FileType; fileType != "" {
+		formattedResults = msgs.text(msgFileTypeHeader, strings.ToUpper(fileType)) + "\n" + formattedResults
+	}
 
-	return mcp.NewToolResultText(formattedResults), nil
+	// Point out a likely misspelling, or the correction the results are for
+	switch {
+	case meta.CorrectedQuery != "":
+		formattedResults = msgs.text(msgAutoCorrected, meta.CorrectedQuery, query) + "\n\n" + formattedResults
+	case meta.DidYouMean != "":
+		formattedResults = msgs.text(msgDidYouMean, meta.DidYouMean) + "\n\n" + formattedResults
+	}
+
+	response.Metadata.EstimatedTokens = estimateTokens(config.TokenEstimator, formattedResults)
+	formattedResults += formatMetadata(response.Metadata)
+
+	if outputFormat == outputPlain {
+		formattedResults = plainText(formattedResults)
+	}
+
+	return attachThumbnails(ctx, mcp.NewToolResultText(formattedResults), thumbnails), nil
+}
+
+// compactResults clears the result fields that repeat others with HTML
+// markup or carry raw page structure. They stay on the model for code that
+// uses it, but would multiply the tool output's token cost.
+func compactResults(results []GoogleSearchResult) {
+	for i := range results {
+		results[i].HTMLTitle = ""
+		results[i].HTMLSnippet = ""
+		results[i].FormattedURL = ""
+		results[i].HTMLFormattedURL = ""
+		results[i].Pagemap = nil
+	}
 }
 
 // extractNumResults extracts and validates the num_results parameter.
-func extractNumResults(arguments map[string]interface{}) int {
-	numResults := defaultNumResults
-
-	if numResultsArg, ok := arguments["num_results"]; ok {
-		if numResultsFloat, ok := numResultsArg.(float64); ok {
-			numResults = int(numResultsFloat)
-			if numResults < 1 || numResults > maxNumResults {
-				numResults = maxNumResults
+// Each page of up to pageSize results is a billed API call, so values out of
+// range are rejected rather than clamped.
+func extractNumResults(arguments map[string]interface{}) (int, error) {
+	raw, ok := arguments["num_results"]
+	if !ok {
+		return defaultNumResults, nil
+	}
+
+	numResults, ok := raw.(float64)
+	if !ok || numResults != float64(int(numResults)) || numResults < 1 || numResults > maxNumResults {
+		return 0, fmt.Errorf("num_results must be an integer from 1 to %d", maxNumResults)
+	}
+
+	return int(numResults), nil
+}
+
+// extractStart extracts and validates the start parameter, the 1-based
+// position of the first result. The API serves no results past
+// maxSearchDepth, so start plus numResults can't reach beyond it.
+func extractStart(arguments map[string]interface{}, numResults int) (int, error) {
+	raw, ok := arguments["start"]
+	if !ok {
+		return 0, nil
+	}
+
+	start, ok := raw.(float64)
+	if !ok || start != float64(int(start)) || start < 1 {
+		return 0, fmt.Errorf("start must be a positive integer")
+	}
+
+	if last := int(start) + numResults - 1; last > maxSearchDepth {
+		return 0, fmt.Errorf("start %d with num_results %d reaches result %d, but the API returns at most the first %d results", int(start), numResults, last, maxSearchDepth)
+	}
+
+	return int(start), nil
+}
+
+// extractOutputFormat extracts and validates the output_format parameter.
+func extractOutputFormat(arguments map[string]interface{}) (string, error) {
+	format, _ := arguments["output_format"].(string)
+
+	switch format {
+	case "":
+		return outputText, nil
+	case outputText, outputPlain, outputJSON:
+		return format, nil
+	default:
+		return "", fmt.Errorf("output_format must be %q, %q or %q", outputText, outputPlain, outputJSON)
+	}
+}
+
+// performGoogleSearch calls the Google Custom Search API and returns the results.
+func performGoogleSearch(ctx context.Context, opts SearchOptions, apiKey, searchEngineID string) ([]GoogleSearchResult, error) {
+	searchResponse, err := searchGoogle(ctx, opts, apiKey, searchEngineID)
+	if err != nil {
+		return nil, err
+	}
+
+	if searchResponse.Incomplete != "" {
+		log.Printf("Warning: returning %d results only: %s", len(searchResponse.Items), searchResponse.Incomplete)
+	}
+
+	return searchResponse.Items, nil
+}
+
+// searchGoogle calls the Google Custom Search API and returns the full
+// parsed response. The API returns at most pageSize results per request, so
+// larger searches are requested page by page and merged, dropping results
+// repeated on a later page. When a later page fails, the pages already
+// fetched, and paid for, are returned with the failure in Incomplete. No
+// further pages are requested once ctx is done.
+func searchGoogle(ctx context.Context, opts SearchOptions, apiKey, searchEngineID string) (*GoogleSearchResponse, error) {
+	if opts.NumResults <= pageSize {
+		return searchGooglePage(ctx, opts, apiKey, searchEngineID)
+	}
+
+	merged := &GoogleSearchResponse{}
+	seen := make(map[string]bool)
+	first := max(opts.Start, 1)
+
+	for offset := 0; offset < opts.NumResults; offset += pageSize {
+		page := opts
+		page.Start = first + offset
+		page.NumResults = min(pageSize, opts.NumResults-offset)
+
+		resp, err := searchGooglePage(ctx, page, apiKey, searchEngineID)
+		if err != nil && merged.Pages == 0 {
+			return nil, err
+		}
+
+		if err != nil {
+			merged.Incomplete = fmt.Sprintf("results from %d on failed: %v", page.Start, err)
+
+			break
+		}
+
+		merged.Pages += resp.Pages
+		merged.Queries = resp.Queries
+		if merged.Spelling == nil {
+			merged.Spelling = resp.Spelling
+		}
+
+		if merged.Information == nil {
+			merged.Information = resp.Information
+		}
+
+		if merged.Promotions == nil {
+			merged.Promotions = resp.Promotions
+		}
+
+		for _, item := range resp.Items {
+			if !seen[item.ID] {
+				seen[item.ID] = true
+				merged.Items = append(merged.Items, item)
 			}
 		}
+
+		// A short page is the last one there is
+		if len(resp.Items) < page.NumResults {
+			break
+		}
 	}
 
-	return numResults
+	return merged, nil
 }
 
-// performGoogleSearch calls the Google Custom Search API and returns the results.
-func performGoogleSearch(query string, numResults int, apiKey, searchEngineID string) ([]GoogleSearchResult, error) {
+// searchGooglePage requests one page of at most pageSize results. It
+// gives up, without spending quota, once ctx is done.
+func searchGooglePage(ctx context.Context, opts SearchOptions, apiKey, searchEngineID string) (*GoogleSearchResponse, error) {
 	// Build the request parameters
-	params := buildSearchParams(query, numResults, apiKey, searchEngineID)
+	params := buildSearchParams(opts, apiKey, searchEngineID)
+
+	// Don't pay for a request the caller no longer waits for
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Stay within the share of the daily quota available now
+	if err := searchQuota.Load().take(opts.Tool, 1); err != nil {
+		return nil, err
+	}
+
+	// Time out adaptively, based on recent latencies
+	timeout := providerLatency.timeout()
+	reqCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// Make the HTTP request, hedged if configured
+	start := time.Now()
+
+	searchResponse, requests, err := hedgedSearch(reqCtx, baseURL+"?"+params.Encode(), opts.Tool)
+	if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+		providerLatency.observe(timeout)
+		debugf("search API request %s timed out after %v", redactParams(params), timeout)
+
+		return nil, fmt.Errorf("HTTP request timed out after %v: %w", timeout.Round(time.Millisecond), err)
+	}
+
+	if err != nil {
+		debugf("search API request %s failed after %v: %v", redactParams(params), time.Since(start), err)
 
-	// Make the HTTP request
-	resp, err := http.Get(baseURL + "?" + params.Encode())
+		return nil, err
+	}
+
+	providerLatency.observe(time.Since(start))
+	debugf("search API request %s returned %d results in %v", redactParams(params), len(searchResponse.Items), time.Since(start))
+
+	searchResponse.Pages = requests
+	assignResultIDs(searchResponse.Items)
+	scoreResults(opts.Query, searchResponse.Items, max(opts.Start-1, 0))
+
+	return searchResponse, nil
+}
+
+// doSearchRequest sends a Custom Search API request and parses the response.
+func doSearchRequest(req *http.Request) (*GoogleSearchResponse, error) {
+	resp, err := searchClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("HTTP request failed: %v", err)
+		// Report the cause only: the request URL contains the API key
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -168,54 +1553,220 @@ func performGoogleSearch(query string, numResults int, apiKey, searchEngineID st
 }
 
 // buildSearchParams creates the URL parameters for the Google Search API request.
-func buildSearchParams(query string, numResults int, apiKey, searchEngineID string) url.Values {
+func buildSearchParams(opts SearchOptions, apiKey, searchEngineID string) url.Values {
 	params := url.Values{}
 	params.Add("key", apiKey)
 	params.Add("cx", searchEngineID)
-	params.Add("q", query)
-	params.Add("num", strconv.Itoa(numResults))
+	params.Add("q", opts.Query)
+	params.Add("num", strconv.Itoa(opts.NumResults))
+
+	if opts.Start > 1 {
+		params.Add("start", strconv.Itoa(opts.Start))
+	}
+
+	for _, filter := range opts.filters() {
+		params.Add(filter.name, filter.value)
+	}
 
 	return params
 }
 
+// redactParams encodes request parameters for logging, without the API key.
+func redactParams(params url.Values) string {
+	redacted := url.Values{}
+	for name, values := range params {
+		if name != "key" {
+			redacted[name] = values
+		}
+	}
+
+	return redacted.Encode()
+}
+
 // parseSearchResponse processes the HTTP response from the Google Search API.
-func parseSearchResponse(resp *http.Response) ([]GoogleSearchResult, error) {
+func parseSearchResponse(resp *http.Response) (*GoogleSearchResponse, error) {
 	// Check for HTTP errors
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 
-		return nil, fmt.Errorf("API returned non-200 status: %d - %s", resp.StatusCode, string(body))
+		return nil, newAPIStatusError(resp, body, time.Now())
 	}
 
 	// Parse the response
-	var searchResponse GoogleSearchResponse
-	if err := json.NewDecoder(resp.Body).Decode(&searchResponse); err != nil {
-		return nil, fmt.Errorf("failed to parse API response: %v", err)
+	searchResponse, err := decodeSearchResponse(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse API response: %w", err)
 	}
 
-	return searchResponse.Items, nil
+	return searchResponse, nil
 }
 
 // formatSearchResults formats the search results into a readable string.
-func formatSearchResults(results []GoogleSearchResult) string {
+func formatSearchResults(results []GoogleSearchResult, msgs messageCatalog) string {
 	if len(results) == 0 {
-		return "No results found."
+		return msgs.text(msgNoResults)
 	}
 
-	var sb *strings.Builder
+	var sb strings.Builder
 
-	fmt.Fprintf(sb, "Found %d results:\n\n", len(results))
+	sb.WriteString(msgs.text(msgFoundResults, len(results)) + "\n\n")
 
 	for i, result := range results {
-		formatSingleResult(sb, i, result)
+		formatSingleResult(&sb, i, result, msgs)
+	}
+
+	return sb.String()
+}
+
+// formatPromotions formats the search engine's promotions, marked as such,
+// to go before the results.
+func formatPromotions(promotions []Promotion, msgs messageCatalog) string {
+	if len(promotions) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString(msgs.text(msgPromotions) + "\n\n")
+
+	for _, promotion := range promotions {
+		fmt.Fprintf(&sb, "* %s\n", promotion.Title)
+		fmt.Fprintf(&sb, "  %s: %s\n", msgs.text(msgURL), promotion.Link)
+
+		for _, line := range promotion.BodyLines {
+			if line.Link != "" {
+				fmt.Fprintf(&sb, "  %s (%s)\n", line.Title, line.Link)
+			} else {
+				fmt.Fprintf(&sb, "  %s\n", line.Title)
+			}
+		}
+
+		sb.WriteString("\n")
 	}
 
 	return sb.String()
 }
 
 // formatSingleResult formats a single search result and appends it to the string builder.
-func formatSingleResult(sb *strings.Builder, index int, result GoogleSearchResult) {
+func formatSingleResult(sb *strings.Builder, index int, result GoogleSearchResult, msgs messageCatalog) {
 	fmt.Fprintf(sb, "%d. %s\n", index+1, result.Title)
-	fmt.Fprintf(sb, "   URL: %s\n", result.Link)
-	fmt.Fprintf(sb, "   %s\n\n", result.Snippet)
+	fmt.Fprintf(sb, "   %s: %s\n", msgs.text(msgURL), result.Link)
+
+	if result.ID != "" {
+		fmt.Fprintf(sb, "   %s: %s\n", msgs.text(msgResultID), result.ID)
+	}
+
+	if result.Archive != "" {
+		fmt.Fprintf(sb, "   %s: %s\n", msgs.text(msgArchive), result.Archive)
+	}
+
+	if image := result.Image; image != nil {
+		fmt.Fprintf(sb, "   %s: %dx%d, %s\n", msgs.text(msgImage), image.Width, image.Height, formatByteSize(image.ByteSize))
+		fmt.Fprintf(sb, "   %s: %s\n", msgs.text(msgImagePage), image.ContextLink)
+		fmt.Fprintf(sb, "   %s: %s (%dx%d)\n", msgs.text(msgThumbnail), image.ThumbnailLink, image.ThumbnailWidth, image.ThumbnailHeight)
+	}
+
+	if result.Credibility != "" {
+		fmt.Fprintf(sb, "   %s: %s\n", msgs.text(msgSource), result.Credibility)
+	}
+
+	if result.Paywalled {
+		fmt.Fprintf(sb, "   %s\n", msgs.text(msgPaywallLikely))
+	}
+
+	if meta := result.PageMeta; meta != nil {
+		if meta.Published != "" {
+			fmt.Fprintf(sb, "   %s: %s\n", msgs.text(msgPublished), meta.Published)
+		}
+
+		if meta.Author != "" {
+			fmt.Fprintf(sb, "   %s: %s\n", msgs.text(msgAuthor), meta.Author)
+		}
+	}
+	fmt.Fprintf(sb, "   %s\n", result.Snippet)
+
+	if meta := result.PageMeta; meta != nil && addsToSnippet(meta.Description, result.Snippet) {
+		fmt.Fprintf(sb, "   %s: %s\n", msgs.text(msgPageDescription), meta.Description)
+	}
+
+	if t := result.Translation; t != nil {
+		fmt.Fprintf(sb, "   %s: %s\n", msgs.text(msgTranslation, t.SourceLanguage, t.Language), t.Title)
+		fmt.Fprintf(sb, "     %s\n", t.Snippet)
+	}
+
+	if result.Entities != nil {
+		formatEntities(sb, result.Entities, msgs)
+	}
+
+	sb.WriteString("\n")
+}
+
+// formatMetadata formats the metadata footer appended to every tool result.
+func formatMetadata(meta searchMetadata) string {
+	filters := "none"
+	if len(meta.Filters) > 0 {
+		filters = strings.Join(meta.Filters, ", ")
+	}
+
+	footer := fmt.Sprintf("\n---\nformat: v%d | provider: %s | api_calls: %d | elapsed: %dms | filters: %s | ~tokens: %d",
+		meta.FormatVersion, meta.Provider, meta.APICalls, meta.ElapsedMS, filters, meta.EstimatedTokens)
+
+	if meta.TotalResults > 0 {
+		footer += fmt.Sprintf(" | total_results: about %d", meta.TotalResults)
+	}
+
+	if meta.SearchTimeMS > 0 {
+		footer += fmt.Sprintf(" | search_time: %dms", meta.SearchTimeMS)
+	}
+
+	if len(meta.Relaxations) > 0 {
+		footer += " | fallbacks: " + strings.Join(meta.Relaxations, ", then ")
+	}
+
+	if meta.SessionID != "" {
+		footer += fmt.Sprintf(" | session: %s (%d api calls total)", meta.SessionID, meta.SessionAPICalls)
+	}
+
+	if meta.SearchID != "" {
+		footer += " | search_id: " + meta.SearchID
+	}
+
+	if meta.NextStart > 0 {
+		footer += fmt.Sprintf(" | next_start: %d", meta.NextStart)
+	}
+
+	if meta.Incomplete != "" {
+		footer += " | incomplete: " + meta.Incomplete
+	}
+
+	if meta.TranslationError != "" {
+		footer += " | translation_failed: " + meta.TranslationError
+	}
+
+	if meta.StaleSeconds > 0 {
+		footer += fmt.Sprintf(" | degraded: search API unreachable, cached results from %v ago", time.Duration(meta.StaleSeconds)*time.Second)
+	}
+
+	return footer + "\n"
+}
+
+// formatJSONResult renders a search response as a JSON tool result.
+func formatJSONResult(response searchResponse, tokenEstimator string) (*mcp.CallToolResult, error) {
+	if response.Results == nil {
+		response.Results = []GoogleSearchResult{}
+	}
+
+	body, err := json.Marshal(response.Results)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode results: %v", err)
+	}
+
+	response.Metadata.EstimatedTokens = estimateTokens(tokenEstimator, string(body))
+
+	data, err := json.MarshalIndent(response, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode results: %v", err)
+	}
+
+	return mcp.NewToolResultText(string(data)), nil
 }
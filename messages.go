@@ -0,0 +1,230 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// defaultLocale is used when GOOGLE_SEARCH_LOCALE is unset.
+const defaultLocale = "en"
+
+// Message keys for human-facing output.
+const (
+	msgNoResults           = "no_results"
+	msgFoundResults        = "found_results"
+	msgFileTypeHeader      = "file_type_header"
+	msgDidYouMean          = "did_you_mean"
+	msgAutoCorrected       = "auto_corrected"
+	msgPromotions          = "promotions"
+	msgURL                 = "url"
+	msgResultID            = "result_id"
+	msgArchive             = "archive"
+	msgImage               = "image"
+	msgImagePage           = "image_page"
+	msgThumbnail           = "thumbnail"
+	msgSource              = "source"
+	msgPaywallLikely       = "paywall_likely"
+	msgPublished           = "published"
+	msgAuthor              = "author"
+	msgPageDescription     = "page_description"
+	msgTranslation         = "translation"
+	msgTopics              = "topics"
+	msgUntitled            = "untitled"
+	msgClusterResults      = "cluster_results"
+	msgOrganizations       = "organizations"
+	msgPeople              = "people"
+	msgDates               = "dates"
+	msgEngineMisconfigured = "engine_misconfigured"
+)
+
+// messageCatalog maps message keys to format strings in one language.
+type messageCatalog map[string]string
+
+// catalogs holds the message catalogs by language code. English is complete;
+// other catalogs fall back to it for missing keys.
+var catalogs = map[string]messageCatalog{
+	"en": {
+		msgNoResults:       "No results found.",
+		msgFoundResults:    "Found %d results:",
+		msgFileTypeHeader:  "File type: %s",
+		msgDidYouMean:      "Did you mean: %s",
+		msgAutoCorrected:   "Showing results for %s instead of %s",
+		msgPromotions:      "Promoted by the search engine:",
+		msgURL:             "URL",
+		msgResultID:        "ID",
+		msgArchive:         "Archive",
+		msgImage:           "Image",
+		msgImagePage:       "Page",
+		msgThumbnail:       "Thumbnail",
+		msgSource:          "Source",
+		msgPaywallLikely:   "Paywall: likely",
+		msgPublished:       "Published",
+		msgAuthor:          "Author",
+		msgPageDescription: "Page description",
+		msgTranslation:     "Translation (%s → %s)",
+		msgTopics:          "Topics:",
+		msgUntitled:        "(untitled)",
+		msgClusterResults:  "results %s",
+		msgOrganizations:   "Organizations",
+		msgPeople:          "People",
+		msgDates:           "Dates",
+		msgEngineMisconfigured: "the search engine (cx=%s) returned no results even for the query %q. " +
+			"It is most likely restricted to specific sites: open https://programmablesearchengine.google.com/, " +
+			"select the engine and turn on \"Search the entire web\". " +
+			"Also check that GOOGLE_SEARCH_ENGINE_ID is the engine's Search engine ID",
+	},
+	"de": {
+		msgNoResults:       "Keine Ergebnisse gefunden.",
+		msgFoundResults:    "%d Ergebnisse gefunden:",
+		msgFileTypeHeader:  "Dateityp: %s",
+		msgDidYouMean:      "Meinten Sie: %s",
+		msgAutoCorrected:   "Ergebnisse für %s statt %s",
+		msgPromotions:      "Von der Suchmaschine hervorgehoben:",
+		msgURL:             "URL",
+		msgArchive:         "Archiv",
+		msgImage:           "Bild",
+		msgImagePage:       "Seite",
+		msgThumbnail:       "Vorschaubild",
+		msgSource:          "Quelle",
+		msgPaywallLikely:   "Paywall: wahrscheinlich",
+		msgPublished:       "Veröffentlicht",
+		msgAuthor:          "Autor",
+		msgPageDescription: "Seitenbeschreibung",
+		msgTranslation:     "Übersetzung (%s → %s)",
+		msgTopics:          "Themen:",
+		msgUntitled:        "(ohne Titel)",
+		msgClusterResults:  "Ergebnisse %s",
+		msgOrganizations:   "Organisationen",
+		msgPeople:          "Personen",
+		msgDates:           "Daten",
+		msgEngineMisconfigured: "die Suchmaschine (cx=%s) hat selbst für die Anfrage %q keine Ergebnisse geliefert. " +
+			"Vermutlich ist sie auf bestimmte Websites beschränkt: Öffnen Sie https://programmablesearchengine.google.com/, " +
+			"wählen Sie die Suchmaschine aus und aktivieren Sie \"Im gesamten Web suchen\". " +
+			"Prüfen Sie außerdem, ob GOOGLE_SEARCH_ENGINE_ID die Suchmaschinen-ID enthält",
+	},
+	"es": {
+		msgNoResults:       "No se encontraron resultados.",
+		msgFoundResults:    "Se encontraron %d resultados:",
+		msgFileTypeHeader:  "Tipo de archivo: %s",
+		msgDidYouMean:      "Quizás quisiste decir: %s",
+		msgAutoCorrected:   "Mostrando resultados de %s en lugar de %s",
+		msgPromotions:      "Destacado por el motor de búsqueda:",
+		msgURL:             "URL",
+		msgArchive:         "Archivo",
+		msgImage:           "Imagen",
+		msgImagePage:       "Página",
+		msgThumbnail:       "Miniatura",
+		msgSource:          "Fuente",
+		msgPaywallLikely:   "Muro de pago: probable",
+		msgPublished:       "Publicado",
+		msgAuthor:          "Autor",
+		msgPageDescription: "Descripción de la página",
+		msgTranslation:     "Traducción (%s → %s)",
+		msgTopics:          "Temas:",
+		msgUntitled:        "(sin título)",
+		msgClusterResults:  "resultados %s",
+		msgOrganizations:   "Organizaciones",
+		msgPeople:          "Personas",
+		msgDates:           "Fechas",
+		msgEngineMisconfigured: "el motor de búsqueda (cx=%s) no devolvió resultados ni siquiera para la consulta %q. " +
+			"Probablemente está limitado a sitios concretos: abra https://programmablesearchengine.google.com/, " +
+			"seleccione el motor y active \"Buscar en toda la Web\". " +
+			"Compruebe también que GOOGLE_SEARCH_ENGINE_ID contiene el ID del motor de búsqueda",
+	},
+	"fr": {
+		msgNoResults:       "Aucun résultat trouvé.",
+		msgFoundResults:    "%d résultats trouvés :",
+		msgFileTypeHeader:  "Type de fichier : %s",
+		msgDidYouMean:      "Essayez avec cette orthographe : %s",
+		msgAutoCorrected:   "Résultats pour %s au lieu de %s",
+		msgPromotions:      "Mis en avant par le moteur de recherche :",
+		msgURL:             "URL",
+		msgArchive:         "Archive",
+		msgImage:           "Image",
+		msgImagePage:       "Page",
+		msgThumbnail:       "Miniature",
+		msgSource:          "Source",
+		msgPaywallLikely:   "Paywall : probable",
+		msgPublished:       "Date de publication",
+		msgAuthor:          "Auteur",
+		msgPageDescription: "Description de la page",
+		msgTranslation:     "Traduction (%s → %s)",
+		msgTopics:          "Thèmes :",
+		msgUntitled:        "(sans titre)",
+		msgClusterResults:  "résultats %s",
+		msgOrganizations:   "Organisations",
+		msgPeople:          "Personnes",
+		msgDates:           "Dates",
+		msgEngineMisconfigured: "le moteur de recherche (cx=%s) n'a renvoyé aucun résultat, même pour la requête %q. " +
+			"Il est probablement limité à certains sites : ouvrez https://programmablesearchengine.google.com/, " +
+			"sélectionnez le moteur et activez « Rechercher sur l'ensemble du Web ». " +
+			"Vérifiez aussi que GOOGLE_SEARCH_ENGINE_ID contient l'ID du moteur de recherche",
+	},
+}
+
+// text formats the message for key, falling back to English.
+func (c messageCatalog) text(key string, args ...interface{}) string {
+	format, ok := c[key]
+	if !ok {
+		format = catalogs[defaultLocale][key]
+	}
+
+	return fmt.Sprintf(format, args...)
+}
+
+// catalogFor returns the catalog for a language code such as "de" or
+// "pt-BR", and false if there is none.
+func catalogFor(lang string) (messageCatalog, bool) {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if catalog, ok := catalogs[lang]; ok {
+		return catalog, true
+	}
+
+	// Fall back from a regional variant to its base language
+	base, _, _ := strings.Cut(strings.ReplaceAll(lang, "_", "-"), "-")
+	catalog, ok := catalogs[base]
+
+	return catalog, ok
+}
+
+// loadLocale reads the message language from GOOGLE_SEARCH_LOCALE.
+func loadLocale() (string, error) {
+	locale := os.Getenv("GOOGLE_SEARCH_LOCALE")
+	if locale == "" {
+		return defaultLocale, nil
+	}
+
+	if _, ok := catalogFor(locale); !ok {
+		return "", fmt.Errorf("unsupported GOOGLE_SEARCH_LOCALE %q (want one of %s)", locale, strings.Join(knownLocales(), ", "))
+	}
+
+	return locale, nil
+}
+
+// knownLocales returns the languages with a message catalog, sorted.
+func knownLocales() []string {
+	locales := make([]string, 0, len(catalogs))
+	for locale := range catalogs {
+		locales = append(locales, locale)
+	}
+
+	sort.Strings(locales)
+
+	return locales
+}
+
+// messages returns the catalog for the configured locale, or for hl when
+// it names a language with a catalog.
+func (c *Config) messages(hl string) messageCatalog {
+	if catalog, ok := catalogFor(hl); ok {
+		return catalog
+	}
+
+	if catalog, ok := catalogFor(c.Locale); ok {
+		return catalog
+	}
+
+	return catalogs[defaultLocale]
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	// probeQuery is a query any engine that searches the whole web answers.
+	probeQuery = "google"
+	// probeInterval is how long a probe verdict is trusted before re-checking.
+	probeInterval = 10 * time.Minute
+)
+
+// errEngineMisconfigured explains the most common reason for getting no results at all.
+func errEngineMisconfigured(msgs messageCatalog, searchEngineID string) error {
+	return errors.New(msgs.text(msgEngineMisconfigured, searchEngineID, probeQuery))
+}
+
+// engineProbe checks whether zero results mean a misconfigured engine rather
+// than a genuinely unmatched query. It is safe for concurrent use.
+type engineProbe struct {
+	mu        sync.Mutex
+	checkedAt time.Time
+	healthy   bool
+	// running is closed when the probe in flight ends; nil when none is.
+	running chan struct{}
+}
+
+// check returns an error with setup guidance if the engine can't find even a
+// known-good query. Verdicts are cached for probeInterval.
+func (p *engineProbe) check(ctx context.Context, config *Config, msgs messageCatalog) error {
+	healthy, ok := p.verdict(ctx, config)
+
+	// Can't tell; don't block the original (empty) answer on the probe
+	if !ok || healthy {
+		return nil
+	}
+
+	return errEngineMisconfigured(msgs, config.SearchEngineID)
+}
+
+// verdict returns whether the engine answers probeQuery, probing it when the
+// cached verdict is stale. Concurrent callers share one probe, and the lock
+// isn't held while it runs. ok is false if the probe failed or ctx ended
+// before it did.
+func (p *engineProbe) verdict(ctx context.Context, config *Config) (healthy, ok bool) {
+	p.mu.Lock()
+
+	if time.Since(p.checkedAt) <= probeInterval {
+		defer p.mu.Unlock()
+
+		return p.healthy, true
+	}
+
+	// Wait for the probe another call started
+	if running := p.running; running != nil {
+		p.mu.Unlock()
+
+		select {
+		case <-running:
+		case <-ctx.Done():
+			return false, false
+		}
+
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		return p.healthy, time.Since(p.checkedAt) <= probeInterval
+	}
+
+	running := make(chan struct{})
+	p.running = running
+	p.mu.Unlock()
+
+	results, err := performGoogleSearch(ctx, SearchOptions{Query: probeQuery, NumResults: 1}, config.APIKey, config.SearchEngineID)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.running = nil
+	close(running)
+
+	if err != nil {
+		return false, false
+	}
+
+	p.checkedAt = time.Now()
+	p.healthy = len(results) > 0
+
+	return p.healthy, true
+}
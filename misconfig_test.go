@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/habuvo/mcp-internet-search/searchtest"
+)
+
+// TestEngineProbeSharesOneRequest runs one probe for concurrent checks and
+// lets a waiting check give up without waiting for it.
+func TestEngineProbeSharesOneRequest(t *testing.T) {
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+
+	api := newFakeSearchAPI()
+	api.Respond(func(params url.Values) []searchtest.Result { return nil })
+	newConformanceServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+		api.ServeHTTP(w, r)
+	}))
+
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig: %v", err)
+	}
+
+	probe := &engineProbe{}
+	msgs := catalogs[defaultLocale]
+
+	var wg sync.WaitGroup
+
+	errs := make(chan error, 4)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		errs <- probe.check(context.Background(), config, msgs)
+	}()
+
+	<-started
+
+	// A check whose call is cancelled doesn't wait for the probe
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := probe.check(ctx, config, msgs); err != nil {
+		t.Errorf("cancelled check: %v, want no verdict", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- probe.check(context.Background(), config, msgs)
+		}()
+	}
+
+	close(release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err == nil {
+			t.Error("check passed, want the engine reported as misconfigured")
+		}
+	}
+
+	if n := len(api.Requests()); n != 1 {
+		t.Errorf("API requests = %d, want one probe", n)
+	}
+}
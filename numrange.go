@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// extractNumericRange extracts and validates the low_range and high_range
+// parameters, which bound numbers in matching pages (prices, years, ...)
+// inclusively. Either bound may be omitted.
+func extractNumericRange(arguments map[string]interface{}) (low, high string, err error) {
+	lowValue, low, err := extractRangeBound(arguments, "low_range")
+	if err != nil {
+		return "", "", err
+	}
+
+	highValue, high, err := extractRangeBound(arguments, "high_range")
+	if err != nil {
+		return "", "", err
+	}
+
+	if low != "" && high != "" && lowValue > highValue {
+		return "", "", fmt.Errorf("low_range (%s) must not be greater than high_range (%s)", low, high)
+	}
+
+	return low, high, nil
+}
+
+// extractRangeBound extracts one range bound, given as a number or a numeric
+// string, returning its value and its API form.
+func extractRangeBound(arguments map[string]interface{}, name string) (float64, string, error) {
+	var value float64
+
+	switch raw := arguments[name].(type) {
+	case nil:
+		return 0, "", nil
+	case float64:
+		value = raw
+	case string:
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil {
+			return 0, "", fmt.Errorf("%s must be a number, got %q", name, raw)
+		}
+
+		value = parsed
+	default:
+		return 0, "", fmt.Errorf("%s must be a number", name)
+	}
+
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, "", fmt.Errorf("%s must be a finite number", name)
+	}
+
+	return value, strconv.FormatFloat(value, 'f', -1, 64), nil
+}
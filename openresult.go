@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	defaultPageChars = 8000
+	maxPageChars     = 50000
+	// maxPageBytes bounds how much of a page open_result downloads.
+	maxPageBytes = 2 << 20
+	pageTimeout  = 20 * time.Second
+)
+
+// pageClient fetches result pages for open_result. Pages come from other
+// hosts than the API, so it doesn't share searchClient's pooled dialer.
+var pageClient = &http.Client{Timeout: pageTimeout}
+
+var (
+	// pageTitle matches the document title.
+	pageTitle = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	// pageInvisible matches comments and elements whose content isn't text.
+	pageInvisible = regexp.MustCompile(`(?is)<!--.*?-->|<(script|style|noscript|svg|template|head)\b.*?</(script|style|noscript|svg|template|head)>`)
+	// pageBreak matches tags that end a line of text.
+	pageBreak = regexp.MustCompile(`(?i)<(br|/p|/div|/li|/tr|/h[1-6]|/section|/article|/blockquote|/pre)\b[^>]*>`)
+	// pageTag matches any other tag.
+	pageTag = regexp.MustCompile(`(?s)<[^>]*>`)
+)
+
+// registerOpenResultTool creates and registers the open_result tool with the server.
+func registerOpenResultTool(s *server.MCPServer, store *resultStore, list *bookmarks) {
+	s.AddTool(createOpenResultTool(), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleOpenResultRequest(ctx, request, store, list)
+	})
+}
+
+// createOpenResultTool creates and configures the open_result tool.
+func createOpenResultTool() mcp.Tool {
+	options := append([]mcp.ToolOption{
+		mcp.WithDescription("Fetch the page of one result of an earlier search and return its readable text"),
+	}, storedResultOptions()...)
+
+	options = append(options,
+		mcp.WithNumber("max_chars",
+			mcp.Description(fmt.Sprintf("Maximum characters of page text to return (max %d, default %d)", maxPageChars, defaultPageChars)),
+		),
+	)
+
+	return mcp.NewTool("open_result", options...)
+}
+
+// handleOpenResultRequest processes an open_result tool request.
+func handleOpenResultRequest(ctx context.Context,
+	request mcp.CallToolRequest,
+	store *resultStore,
+	list *bookmarks,
+) (*mcp.CallToolResult, error) {
+	stored, err := extractStoredResult(ctx, request.Params.Arguments, store, list)
+	if err != nil {
+		return nil, err
+	}
+
+	// Extract and validate max_chars parameter
+	maxChars := defaultPageChars
+	if maxFloat, ok := request.Params.Arguments["max_chars"].(float64); ok {
+		maxChars = int(maxFloat)
+		if maxChars < 1 || maxChars > maxPageChars {
+			return nil, fmt.Errorf("max_chars must be between 1 and %d", maxPageChars)
+		}
+	}
+
+	link := stored.Result.Link
+
+	title, text, err := fetchPageText(ctx, link)
+	if err != nil {
+		if archive := archiveURL(link); archive != "" {
+			return nil, fmt.Errorf("failed to open %s: %v; an archived copy may be at %s", link, err, archive)
+		}
+
+		return nil, fmt.Errorf("failed to open %s: %v", link, err)
+	}
+
+	if title == "" {
+		title = stored.Result.Title
+	}
+
+	if runes := []rune(text); len(runes) > maxChars {
+		text = string(runes[:maxChars]) + " [...]"
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("%s\n%s\n\n%s\n", title, link, text)), nil
+}
+
+// fetchPageText downloads the page at link and returns its title and text.
+// Only HTML and plain text pages are supported.
+func fetchPageText(ctx context.Context, link string) (title, text string, err error) {
+	if archiveURL(link) == "" {
+		return "", "", fmt.Errorf("not a web page")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create request: %v", err)
+	}
+
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,text/plain;q=0.9")
+
+	resp, err := pageClient.Do(req)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("the site returned %s", resp.Status)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "" && mediaType != "text/html" && mediaType != "application/xhtml+xml" && mediaType != "text/plain" {
+		return "", "", fmt.Errorf("can't extract text from %s documents", mediaType)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxPageBytes))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read page: %v", err)
+	}
+
+	page := strings.ToValidUTF8(string(body), "�")
+	if mediaType == "text/plain" {
+		return "", collapseBlankLines(page), nil
+	}
+
+	title, text = htmlToText(page)
+
+	return title, text, nil
+}
+
+// htmlToText extracts the title and readable text of an HTML page. It is a
+// heuristic for feeding pages to a model, not a full HTML parser.
+func htmlToText(page string) (title, text string) {
+	if match := pageTitle.FindStringSubmatch(page); match != nil {
+		title = strings.Join(strings.Fields(html.UnescapeString(match[1])), " ")
+	}
+
+	page = pageInvisible.ReplaceAllString(page, "")
+	page = pageBreak.ReplaceAllString(page, "\n")
+	page = pageTag.ReplaceAllString(page, " ")
+
+	return title, collapseBlankLines(html.UnescapeString(page))
+}
+
+// collapseBlankLines normalizes spaces within lines and drops empty lines.
+func collapseBlankLines(text string) string {
+	var lines []string
+
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,63 @@
+package main
+
+import "strings"
+
+// PageMeta holds the page's own metadata from its <meta> tags, which often
+// says more than the snippet Google cut from the page.
+type PageMeta struct {
+	// Title and Description are og:title and og:description.
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	// Published is article:published_time as the page states it, usually
+	// an ISO 8601 timestamp.
+	Published string `json:"published,omitempty"`
+	Author    string `json:"author,omitempty"`
+}
+
+// pageMeta picks the selected metatags from a result's pagemap, or returns
+// nil when the page has none of them.
+func pageMeta(pagemap *ResultPagemap) *PageMeta {
+	if pagemap == nil {
+		return nil
+	}
+
+	meta := PageMeta{
+		Title:       metatag(pagemap.Metatags, "og:title"),
+		Description: metatag(pagemap.Metatags, "og:description"),
+		Published:   metatag(pagemap.Metatags, "article:published_time"),
+		Author:      metatag(pagemap.Metatags, "author"),
+	}
+	if meta == (PageMeta{}) {
+		return nil
+	}
+
+	return &meta
+}
+
+// metatag returns the first non-blank value of the named metatag. Pages
+// usually have a single metatags block, but the API sends a list.
+func metatag(tags []map[string]string, name string) string {
+	for _, block := range tags {
+		if value := strings.TrimSpace(block[name]); value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// addPageMeta sets each result's PageMeta from its pagemap. Call it before
+// compactResults, which drops the pagemap.
+func addPageMeta(results []GoogleSearchResult) {
+	for i := range results {
+		results[i].PageMeta = pageMeta(results[i].Pagemap)
+	}
+}
+
+// addsToSnippet reports whether description says something the snippet
+// doesn't, so text output isn't padded with a copy of the snippet.
+func addsToSnippet(description, snippet string) bool {
+	trimmed := strings.TrimRight(snippet, ".… ")
+
+	return description != "" && (trimmed == "" || !strings.Contains(description, trimmed)) && !strings.Contains(snippet, description)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPageMeta(t *testing.T) {
+	tests := []struct {
+		name    string
+		pagemap *ResultPagemap
+		want    *PageMeta
+	}{
+		{name: "no pagemap"},
+		{name: "no selected tags", pagemap: &ResultPagemap{Metatags: []map[string]string{{"viewport": "width=device-width"}}}},
+		{
+			name: "article",
+			pagemap: &ResultPagemap{Metatags: []map[string]string{{
+				"og:title":               "Range over integers",
+				"og:description":         "What changed in Go 1.22.",
+				"article:published_time": "2024-02-06T09:00:00Z",
+				"author":                 " Russ Cox ",
+				"og:type":                "article",
+			}}},
+			want: &PageMeta{Title: "Range over integers", Description: "What changed in Go 1.22.", Published: "2024-02-06T09:00:00Z", Author: "Russ Cox"},
+		},
+		{
+			name:    "later block fills blanks",
+			pagemap: &ResultPagemap{Metatags: []map[string]string{{"og:title": "First", "author": ""}, {"og:title": "Second", "author": "Ann"}}},
+			want:    &PageMeta{Title: "First", Author: "Ann"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pageMeta(tt.pagemap); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("pageMeta = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddsToSnippet(t *testing.T) {
+	tests := []struct {
+		description, snippet string
+		want                 bool
+	}{
+		{"", "Anything.", false},
+		{"Plant tomatoes after the last frost.", "Plant tomatoes after the last frost ...", false},
+		{"Plant tomatoes", "Plant tomatoes after the last frost.", false},
+		{"A guide to spring planting.", "Plant tomatoes after the last frost.", true},
+		{"A guide to spring planting.", "", true},
+	}
+
+	for _, tt := range tests {
+		if got := addsToSnippet(tt.description, tt.snippet); got != tt.want {
+			t.Errorf("addsToSnippet(%q, %q) = %v, want %v", tt.description, tt.snippet, got, tt.want)
+		}
+	}
+}
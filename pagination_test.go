@@ -0,0 +1,185 @@
+package main
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/habuvo/mcp-internet-search/searchtest"
+)
+
+// TestAutoPagination checks that more than a page of results is fetched in
+// pages, merged without repeats and counted as one API call per page.
+func TestAutoPagination(t *testing.T) {
+	api := newFakeSearchAPI()
+	c := newConformanceClient(t, api)
+
+	result, err := callTool(t, c, "google_search", map[string]interface{}{
+		"query":         "golang",
+		"num_results":   float64(25),
+		"output_format": outputJSON,
+	})
+	if err != nil {
+		t.Fatalf("google_search: %v", err)
+	}
+
+	var response searchResponse
+	if err := json.Unmarshal([]byte(resultText(t, result)), &response); err != nil {
+		t.Fatalf("JSON output doesn't decode: %v", err)
+	}
+
+	if len(response.Results) != 25 || response.Results[24].Link != "https://example.com/25" || response.Metadata.APICalls != 3 {
+		t.Errorf("got %d results, last %+v, %d api calls; want 25 results through example.com/25 in 3 calls",
+			len(response.Results), response.Results[len(response.Results)-1], response.Metadata.APICalls)
+	}
+
+	// Statistics come from the first page
+	if response.Metadata.TotalResults != 10 || response.Metadata.SearchTimeMS != 250 {
+		t.Errorf("total_results %d, search_time_ms %d; want the first page's 10 and 250",
+			response.Metadata.TotalResults, response.Metadata.SearchTimeMS)
+	}
+
+	var pages []string
+	for _, params := range api.Requests() {
+		pages = append(pages, params.Get("start")+"+"+params.Get("num"))
+	}
+
+	if want := []string{"+10", "11+10", "21+5"}; len(pages) != len(want) || pages[0] != want[0] || pages[1] != want[1] || pages[2] != want[2] {
+		t.Errorf("requested pages %v, want %v", pages, want)
+	}
+
+	// The API runs out after 12 results and repeats one on the second page
+	api.Respond(func(params url.Values) []searchtest.Result {
+		start, _ := strconv.Atoi(params.Get("start"))
+		if start > 1 {
+			return append(searchtest.ResultsFrom("golang", 10, 1), searchtest.ResultsFrom("golang", 11, 2)...)
+		}
+
+		return searchtest.Results("golang", 10)
+	})
+
+	result, err = callTool(t, c, "google_search", map[string]interface{}{
+		"query":         "golang",
+		"num_results":   float64(40),
+		"output_format": outputJSON,
+	})
+	if err != nil {
+		t.Fatalf("google_search: %v", err)
+	}
+
+	response = searchResponse{}
+	if err := json.Unmarshal([]byte(resultText(t, result)), &response); err != nil {
+		t.Fatalf("JSON output doesn't decode: %v", err)
+	}
+
+	if len(response.Results) != 12 || response.Metadata.APICalls != 2 {
+		t.Errorf("got %d results in %d api calls, want 12 in 2", len(response.Results), response.Metadata.APICalls)
+	}
+}
+
+// TestNextStart checks that results report where the next page starts and
+// that passing it back as start continues from there.
+func TestNextStart(t *testing.T) {
+	api := newFakeSearchAPI()
+	c := newConformanceClient(t, api)
+
+	search := func(args map[string]interface{}) searchResponse {
+		t.Helper()
+
+		args["query"] = "golang"
+		args["output_format"] = outputJSON
+
+		result, err := callTool(t, c, "google_search", args)
+		if err != nil {
+			t.Fatalf("google_search: %v", err)
+		}
+
+		var response searchResponse
+		if err := json.Unmarshal([]byte(resultText(t, result)), &response); err != nil {
+			t.Fatalf("JSON output doesn't decode: %v", err)
+		}
+
+		return response
+	}
+
+	first := search(map[string]interface{}{"num_results": float64(15)})
+	if first.Metadata.NextStart != 16 {
+		t.Fatalf("next_start = %d, want 16", first.Metadata.NextStart)
+	}
+
+	second := search(map[string]interface{}{"num_results": float64(15), "start": float64(first.Metadata.NextStart)})
+	if second.Results[0].Link != "https://example.com/16" || second.Metadata.NextStart != 31 {
+		t.Errorf("second page starts at %s with next_start %d, want example.com/16 and 31", second.Results[0].Link, second.Metadata.NextStart)
+	}
+
+	// The API serves nothing past maxSearchDepth
+	if last := search(map[string]interface{}{"num_results": float64(10), "start": float64(91)}); last.Metadata.NextStart != 0 {
+		t.Errorf("next_start past the last page = %d, want none", last.Metadata.NextStart)
+	}
+
+	// Nor after a short page
+	api.Respond(func(params url.Values) []searchtest.Result {
+		return searchtest.Results("golang", 3)
+	})
+
+	if short := search(map[string]interface{}{}); short.Metadata.NextStart != 0 {
+		t.Errorf("next_start after a short page = %d, want none", short.Metadata.NextStart)
+	}
+
+	result, err := callTool(t, c, "google_search", map[string]interface{}{"query": "golang"})
+	if err != nil {
+		t.Fatalf("google_search: %v", err)
+	}
+
+	if text := resultText(t, result); strings.Contains(text, "next_start") {
+		t.Errorf("text footer has next_start after a short page:\n%s", text)
+	}
+}
+
+// TestNumResultsRejected refuses num_results that would otherwise be
+// clamped to a different, billed number of API calls.
+func TestNumResultsRejected(t *testing.T) {
+	api := newFakeSearchAPI()
+	c := newConformanceClient(t, api)
+
+	for _, n := range []float64{0, -3, 2.5, maxNumResults + 1, 500} {
+		result, err := callTool(t, c, "google_search", map[string]interface{}{"query": "golang", "num_results": n})
+		if err == nil && !result.IsError {
+			t.Errorf("num_results %v was accepted", n)
+		}
+	}
+
+	if requests := api.Requests(); len(requests) != 0 {
+		t.Errorf("%d API calls made for invalid num_results, want none", len(requests))
+	}
+}
+
+func TestDepthRejected(t *testing.T) {
+	api := newFakeSearchAPI()
+	c := newConformanceClient(t, api)
+
+	tools := map[string]map[string]interface{}{
+		"rank_check":       {"query": "golang", "domain": "go.dev"},
+		"compare_domains":  {"query": "golang", "domains": []interface{}{"go.dev", "golang.org"}},
+		"keyword_coverage": {"keywords": []interface{}{"golang", "rust"}, "domain": "go.dev"},
+	}
+
+	for tool, args := range tools {
+		for _, depth := range []interface{}{float64(0), float64(-10), 2.5, float64(maxSearchDepth + 1), "50"} {
+			args["depth"] = depth
+
+			result, err := callTool(t, c, tool, args)
+			if err == nil && !result.IsError {
+				t.Errorf("%s: depth %v was accepted", tool, depth)
+			} else if err != nil && !strings.Contains(err.Error(), "depth must be an integer") {
+				t.Errorf("%s: depth %v: unexpected error %v", tool, depth, err)
+			}
+		}
+	}
+
+	if requests := api.Requests(); len(requests) != 0 {
+		t.Errorf("%d API calls made for invalid depth, want none", len(requests))
+	}
+}
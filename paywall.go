@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// defaultPaywalledDomains are well-known publishers that gate most articles.
+var defaultPaywalledDomains = []string{
+	"nytimes.com", "wsj.com", "ft.com", "economist.com", "bloomberg.com",
+	"washingtonpost.com", "barrons.com", "thetimes.co.uk", "telegraph.co.uk",
+	"newyorker.com", "theatlantic.com", "wired.com", "hbr.org", "latimes.com",
+	"bostonglobe.com", "theinformation.com", "lemonde.fr", "spiegel.de",
+	"nikkei.com", "businessinsider.com",
+}
+
+// loadPaywalledDomains returns the paywalled domain set: the built-in list, or
+// GOOGLE_SEARCH_PAYWALLED_DOMAINS (comma-separated or @file) when set.
+func loadPaywalledDomains() (map[string]bool, error) {
+	domains := defaultPaywalledDomains
+
+	if value := os.Getenv("GOOGLE_SEARCH_PAYWALLED_DOMAINS"); value != "" {
+		var err error
+
+		domains, err = readDomainList(value)
+		if err != nil {
+			return nil, fmt.Errorf("GOOGLE_SEARCH_PAYWALLED_DOMAINS: %v", err)
+		}
+	}
+
+	set := make(map[string]bool, len(domains))
+	for _, domain := range domains {
+		set[domain] = true
+	}
+
+	return set, nil
+}
+
+// markPaywalled flags results from paywalled domains, or drops them when exclude is set.
+func markPaywalled(results []GoogleSearchResult, domains map[string]bool, exclude bool) []GoogleSearchResult {
+	kept := results[:0]
+
+	for _, result := range results {
+		result.Paywalled, _ = lookupDomain(domains, result.Link)
+		if result.Paywalled && exclude {
+			continue
+		}
+
+		kept = append(kept, result)
+	}
+
+	return kept
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// ansiEscape matches ANSI/VT100 escape sequences (CSI and OSC).
+var ansiEscape = regexp.MustCompile(`\x1b(?:\[[0-9;?]*[ -/]*[@-~]|\][^\x07\x1b]*(?:\x07|\x1b\\)|[@-Z\\-_])`)
+
+// asciiReplacements transliterates common typographic and Latin-1 characters.
+var asciiReplacements = map[rune]string{
+	' ': " ", ' ': " ", ' ': " ", ' ': " ", ' ': " ",
+	'‐': "-", '‑': "-", '‒': "-", '–': "-", '—': "--", '−': "-",
+	'‘': "'", '’': "'", '‚': "'", '′': "'",
+	'“': `"`, '”': `"`, '„': `"`, '«': `"`, '»': `"`, '″': `"`,
+	'…': "...", '→': "->", '←': "<-", '•': "*", '·': "*", '×': "x", '÷': "/",
+	'©': "(c)", '®': "(R)", '™': "(TM)", '°': " deg",
+	'€': "EUR", '£': "GBP", '¥': "JPY",
+	'À': "A", 'Á': "A", 'Â': "A", 'Ã': "A", 'Ä': "A", 'Å': "A", 'Æ': "AE",
+	'Ç': "C", 'È': "E", 'É': "E", 'Ê': "E", 'Ë': "E",
+	'Ì': "I", 'Í': "I", 'Î': "I", 'Ï': "I", 'Ð': "D", 'Ñ': "N",
+	'Ò': "O", 'Ó': "O", 'Ô': "O", 'Õ': "O", 'Ö': "O", 'Ø': "O",
+	'Ù': "U", 'Ú': "U", 'Û': "U", 'Ü': "U", 'Ý': "Y", 'Þ': "TH", 'ß': "ss",
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'æ': "ae",
+	'ç': "c", 'è': "e", 'é': "e", 'ê': "e", 'ë': "e",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ð': "d", 'ñ': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ý': "y", 'þ': "th", 'ÿ': "y",
+	'Œ': "OE", 'œ': "oe", 'Š': "S", 'š': "s", 'Ž': "Z", 'ž': "z",
+}
+
+// plainText makes text safe for terminals and legacy systems: escape
+// sequences and control characters (other than newlines) are removed,
+// common characters are transliterated and anything else non-ASCII becomes "?".
+func plainText(text string) string {
+	text = ansiEscape.ReplaceAllString(text, "")
+
+	var sb strings.Builder
+
+	for _, r := range text {
+		switch {
+		case r == '\n':
+			sb.WriteRune(r)
+		case r == '\t':
+			sb.WriteByte(' ')
+		case r < 0x80 && !unicode.IsControl(r):
+			sb.WriteRune(r)
+		case asciiReplacements[r] != "":
+			sb.WriteString(asciiReplacements[r])
+		case unicode.IsControl(r), unicode.Is(unicode.Cf, r), unicode.Is(unicode.Mn, r), unicode.Is(unicode.Sk, r):
+			// Drop controls, invisible formatting and combining marks
+		default:
+			sb.WriteByte('?')
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/joho/godotenv"
+)
+
+// profileEnv names the environment variable that selects a config file
+// profile; --profile sets it.
+const profileEnv = "GOOGLE_SEARCH_PROFILE"
+
+// maxReferenceDepth bounds settings referencing settings, to catch cycles.
+const maxReferenceDepth = 10
+
+var (
+	// profileHeader matches a line starting a profile section, e.g. [prod].
+	profileHeader = regexp.MustCompile(`^\s*\[([A-Za-z0-9_.-]+)\]\s*$`)
+	// configReference matches ${NAME}, or \${NAME} to keep it as is, after
+	// hideReferences.
+	configReference = regexp.MustCompile(`(\\?)\x00\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+)
+
+// configFile is a config file split into its settings for every deployment
+// and its named profiles, each a KEY=VALUE text.
+type configFile struct {
+	common   string
+	profiles map[string]string
+	// names lists the profiles in file order.
+	names []string
+}
+
+// parseConfigProfiles splits a config file at its [name] profile headers.
+// Lines before the first header apply to every profile.
+func parseConfigProfiles(text string) (*configFile, error) {
+	file := &configFile{profiles: make(map[string]string)}
+
+	var (
+		current string
+		section strings.Builder
+	)
+
+	flush := func() {
+		if current == "" {
+			file.common = section.String()
+		} else {
+			file.profiles[current] = section.String()
+		}
+
+		section.Reset()
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		match := profileHeader.FindStringSubmatch(line)
+		if match == nil {
+			section.WriteString(line + "\n")
+
+			continue
+		}
+
+		flush()
+
+		current = match[1]
+		if _, ok := file.profiles[current]; ok {
+			return nil, fmt.Errorf("profile [%s] is defined twice", current)
+		}
+
+		file.profiles[current] = ""
+		file.names = append(file.names, current)
+	}
+
+	flush()
+
+	return file, nil
+}
+
+// settings returns the settings of profile, which override the common ones,
+// with ${NAME} references expanded. An empty profile selects the common
+// settings alone.
+func (f *configFile) settings(profile string) (map[string]string, error) {
+	text := f.common
+
+	if profile != "" {
+		section, ok := f.profiles[profile]
+		if !ok {
+			if len(f.names) == 0 {
+				return nil, fmt.Errorf("profile %q is selected, but the file has no profiles", profile)
+			}
+
+			return nil, fmt.Errorf("no profile %q (have %s)", profile, strings.Join(f.names, ", "))
+		}
+
+		text += section
+	}
+
+	raw, err := godotenv.Unmarshal(hideReferences(text))
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(raw))
+	for name, value := range raw {
+		if values[name], err = expandReferences(value, raw, 0); err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+	}
+
+	return values, nil
+}
+
+// hideReferences keeps ${NAME} references from godotenv, which would expand
+// them from the file alone, replacing unset names with nothing.
+func hideReferences(text string) string {
+	return strings.ReplaceAll(text, "${", "\x00{")
+}
+
+// expandReferences replaces the ${NAME} references in value with the
+// environment variable NAME or, if it is unset, the file's setting NAME,
+// itself expanded. Referencing a name set in neither is an error.
+func expandReferences(value string, settings map[string]string, depth int) (string, error) {
+	if depth > maxReferenceDepth {
+		return "", fmt.Errorf("references nest more than %d deep; are they circular?", maxReferenceDepth)
+	}
+
+	var failure error
+
+	value = configReference.ReplaceAllStringFunc(value, func(reference string) string {
+		match := configReference.FindStringSubmatch(reference)
+		if match[1] != "" {
+			return "${" + match[2] + "}"
+		}
+
+		if env, ok := os.LookupEnv(match[2]); ok {
+			return env
+		}
+
+		setting, ok := settings[match[2]]
+		if !ok {
+			failure = fmt.Errorf("${%s} is not set", match[2])
+
+			return ""
+		}
+
+		expanded, err := expandReferences(setting, settings, depth+1)
+		if err != nil {
+			failure = err
+		}
+
+		return expanded
+	})
+
+	if failure != nil {
+		return "", failure
+	}
+
+	// Unterminated references stay as written
+	return strings.ReplaceAll(value, "\x00{", "${"), nil
+}
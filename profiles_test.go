@@ -0,0 +1,94 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+const profilesConfig = `# Shared by every deployment
+GOOGLE_SEARCH_ENGINE_ID=shared-cx
+GOOGLE_SEARCH_LOCALE=en
+REGION=eu
+
+[dev]
+GOOGLE_API_KEY=dev-key
+GOOGLE_SEARCH_REPORT_DIR=/tmp/reports
+
+[prod]
+GOOGLE_API_KEY=${PROD_API_KEY}
+GOOGLE_SEARCH_ENGINE_ID="prod-${REGION}"
+GOOGLE_SEARCH_APPEND_TERMS=costs \${literally}
+GOOGLE_SEARCH_HISTORY='${kept as written}'
+`
+
+func TestConfigProfiles(t *testing.T) {
+	t.Setenv("PROD_API_KEY", `s3cr3t"$with\specials`)
+
+	file, err := parseConfigProfiles(profilesConfig)
+	if err != nil {
+		t.Fatalf("parseConfigProfiles: %v", err)
+	}
+
+	if strings.Join(file.names, ",") != "dev,prod" {
+		t.Errorf("profiles = %v, want dev,prod", file.names)
+	}
+
+	tests := []struct {
+		profile string
+		want    map[string]string
+	}{
+		{"", map[string]string{
+			"GOOGLE_SEARCH_ENGINE_ID": "shared-cx",
+			"GOOGLE_API_KEY":          "",
+		}},
+		{"dev", map[string]string{
+			"GOOGLE_SEARCH_ENGINE_ID":  "shared-cx",
+			"GOOGLE_API_KEY":           "dev-key",
+			"GOOGLE_SEARCH_REPORT_DIR": "/tmp/reports",
+		}},
+		{"prod", map[string]string{
+			"GOOGLE_API_KEY":             `s3cr3t"$with\specials`,
+			"GOOGLE_SEARCH_ENGINE_ID":    "prod-eu",
+			"GOOGLE_SEARCH_LOCALE":       "en",
+			"GOOGLE_SEARCH_APPEND_TERMS": "costs ${literally}",
+			"GOOGLE_SEARCH_HISTORY":      "${kept as written}",
+			"GOOGLE_SEARCH_REPORT_DIR":   "",
+		}},
+	}
+
+	for _, tt := range tests {
+		values, err := file.settings(tt.profile)
+		if err != nil {
+			t.Errorf("settings(%q): %v", tt.profile, err)
+
+			continue
+		}
+
+		for name, want := range tt.want {
+			if got := values[name]; got != want {
+				t.Errorf("settings(%q)[%s] = %q, want %q", tt.profile, name, got, want)
+			}
+		}
+	}
+
+	if _, err := file.settings("staging"); err == nil || !strings.Contains(err.Error(), "have dev, prod") {
+		t.Errorf("unknown profile: err = %v", err)
+	}
+}
+
+func TestConfigProfileErrors(t *testing.T) {
+	for _, tt := range []struct{ config, want string }{
+		{"GOOGLE_API_KEY=${NOT_SET_ANYWHERE}\n", "${NOT_SET_ANYWHERE} is not set"},
+		{"A=${B}\nB=${A}\n", "circular"},
+		{"[prod]\nA=1\n[prod]\nA=2\n", "profile [prod] is defined twice"},
+	} {
+		file, err := parseConfigProfiles(tt.config)
+		if err == nil {
+			_, err = file.settings("")
+		}
+
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%q: err = %v, want %q", tt.config, err, tt.want)
+		}
+	}
+}
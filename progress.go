@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// progressReporter streams progress, including partial results, to a client
+// that asked for it with a progress token. A nil reporter does nothing.
+type progressReporter struct {
+	ctx    context.Context
+	server *server.MCPServer
+	token  mcp.ProgressToken
+	total  int
+}
+
+// newProgressReporter returns a reporter for request, or nil if the client
+// didn't send a progress token.
+func newProgressReporter(ctx context.Context, request mcp.CallToolRequest, total int) *progressReporter {
+	meta := request.Params.Meta
+	if meta == nil || meta.ProgressToken == nil {
+		return nil
+	}
+
+	srv := server.ServerFromContext(ctx)
+	if srv == nil {
+		return nil
+	}
+
+	return &progressReporter{ctx: ctx, server: srv, token: meta.ProgressToken, total: total}
+}
+
+// report sends a notifications/progress message for done of total steps.
+// message carries the partial result of the step so clients can act on it
+// before the whole operation completes.
+func (p *progressReporter) report(done int, message string) {
+	if p == nil {
+		return
+	}
+
+	// Progress is best-effort: a full or closed channel must not fail the call
+	_ = p.server.SendNotificationToClient(p.ctx, "notifications/progress", map[string]any{
+		"progressToken": p.token,
+		"progress":      done,
+		"total":         p.total,
+		"message":       message,
+	})
+}
+
+// reportJSON is like report with v encoded as JSON as the message.
+func (p *progressReporter) reportJSON(done int, v any) {
+	if p == nil {
+		return
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+
+	p.report(done, string(data))
+}
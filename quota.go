@@ -0,0 +1,387 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// quotaFileName is the file in cacheDir that keeps the day's and the
+// month's API call counts across restarts.
+const quotaFileName = "quota.json"
+
+// searchQuota holds the local daily budgets of API calls; nil means no
+// limit. It is set by loadConfig.
+var searchQuota atomic.Pointer[quotaTracker]
+
+// quotaReserve holds back Percent of the daily quota until At, a time of
+// day in UTC.
+type quotaReserve struct {
+	Percent int
+	At      time.Duration
+}
+
+// String formats the reserve the way GOOGLE_SEARCH_QUOTA_RESERVE spells it.
+func (r quotaReserve) String() string {
+	return fmt.Sprintf("%d%%@%02d:%02d", r.Percent, int(r.At.Hours()), int(r.At.Minutes())%60)
+}
+
+// quotaTools are the tools that call the search API, which may be given
+// budgets of their own.
+var quotaTools = []string{"google_search", "google_image_search", "rank_check", "compare_domains", "keyword_coverage"}
+
+// quotaSettings limit the API calls the server makes per quota day, which
+// starts at midnight Pacific time like Google's daily quota.
+type quotaSettings struct {
+	// Daily is the number of API calls allowed per day; zero means no limit.
+	Daily int
+	// Reserves hold back shares of Daily until later in the day, sorted by
+	// time.
+	Reserves []quotaReserve
+	// Tools limits the daily API calls of single tools, within Daily.
+	Tools map[string]int
+	// Spend caps the monthly cost of paid calls.
+	Spend spendCap
+}
+
+// enabled reports whether any limit is set.
+func (s quotaSettings) enabled() bool {
+	return s.Daily > 0 || len(s.Tools) > 0 || s.Spend.Monthly > 0
+}
+
+// loadQuota reads GOOGLE_SEARCH_DAILY_QUOTA, GOOGLE_SEARCH_TOOL_QUOTAS, a
+// comma-separated list of tool=calls budgets, and
+// GOOGLE_SEARCH_QUOTA_RESERVE, a comma-separated list of shares to hold back
+// until a UTC time of day, e.g. "30%@15:00".
+func loadQuota() (quotaSettings, error) {
+	var settings quotaSettings
+
+	if value := os.Getenv("GOOGLE_SEARCH_DAILY_QUOTA"); value != "" {
+		daily, err := strconv.Atoi(value)
+		if err != nil || daily < 1 {
+			return quotaSettings{}, fmt.Errorf("GOOGLE_SEARCH_DAILY_QUOTA must be a positive number of API calls, got %q", value)
+		}
+
+		settings.Daily = daily
+	}
+
+	tools, err := loadToolQuotas()
+	if err != nil {
+		return quotaSettings{}, err
+	}
+
+	settings.Tools = tools
+
+	if settings.Spend, err = loadSpendCap(); err != nil {
+		return quotaSettings{}, err
+	}
+
+	value := os.Getenv("GOOGLE_SEARCH_QUOTA_RESERVE")
+	if value == "" {
+		return settings, nil
+	}
+
+	if settings.Daily == 0 {
+		return quotaSettings{}, fmt.Errorf("GOOGLE_SEARCH_QUOTA_RESERVE requires GOOGLE_SEARCH_DAILY_QUOTA")
+	}
+
+	total := 0
+
+	for _, entry := range strings.Split(value, ",") {
+		reserve, err := parseQuotaReserve(strings.TrimSpace(entry))
+		if err != nil {
+			return quotaSettings{}, fmt.Errorf("invalid GOOGLE_SEARCH_QUOTA_RESERVE entry %q: %v", entry, err)
+		}
+
+		total += reserve.Percent
+		settings.Reserves = append(settings.Reserves, reserve)
+	}
+
+	if total >= 100 {
+		return quotaSettings{}, fmt.Errorf("GOOGLE_SEARCH_QUOTA_RESERVE holds back %d%% of the quota; it must leave some for the start of the day", total)
+	}
+
+	sort.Slice(settings.Reserves, func(i, j int) bool { return settings.Reserves[i].At < settings.Reserves[j].At })
+
+	return settings, nil
+}
+
+// loadToolQuotas reads GOOGLE_SEARCH_TOOL_QUOTAS, e.g.
+// "rank_check=200,keyword_coverage=100".
+func loadToolQuotas() (map[string]int, error) {
+	value := os.Getenv("GOOGLE_SEARCH_TOOL_QUOTAS")
+	if value == "" {
+		return nil, nil
+	}
+
+	tools := make(map[string]int)
+
+	for _, entry := range strings.Split(value, ",") {
+		name, limit, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid GOOGLE_SEARCH_TOOL_QUOTAS entry %q (want TOOL=CALLS, e.g. rank_check=200)", entry)
+		}
+
+		if !slices.Contains(quotaTools, name) {
+			return nil, fmt.Errorf("unknown tool %q in GOOGLE_SEARCH_TOOL_QUOTAS (want %s)", name, strings.Join(quotaTools, ", "))
+		}
+
+		calls, err := strconv.Atoi(limit)
+		if err != nil || calls < 0 {
+			return nil, fmt.Errorf("GOOGLE_SEARCH_TOOL_QUOTAS budget of %s must be a number of API calls, got %q", name, limit)
+		}
+
+		tools[name] = calls
+	}
+
+	return tools, nil
+}
+
+// parseQuotaReserve parses one reserve, e.g. "30%@15:00".
+func parseQuotaReserve(entry string) (quotaReserve, error) {
+	percent, at, ok := strings.Cut(entry, "@")
+	if !ok {
+		return quotaReserve{}, fmt.Errorf("want PERCENT%%@HH:MM, e.g. 30%%@15:00")
+	}
+
+	share, err := strconv.Atoi(strings.TrimSuffix(percent, "%"))
+	if err != nil || share < 1 || share > 99 {
+		return quotaReserve{}, fmt.Errorf("share must be a percentage from 1%% to 99%%")
+	}
+
+	clock, err := time.Parse("15:04", at)
+	if err != nil {
+		return quotaReserve{}, fmt.Errorf("time must be HH:MM in UTC")
+	}
+
+	return quotaReserve{Percent: share, At: time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute}, nil
+}
+
+// quotaUsage is the persisted count of the quota day's API calls and the
+// month's paid ones.
+type quotaUsage struct {
+	Date  string `json:"date"`
+	Calls int    `json:"calls"`
+	// Tools counts the calls of the tools with budgets of their own.
+	Tools map[string]int `json:"tools,omitempty"`
+	// Month counts PaidCalls, the calls beyond the free ones of each day.
+	Month     string `json:"month,omitempty"`
+	PaidCalls int    `json:"paid_calls,omitempty"`
+}
+
+// quotaTracker counts API calls per quota day and refuses calls beyond the
+// share of the daily quota available at the time. It is safe for concurrent
+// use.
+type quotaTracker struct {
+	settings quotaSettings
+	path     string
+	now      func() time.Time
+
+	mu    sync.Mutex
+	usage quotaUsage
+}
+
+// openQuota returns a tracker for the configured daily quotas, continuing
+// the count of an earlier run today, or nil if there are none.
+func openQuota(config *Config) *quotaTracker {
+	if !config.Quota.enabled() {
+		return nil
+	}
+
+	q := &quotaTracker{settings: config.Quota, now: time.Now}
+
+	dir, err := cacheDir()
+	if err != nil {
+		log.Printf("Quota usage is counted in memory only: %v", err)
+
+		return q
+	}
+
+	q.path = filepath.Join(dir, quotaFileName)
+	if err := q.load(); err != nil {
+		log.Printf("Counting quota usage afresh: %v", err)
+	}
+
+	return q
+}
+
+// budgetError refuses an API call that would exceed the share of the local
+// daily quota available now, or the daily budget of a tool.
+type budgetError struct {
+	// Tool is set when the tool's own budget is spent.
+	Tool          string
+	Used, Allowed int
+	// Until is when more of the quota becomes available.
+	Until time.Time
+}
+
+// Error implements error.
+func (e *budgetError) Error() string {
+	budget := "daily search budget"
+	if e.Tool != "" {
+		budget = "daily search budget of " + e.Tool
+	}
+
+	return fmt.Sprintf("%s spent: %d of %d API calls allowed so far today were used; more become available at %s",
+		budget, e.Used, e.Allowed, e.Until.UTC().Format("15:04 UTC"))
+}
+
+// Is reports whether target is errQuotaExceeded.
+func (e *budgetError) Is(target error) bool {
+	return target == errQuotaExceeded
+}
+
+// take counts calls API calls of tool against today's budgets, or refuses
+// them all if the budgets available now don't cover them. A nil tracker
+// allows everything.
+func (q *quotaTracker) take(tool string, calls int) error {
+	if q == nil {
+		return nil
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now()
+	start := quotaDayStart(now)
+	usage := &q.usage
+
+	if date := start.Format(time.DateOnly); usage.Date != date {
+		usage.Date, usage.Calls, usage.Tools = date, 0, nil
+	}
+
+	if month := start.Format("2006-01"); usage.Month != month {
+		usage.Month, usage.PaidCalls = month, 0
+	}
+
+	if usage.Tools == nil {
+		usage.Tools = make(map[string]int)
+	}
+
+	if limit, ok := q.settings.Tools[tool]; ok && usage.Tools[tool]+calls > limit {
+		return &budgetError{Tool: tool, Used: usage.Tools[tool], Allowed: limit, Until: quotaDayStart(start.Add(36 * time.Hour))}
+	}
+
+	allowed, until := q.allowance(start, now)
+	if q.settings.Daily > 0 && usage.Calls+calls > allowed {
+		return &budgetError{Used: usage.Calls, Allowed: allowed, Until: until}
+	}
+
+	// Only calls beyond the day's free ones are paid
+	spend := q.settings.Spend
+	paid := max(usage.Calls+calls-spend.FreePerDay, 0) - max(usage.Calls-spend.FreePerDay, 0)
+
+	if spend.Monthly > 0 && paid > 0 && spend.cost(usage.PaidCalls+paid) > spend.Monthly+1e-9 {
+		return &spendCapError{
+			Cap:        spend.Monthly,
+			Spent:      spend.cost(usage.PaidCalls),
+			FreePerDay: spend.FreePerDay,
+			Until:      quotaMonthStart(start).AddDate(0, 1, 0),
+		}
+	}
+
+	usage.Calls += calls
+	usage.PaidCalls += paid
+	if _, ok := q.settings.Tools[tool]; ok {
+		usage.Tools[tool] += calls
+	}
+
+	if err := q.save(); err != nil {
+		log.Printf("Failed to save quota usage: %v", err)
+	}
+
+	return nil
+}
+
+// allowance returns the API calls allowed by now in the quota day starting
+// at start, and when the next reserve is released: the end of the day if
+// none is left.
+func (q *quotaTracker) allowance(start, now time.Time) (int, time.Time) {
+	reserved := 0
+	until := quotaDayStart(start.Add(36 * time.Hour))
+
+	for _, reserve := range q.settings.Reserves {
+		release := reserveRelease(start, reserve)
+		if now.Before(release) {
+			reserved += reserve.Percent
+			until = minTime(until, release)
+		}
+	}
+
+	return q.settings.Daily * (100 - reserved) / 100, until
+}
+
+// quotaDayStart returns the start of the quota day containing t.
+func quotaDayStart(t time.Time) time.Time {
+	local := t.In(quotaResetZone)
+
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, quotaResetZone)
+}
+
+// reserveRelease returns the first time at or after start whose UTC time of
+// day is the reserve's.
+func reserveRelease(start time.Time, reserve quotaReserve) time.Time {
+	utc := start.UTC()
+	release := time.Date(utc.Year(), utc.Month(), utc.Day(), 0, 0, 0, 0, time.UTC).Add(reserve.At)
+
+	if release.Before(start) {
+		release = release.AddDate(0, 0, 1)
+	}
+
+	return release
+}
+
+// minTime returns the earlier of a and b.
+func minTime(a, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+
+	return a
+}
+
+// load reads the count saved by an earlier run.
+func (q *quotaTracker) load() error {
+	data, err := os.ReadFile(q.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, &q.usage); err != nil {
+		return fmt.Errorf("failed to parse %s: %v", q.path, err)
+	}
+
+	return nil
+}
+
+// save writes the count atomically. Callers must hold mu.
+func (q *quotaTracker) save() error {
+	if q.path == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(q.usage)
+	if err != nil {
+		return err
+	}
+
+	tmp := q.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, q.path)
+}
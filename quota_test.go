@@ -0,0 +1,163 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestQuotaShaping holds back reserved shares of the daily quota until
+// their time and starts counting afresh each quota day.
+func TestQuotaShaping(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), quotaFileName)
+	settings := quotaSettings{Daily: 10, Reserves: []quotaReserve{{Percent: 30, At: 15 * time.Hour}}}
+
+	q := &quotaTracker{settings: settings, path: path, now: func() time.Time { return now }}
+
+	// Before 15:00 UTC, 30% is held back
+	if err := q.take("google_search", 7); err != nil {
+		t.Fatalf("take(7) at 12:00: %v", err)
+	}
+
+	err := q.take("google_search", 1)
+
+	var budgetErr *budgetError
+	if !errors.As(err, &budgetErr) || !errors.Is(err, errQuotaExceeded) {
+		t.Fatalf("take beyond the morning share: err = %v, want a budgetError", err)
+	}
+
+	if budgetErr.Used != 7 || budgetErr.Allowed != 7 || !budgetErr.Until.Equal(time.Date(2026, 10, 16, 15, 0, 0, 0, time.UTC)) {
+		t.Errorf("budgetError = %+v, want 7 of 7 until 15:00 UTC", budgetErr)
+	}
+
+	// A restart continues the count
+	q = &quotaTracker{settings: settings, path: path, now: func() time.Time { return now }}
+	if err := q.load(); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	now = time.Date(2026, 10, 16, 15, 30, 0, 0, time.UTC)
+	if err := q.take("google_search", 3); err != nil {
+		t.Fatalf("take(3) at 15:30: %v", err)
+	}
+
+	// Spent for the day: more comes at midnight Pacific
+	nextDay := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	if err := q.take("google_search", 1); !errors.As(err, &budgetErr) || !budgetErr.Until.Equal(quotaDayStart(nextDay)) {
+		t.Errorf("take after the whole quota: err = %v, want a budgetError until %v", err, quotaDayStart(nextDay))
+	}
+
+	now = nextDay
+	if err := q.take("google_search", 7); err != nil {
+		t.Errorf("take(7) on the next quota day: %v", err)
+	}
+
+	var unlimited *quotaTracker
+	if err := unlimited.take("google_search", 1000); err != nil {
+		t.Errorf("nil tracker: %v", err)
+	}
+}
+
+// TestQuotaLimitsSearches refuses the pages of a search beyond the budget,
+// keeping the pages already paid for.
+func TestQuotaLimitsSearches(t *testing.T) {
+	c := newConformanceClient(t, newFakeSearchAPI())
+
+	searchQuota.Store(&quotaTracker{settings: quotaSettings{Daily: 1}, now: time.Now})
+	t.Cleanup(func() { searchQuota.Store(nil) })
+
+	result, err := callTool(t, c, "google_search", map[string]interface{}{"query": "golang", "num_results": float64(15)})
+	if err != nil {
+		t.Fatalf("google_search: %v", err)
+	}
+
+	text := resultText(t, result)
+	if !strings.Contains(text, "Found 10 results") || !strings.Contains(text, "incomplete: results from 11 on failed: daily search budget spent") {
+		t.Errorf("want the first page and the budget explained, got:\n%s", text)
+	}
+
+	result, err = callTool(t, c, "google_search", map[string]interface{}{"query": "golang"})
+	if err == nil && !result.IsError {
+		t.Fatalf("a search succeeded with the budget spent")
+	}
+
+	if err == nil || !strings.Contains(err.Error(), "daily search budget spent") {
+		t.Errorf("err = %v, want the budget explained", err)
+	}
+}
+
+func TestLoadQuota(t *testing.T) {
+	tests := []struct {
+		daily, reserve, tools string
+		want                  string
+		wantErr               string
+	}{
+		{daily: "", reserve: "", want: "{0 [] map[] {0 100 5}}"},
+		{daily: "100", reserve: "10%@20:00, 30%@15:00", want: "{100 [30%@15:00 10%@20:00] map[] {0 100 5}}"},
+		{daily: "100", reserve: "30%", wantErr: "PERCENT%@HH:MM"},
+		{daily: "100", reserve: "30%@3pm", wantErr: "HH:MM"},
+		{daily: "100", reserve: "60%@12:00,40%@18:00", wantErr: "holds back 100%"},
+		{daily: "", reserve: "30%@15:00", wantErr: "requires GOOGLE_SEARCH_DAILY_QUOTA"},
+		{daily: "lots", wantErr: "positive number"},
+		{tools: "rank_check=200, keyword_coverage=0", want: "{0 [] map[keyword_coverage:0 rank_check:200] {0 100 5}}"},
+		{tools: "meta_search=10", wantErr: "unknown tool"},
+		{tools: "rank_check", wantErr: "TOOL=CALLS"},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("GOOGLE_SEARCH_DAILY_QUOTA", tt.daily)
+		t.Setenv("GOOGLE_SEARCH_QUOTA_RESERVE", tt.reserve)
+		t.Setenv("GOOGLE_SEARCH_TOOL_QUOTAS", tt.tools)
+
+		settings, err := loadQuota()
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%q, %q: err = %v, want %q", tt.daily, tt.reserve, err, tt.wantErr)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%q, %q: %v", tt.daily, tt.reserve, err)
+
+			continue
+		}
+
+		if got := fmt.Sprint(settings); got != tt.want {
+			t.Errorf("%q, %q: got %s, want %s", tt.daily, tt.reserve, got, tt.want)
+		}
+	}
+}
+
+// TestToolQuotas charges a tool's calls to its own budget as well as the
+// daily one, and leaves other tools alone when it is spent.
+func TestToolQuotas(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	q := &quotaTracker{
+		settings: quotaSettings{Daily: 10, Tools: map[string]int{"rank_check": 3}},
+		now:      func() time.Time { return now },
+	}
+
+	if err := q.take("rank_check", 3); err != nil {
+		t.Fatalf("take rank_check within its budget: %v", err)
+	}
+
+	var budgetErr *budgetError
+	if err := q.take("rank_check", 1); !errors.As(err, &budgetErr) || budgetErr.Tool != "rank_check" || !strings.Contains(err.Error(), "budget of rank_check spent") {
+		t.Errorf("take rank_check beyond its budget: err = %v", err)
+	}
+
+	if err := q.take("google_search", 7); err != nil {
+		t.Errorf("take google_search after rank_check's budget was spent: %v", err)
+	}
+
+	// rank_check's calls counted toward the daily quota too
+	if err := q.take("google_search", 1); !errors.As(err, &budgetErr) || budgetErr.Tool != "" || budgetErr.Used != 10 {
+		t.Errorf("take beyond the daily quota: err = %v", err)
+	}
+}
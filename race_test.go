@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/habuvo/mcp-internet-search/searchtest"
+)
+
+// TestConcurrentToolCalls runs parallel tool calls from several clients over
+// SSE, behind the message worker pool as serveSSE sets it up, so that
+// `go test -race` checks the state tool calls share: session usage, the
+// degraded-mode cache, the latency tracker, the engine probe and the
+// history logs.
+func TestConcurrentToolCalls(t *testing.T) {
+	api := newFakeSearchAPI()
+	api.Respond(func(params url.Values) []searchtest.Result {
+		if strings.HasPrefix(params.Get("q"), "empty") {
+			return nil
+		}
+
+		return searchtest.Results(params.Get("q"), 3)
+	})
+
+	ts := httptest.NewUnstartedServer(nil)
+	pool := newWorkerPool("messages", 4, 64)
+	ts.Config.Handler = pool.wrap(server.NewSSEServer(newConformanceServer(t, api),
+		server.WithBaseURL("http://"+ts.Listener.Addr().String()),
+	))
+	ts.Start()
+	t.Cleanup(ts.Close)
+
+	const clients, callsPerClient = 4, 12
+
+	sessions := make([]*callSession, clients)
+	for i := range sessions {
+		sessions[i] = &callSession{t: t, c: connectClient(t, ts.URL)}
+	}
+
+	// Mix every tool and the code paths that touch shared state
+	runParallel(sessions, callsPerClient, func(s *callSession, call int) {
+		query := fmt.Sprintf("query %d", call%3)
+
+		switch call % 5 {
+		case 0:
+			s.call("google_search", map[string]interface{}{"query": query, "session_id": "shared", "output_format": outputJSON})
+		case 1:
+			s.call("google_search", map[string]interface{}{"query": "empty " + query})
+		case 2:
+			s.call("keyword_coverage", map[string]interface{}{"keywords": []interface{}{"alpha", "beta"}, "domain": "example.com"})
+		case 3:
+			s.call("rank_check", map[string]interface{}{"query": query, "domain": "example.com"})
+		case 4:
+			s.call("query_analytics", map[string]interface{}{})
+		}
+	})
+
+	// With the API down, parallel calls share the degraded-mode cache
+	api.Fail(http.StatusServiceUnavailable, "Backend Error")
+
+	runParallel(sessions, callsPerClient, func(s *callSession, call int) {
+		text := s.call("google_search", map[string]interface{}{"query": fmt.Sprintf("query %d", call%3)})
+		if text != "" && !strings.Contains(text, "degraded") {
+			t.Errorf("result served while the API is down isn't labeled degraded:\n%s", text)
+		}
+	})
+
+	if shed := pool.shed.Load(); shed != 0 {
+		t.Errorf("worker pool shed %d requests", shed)
+	}
+}
+
+// callSession is one client making calls in TestConcurrentToolCalls.
+type callSession struct {
+	t *testing.T
+	c *client.SSEMCPClient
+}
+
+// call calls a tool, reporting a failed call as a test error, and returns
+// the text output or "" if the call failed.
+func (s *callSession) call(name string, args map[string]interface{}) string {
+	result, err := callTool(s.t, s.c, name, args)
+	if err != nil {
+		s.t.Errorf("%s %v: %v", name, args, err)
+
+		return ""
+	}
+
+	if result.IsError || len(result.Content) != 1 {
+		s.t.Errorf("%s %v: unexpected result %+v", name, args, result)
+
+		return ""
+	}
+
+	text, _ := result.Content[0].(mcp.TextContent)
+
+	return text.Text
+}
+
+// runParallel runs calls calls per session at once and waits for all of them.
+func runParallel(sessions []*callSession, calls int, call func(s *callSession, call int)) {
+	var wg sync.WaitGroup
+
+	for _, s := range sessions {
+		for i := range calls {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+				call(s, i)
+			}()
+		}
+	}
+
+	wg.Wait()
+}
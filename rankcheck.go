@@ -0,0 +1,279 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// maxSearchDepth is the deepest result position the API will return.
+	maxSearchDepth      = 100
+	defaultRankDepth    = 10
+	rankTrendLength     = 5
+	rankHistoryFileName = "rank_history.jsonl"
+)
+
+// rankCheck is one recorded rank check.
+type rankCheck struct {
+	Time      time.Time `json:"time"`
+	Query     string    `json:"query"`
+	Domain    string    `json:"domain"`
+	Depth     int       `json:"depth"`
+	Positions []int     `json:"positions"`
+}
+
+// rankHistory persists rank checks for trend reporting.
+type rankHistory struct {
+	jsonlLog
+}
+
+// registerRankCheckTool creates and registers the rank_check tool with the server.
+func registerRankCheckTool(s *server.MCPServer, config *Config) {
+	history := &rankHistory{}
+
+	if dir, err := cacheDir(); err != nil {
+		log.Printf("Rank history disabled: %v", err)
+	} else {
+		history.path = filepath.Join(dir, rankHistoryFileName)
+	}
+
+	s.AddTool(createRankCheckTool(), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleRankCheckRequest(ctx, request, config, history)
+	})
+}
+
+// createRankCheckTool creates and configures the rank_check tool.
+func createRankCheckTool() mcp.Tool {
+	return mcp.NewTool("rank_check",
+		mcp.WithDescription("Report where a domain ranks in Google results for a query, with the trend from earlier checks"),
+		mcp.WithString("query",
+			mcp.Required(),
+			mcp.Description("The search query"),
+		),
+		mcp.WithString("domain",
+			mcp.Required(),
+			mcp.Description("Domain to look for, e.g. example.com (subdomains match)"),
+		),
+		mcp.WithNumber("depth",
+			mcp.Description(fmt.Sprintf("How many top results to inspect (max %d, default %d); each 10 results cost one API call", maxSearchDepth, defaultRankDepth)),
+		),
+	)
+}
+
+// handleRankCheckRequest processes a rank_check tool request.
+func handleRankCheckRequest(ctx context.Context,
+	request mcp.CallToolRequest,
+	config *Config,
+	history *rankHistory,
+) (*mcp.CallToolResult, error) {
+	query, ok := request.Params.Arguments["query"].(string)
+	if !ok || query == "" {
+		return nil, fmt.Errorf("query must be a non-empty string")
+	}
+
+	domain, _ := request.Params.Arguments["domain"].(string)
+	domain = normalizeDomain(domain)
+	if domain == "" {
+		return nil, fmt.Errorf("domain must be a non-empty string")
+	}
+
+	depth, err := extractDepth(request.Params.Arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	progress := newProgressReporter(ctx, request, searchPages(depth))
+
+	results, apiCalls, err := searchDepth(ctx, "rank_check", query, depth, config, progress)
+	if err != nil {
+		return nil, fmt.Errorf("search failed: %v", err)
+	}
+
+	check := rankCheck{
+		Time:      time.Now().UTC(),
+		Query:     query,
+		Domain:    domain,
+		Depth:     depth,
+		Positions: domainPositions(results, domain),
+	}
+
+	// Read the trend before recording this check
+	previous, err := history.recent(query, domain, rankTrendLength)
+	if err != nil {
+		log.Printf("Failed to read rank history: %v", err)
+	}
+
+	if err := history.record(check); err != nil {
+		log.Printf("Failed to record rank check: %v", err)
+	}
+
+	return mcp.NewToolResultText(formatRankCheck(check, results, previous, apiCalls)), nil
+}
+
+// extractDepth extracts and validates the depth parameter. Invalid values
+// are rejected rather than clamped, since every page of depth is billed.
+func extractDepth(arguments map[string]interface{}) (int, error) {
+	raw, ok := arguments["depth"]
+	if !ok {
+		return defaultRankDepth, nil
+	}
+
+	depth, ok := raw.(float64)
+	if !ok || depth != float64(int(depth)) || depth < 1 || depth > maxSearchDepth {
+		return 0, fmt.Errorf("depth must be an integer from 1 to %d", maxSearchDepth)
+	}
+
+	return int(depth), nil
+}
+
+// searchDepth fetches the top depth results page by page for tool, stopping
+// early when the results run out. It returns the results and the number of
+// API calls made.
+func searchDepth(ctx context.Context, tool, query string, depth int, config *Config, progress *progressReporter) ([]GoogleSearchResult, int, error) {
+	var (
+		results  []GoogleSearchResult
+		apiCalls int
+	)
+
+	for start := 1; start <= depth; start += pageSize {
+		opts := SearchOptions{
+			Query:       query,
+			NumResults:  min(pageSize, depth-start+1),
+			Start:       start,
+			Tool:        tool,
+			Safe:        config.SafeSearch,
+			AppendTerms: config.AppendTerms,
+		}
+
+		page, err := performGoogleSearch(ctx, opts, config.APIKey, config.SearchEngineID)
+		apiCalls++
+
+		if err != nil {
+			return nil, apiCalls, err
+		}
+
+		results = append(results, page...)
+		progress.reportJSON(apiCalls, pageProgress{Start: start, Results: page})
+
+		if len(page) < opts.NumResults {
+			break
+		}
+	}
+
+	return results, apiCalls, nil
+}
+
+// pageProgress is the partial result streamed after each page of a deep search.
+type pageProgress struct {
+	// Start is the 1-based position of the page's first result.
+	Start   int                  `json:"start"`
+	Results []GoogleSearchResult `json:"results"`
+}
+
+// searchPages returns the number of API calls a search to depth takes.
+func searchPages(depth int) int {
+	return (depth + pageSize - 1) / pageSize
+}
+
+// normalizeDomain reduces user input like "https://www.Example.com/" to "example.com".
+func normalizeDomain(domain string) string {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	domain = strings.TrimPrefix(domain, "https://")
+	domain = strings.TrimPrefix(domain, "http://")
+	domain, _, _ = strings.Cut(domain, "/")
+
+	return strings.TrimPrefix(domain, "www.")
+}
+
+// domainPositions returns the 1-based positions of results on domain.
+func domainPositions(results []GoogleSearchResult, domain string) []int {
+	domains := map[string]bool{domain: true}
+
+	var positions []int
+
+	for i, result := range results {
+		if _, ok := lookupDomain(domains, result.Link); ok {
+			positions = append(positions, i+1)
+		}
+	}
+
+	return positions
+}
+
+// record appends a check to the history file.
+func (h *rankHistory) record(check rankCheck) error {
+	return h.append(check)
+}
+
+// recent returns up to n most recent checks for query and domain, oldest first.
+func (h *rankHistory) recent(query, domain string, n int) ([]rankCheck, error) {
+	var checks []rankCheck
+
+	err := h.scan(func(line []byte) {
+		var check rankCheck
+		if err := json.Unmarshal(line, &check); err != nil {
+			return
+		}
+
+		if check.Query == query && check.Domain == domain {
+			checks = append(checks, check)
+			if len(checks) > n {
+				checks = checks[1:]
+			}
+		}
+	})
+
+	return checks, err
+}
+
+// formatRankCheck formats a rank check result with its trend.
+func formatRankCheck(check rankCheck, results []GoogleSearchResult, previous []rankCheck, apiCalls int) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "Rank check for %q, domain %s (top %d, %d results inspected):\n\n", check.Query, check.Domain, check.Depth, len(results))
+
+	if len(check.Positions) == 0 {
+		fmt.Fprintf(&sb, "%s does not appear in the top %d results.\n", check.Domain, check.Depth)
+	} else {
+		fmt.Fprintf(&sb, "Positions: %s\n\n", formatPositions(check.Positions))
+
+		for _, pos := range check.Positions {
+			result := results[pos-1]
+			fmt.Fprintf(&sb, "%d. %s\n   URL: %s\n", pos, result.Title, result.Link)
+		}
+	}
+
+	if len(previous) > 0 {
+		sb.WriteString("\nTrend (earlier checks):\n")
+
+		for _, p := range previous {
+			fmt.Fprintf(&sb, "   %s  top %d: %s\n", p.Time.Format("2006-01-02 15:04 UTC"), p.Depth, formatPositions(p.Positions))
+		}
+	}
+
+	fmt.Fprintf(&sb, "\n---\nprovider: %s | api_calls: %d\n", providerName, apiCalls)
+
+	return sb.String()
+}
+
+// formatPositions renders positions as "3, 17" or "not ranked".
+func formatPositions(positions []int) string {
+	if len(positions) == 0 {
+		return "not ranked"
+	}
+
+	parts := make([]string, len(positions))
+	for i, pos := range positions {
+		parts[i] = fmt.Sprint(pos)
+	}
+
+	return strings.Join(parts, ", ")
+}
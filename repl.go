@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const replHelp = `Type a query to search, or a command:
+  /num N        number of results (1-10)
+  /lang CODE    restrict to a language, e.g. /lang de (empty to clear)
+  /site DOMAIN  restrict to a site, e.g. /site go.dev (empty to clear)
+  /date PERIOD  restrict to a recent period, e.g. /date w2 (empty to clear)
+  /cx ID        switch search engine ID
+  /show         show current settings
+  /help         show this help
+  /quit         exit
+`
+
+// replSession holds the settings applied to every query in the REPL.
+type replSession struct {
+	config *Config
+	opts   SearchOptions
+}
+
+// runREPL reads queries from in and writes formatted results to out until EOF or /quit.
+func runREPL(in io.Reader, out io.Writer) error {
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	session := &replSession{
+		config: config,
+		opts:   SearchOptions{NumResults: defaultNumResults, Safe: config.SafeSearch, AppendTerms: config.AppendTerms},
+	}
+
+	fmt.Fprint(out, replHelp)
+
+	scanner := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(out, "> ")
+
+		if !scanner.Scan() {
+			fmt.Fprintln(out)
+
+			return scanner.Err()
+		}
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "/") {
+			if quit := session.command(line, out); quit {
+				return nil
+			}
+
+			continue
+		}
+
+		opts := session.opts
+		opts.Query = line
+
+		results, err := performGoogleSearch(context.Background(), opts, config.APIKey, config.SearchEngineID)
+		if err != nil {
+			fmt.Fprintf(out, "search failed: %v\n", err)
+
+			continue
+		}
+
+		fmt.Fprint(out, formatSearchResults(results, config.messages("")))
+	}
+}
+
+// command applies a slash command and reports whether the REPL should exit.
+func (s *replSession) command(line string, out io.Writer) bool {
+	name, arg, _ := strings.Cut(line, " ")
+	arg = strings.TrimSpace(arg)
+
+	switch name {
+	case "/quit", "/exit":
+		return true
+	case "/help":
+		fmt.Fprint(out, replHelp)
+	case "/num":
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 1 || n > maxNumResults {
+			fmt.Fprintf(out, "/num expects a number between 1 and %d\n", maxNumResults)
+
+			return false
+		}
+
+		s.opts.NumResults = n
+	case "/lang":
+		if arg == "" {
+			s.opts.Language = ""
+
+			return false
+		}
+
+		language, err := languageRestrict(arg)
+		if err != nil {
+			fmt.Fprintf(out, "/lang: %v\n", err)
+
+			return false
+		}
+
+		s.opts.Language = language
+	case "/site":
+		s.opts.SiteSearch = arg
+	case "/date":
+		if arg != "" && !dateRestrictPattern.MatchString(arg) {
+			fmt.Fprintln(out, "/date expects d, w, m or y followed by a number, e.g. /date w2")
+
+			return false
+		}
+
+		s.opts.DateRestrict = arg
+	case "/cx":
+		if arg == "" {
+			fmt.Fprintln(out, "/cx expects a search engine ID")
+
+			return false
+		}
+
+		s.config.SearchEngineID = arg
+	case "/show":
+		fmt.Fprintf(out, "num=%d lang=%q site=%q date=%q cx=%s\n",
+			s.opts.NumResults, s.opts.Language, s.opts.SiteSearch, s.opts.DateRestrict, s.config.SearchEngineID)
+	default:
+		fmt.Fprintf(out, "unknown command %s (try /help)\n", name)
+	}
+
+	return false
+}
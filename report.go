@@ -0,0 +1,228 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// reportResultsPerSearch is how many top results of each search a report
+// lists as findings.
+const reportResultsPerSearch = 5
+
+// loadReportDir reads GOOGLE_SEARCH_REPORT_DIR, where export_report saves
+// reports; unset disables saving.
+func loadReportDir() (string, error) {
+	dir := os.Getenv("GOOGLE_SEARCH_REPORT_DIR")
+	if dir != "" && !filepath.IsAbs(dir) {
+		return "", fmt.Errorf("GOOGLE_SEARCH_REPORT_DIR must be an absolute path, got %q", dir)
+	}
+
+	return dir, nil
+}
+
+// citations numbers sources in the order they are first cited. A page is
+// cited once however many searches found it.
+type citations struct {
+	numbers map[string]int
+	sources []GoogleSearchResult
+}
+
+// cite returns the citation number of result's page.
+func (c *citations) cite(result GoogleSearchResult) int {
+	id := resultID(result.Link)
+	if n, ok := c.numbers[id]; ok {
+		return n
+	}
+
+	if c.numbers == nil {
+		c.numbers = make(map[string]int)
+	}
+
+	c.sources = append(c.sources, result)
+	c.numbers[id] = len(c.sources)
+
+	return len(c.sources)
+}
+
+// registerExportReportTool creates and registers the export_report tool with the server.
+func registerExportReportTool(s *server.MCPServer, config *Config, store *resultStore, list *bookmarks) {
+	s.AddTool(createExportReportTool(), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleExportReportRequest(ctx, request, config, store, list)
+	})
+}
+
+// createExportReportTool creates and configures the export_report tool.
+func createExportReportTool() mcp.Tool {
+	return mcp.NewTool("export_report",
+		mcp.WithDescription("Compile this session's searches, their top results and the sources bookmarked from them into a Markdown research report with numbered citations"),
+		mcp.WithString("title",
+			mcp.Description("Report title (default: based on the first query)"),
+		),
+		mcp.WithBoolean("save",
+			mcp.Description("Also save the report as a Markdown file in the server's report directory (default false)"),
+		),
+	)
+}
+
+// handleExportReportRequest processes an export_report tool request.
+func handleExportReportRequest(ctx context.Context,
+	request mcp.CallToolRequest,
+	config *Config,
+	store *resultStore,
+	list *bookmarks,
+) (*mcp.CallToolResult, error) {
+	// Extract and validate title parameter
+	title, err := extractTerms(request.Params.Arguments, "title")
+	if err != nil {
+		return nil, err
+	}
+
+	save, _ := request.Params.Arguments["save"].(bool)
+	if save && config.ReportDir == "" {
+		return nil, fmt.Errorf("save is not available: GOOGLE_SEARCH_REPORT_DIR is not set on this server")
+	}
+
+	searches := store.sessionSearches(sessionOwner(ctx))
+	if len(searches) == 0 {
+		return nil, fmt.Errorf("there are no searches in this session to report on")
+	}
+
+	// Bookmarks are optional in a report; without a cache directory there are none
+	entries, err := list.list(sessionOwner(ctx))
+	if err != nil && !errors.Is(err, errBookmarksUnavailable) {
+		return nil, fmt.Errorf("failed to read bookmarks: %v", err)
+	}
+
+	if title == "" {
+		title = "Research report: " + searches[0].query
+	}
+
+	now := time.Now().UTC()
+	report := formatReport(title, searches, sessionBookmarks(entries, searches), now)
+
+	if !save {
+		return mcp.NewToolResultText(report), nil
+	}
+
+	path, err := saveReport(config.ReportDir, title, report, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save report: %v", err)
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Saved to %s\n\n%s", path, report)), nil
+}
+
+// sessionBookmarks returns the bookmarks made from searches.
+func sessionBookmarks(entries []bookmark, searches []storedSearch) []bookmark {
+	ids := make(map[string]bool, len(searches))
+	for _, search := range searches {
+		ids[search.id] = true
+	}
+
+	var matched []bookmark
+
+	for _, entry := range entries {
+		if ids[entry.SearchID] {
+			matched = append(matched, entry)
+		}
+	}
+
+	return matched
+}
+
+// formatReport renders the research report as Markdown.
+func formatReport(title string, searches []storedSearch, marked []bookmark, now time.Time) string {
+	var (
+		sb    strings.Builder
+		cited citations
+	)
+
+	fmt.Fprintf(&sb, "# %s\n\n", title)
+	fmt.Fprintf(&sb, "_Generated %s from %d searches and %d bookmarked sources._\n\n",
+		now.Format("2006-01-02 15:04 MST"), len(searches), len(marked))
+
+	sb.WriteString("## Queries\n\n")
+
+	for i, search := range searches {
+		fmt.Fprintf(&sb, "%d. %s (%d results, %s)\n", i+1, search.query, len(search.results), search.created.UTC().Format("15:04"))
+	}
+
+	sb.WriteString("\n## Findings\n")
+
+	for _, search := range searches {
+		fmt.Fprintf(&sb, "\n### %s\n\n", search.query)
+
+		for _, result := range search.results[:min(len(search.results), reportResultsPerSearch)] {
+			fmt.Fprintf(&sb, "- **%s**: %s [%d]\n", result.Title, result.Snippet, cited.cite(result))
+		}
+	}
+
+	if len(marked) > 0 {
+		sb.WriteString("\n## Bookmarked sources\n\n")
+
+		for _, entry := range marked {
+			n := cited.cite(GoogleSearchResult{Title: entry.Title, Link: entry.Link})
+
+			if entry.Note != "" {
+				fmt.Fprintf(&sb, "- **%s** [%d]: %s\n", entry.Title, n, entry.Note)
+			} else {
+				fmt.Fprintf(&sb, "- **%s** [%d]\n", entry.Title, n)
+			}
+		}
+	}
+
+	sb.WriteString("\n## Citations\n\n")
+
+	for i, source := range cited.sources {
+		fmt.Fprintf(&sb, "%d. %s. <%s>\n", i+1, source.Title, source.Link)
+	}
+
+	return sb.String()
+}
+
+// saveReport writes report to dir under a name made of the time and title,
+// and returns its path.
+func saveReport(dir, title, report string, now time.Time) (string, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, now.Format("20060102-150405")+"-"+reportSlug(title)+".md")
+
+	if err := os.WriteFile(path, []byte(report), 0o600); err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
+
+// reportSlug turns a title into a short file name part.
+func reportSlug(title string) string {
+	slug := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+
+		return '-'
+	}, title)
+
+	slug = strings.Join(strings.FieldsFunc(slug, func(r rune) bool { return r == '-' }), "-")
+	if runes := []rune(slug); len(runes) > 50 {
+		slug = strings.TrimRight(string(runes[:50]), "-")
+	}
+
+	if slug == "" {
+		slug = "report"
+	}
+
+	return slug
+}
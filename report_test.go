@@ -0,0 +1,55 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReportGolden(t *testing.T) {
+	at := time.Date(2026, 3, 14, 9, 30, 0, 0, time.UTC)
+
+	searches := []storedSearch{
+		{id: "a1", query: "go generics", created: at, results: []GoogleSearchResult{
+			{Title: "Tutorial: Getting started with generics", Link: "https://go.dev/doc/tutorial/generics", Snippet: "This tutorial introduces the basics of generics in Go."},
+			{Title: "An Introduction To Generics", Link: "https://go.dev/blog/intro-generics", Snippet: "Generics add three new big things to the language."},
+		}},
+		{id: "b2", query: "go generics performance", created: at.Add(5 * time.Minute), results: []GoogleSearchResult{
+			{Title: "An Introduction To Generics", Link: "https://go.dev/blog/intro-generics", Snippet: "Generics add three new big things to the language."},
+			{Title: "Generics can make your Go code slower", Link: "https://planetscale.com/blog/generics-can-make-your-go-code-slower", Snippet: "Go 1.18 is here, and with it, the first release of generics."},
+		}},
+	}
+
+	marked := sessionBookmarks([]bookmark{
+		{SearchID: "b2", Title: "Generics can make your Go code slower", Link: "https://planetscale.com/blog/generics-can-make-your-go-code-slower", Note: "Benchmarks of GC shape stenciling"},
+		{SearchID: "other-session", Title: "Unrelated", Link: "https://example.com/"},
+		{SearchID: "a1", Title: "The Go Programming Language Specification", Link: "https://go.dev/ref/spec"},
+	}, searches)
+
+	checkGolden(t, "report.golden", formatReport("Generics in Go", searches, marked, at.Add(time.Hour)))
+}
+
+func TestSaveReport(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "reports")
+	at := time.Date(2026, 3, 14, 9, 30, 0, 0, time.UTC)
+
+	path, err := saveReport(dir, "Research report: Go / Rust — 2026?", "# Report\n", at)
+	if err != nil {
+		t.Fatalf("saveReport: %v", err)
+	}
+
+	if want := filepath.Join(dir, "20260314-093000-research-report-go-rust-2026.md"); path != want {
+		t.Errorf("path = %s, want %s", path, want)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "# Report\n" {
+		t.Errorf("saved report = %q (%v)", data, err)
+	}
+
+	if slug := reportSlug(strings.Repeat("word ", 20)); len(slug) > 50 || strings.HasSuffix(slug, "-") {
+		t.Errorf("long title slug = %q", slug)
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strings"
+)
+
+// trackingParams are query parameters that tag a visit's origin without
+// changing the page, so they don't change a result's identity.
+var trackingParams = map[string]bool{
+	"gclid": true, "fbclid": true, "msclkid": true, "mc_cid": true, "mc_eid": true, "ref_src": true,
+}
+
+// canonicalURL normalizes link so that URLs of the same page compare equal:
+// the scheme, a www. prefix, default ports, the fragment, a trailing slash,
+// tracking parameters and the parameter order are ignored. Links that don't
+// parse as absolute URLs are returned trimmed.
+func canonicalURL(link string) string {
+	link = strings.TrimSpace(link)
+
+	u, err := url.Parse(link)
+	if err != nil || u.Host == "" {
+		return link
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	if port := u.Port(); port != "" && port != "80" && port != "443" {
+		host += ":" + port
+	}
+
+	query := u.Query()
+	for name := range query {
+		if trackingParams[strings.ToLower(name)] || strings.HasPrefix(strings.ToLower(name), "utm_") {
+			query.Del(name)
+		}
+	}
+
+	canonical := host + strings.TrimSuffix(u.EscapedPath(), "/")
+	if encoded := query.Encode(); encoded != "" {
+		canonical += "?" + encoded
+	}
+
+	return canonical
+}
+
+// resultID returns the stable ID of the page at link, a hash of its
+// canonical URL. The same page gets the same ID in every search, session
+// and server run.
+func resultID(link string) string {
+	sum := sha256.Sum256([]byte(canonicalURL(link)))
+
+	return hex.EncodeToString(sum[:8])
+}
+
+// assignResultIDs sets the ID of every result.
+func assignResultIDs(results []GoogleSearchResult) {
+	for i := range results {
+		results[i].ID = resultID(results[i].Link)
+	}
+}
+
+// resultIDs returns the IDs of results, in order.
+func resultIDs(results []GoogleSearchResult) []string {
+	ids := make([]string, 0, len(results))
+	for _, result := range results {
+		ids = append(ids, result.ID)
+	}
+
+	return ids
+}
@@ -0,0 +1,31 @@
+package main
+
+import "testing"
+
+func TestCanonicalURL(t *testing.T) {
+	tests := []struct {
+		link, want string
+	}{
+		{"https://www.Example.com/Docs/", "example.com/Docs"},
+		{"http://example.com:80/a?b=2&a=1#section", "example.com/a?a=1&b=2"},
+		{"https://example.com:8443/a", "example.com:8443/a"},
+		{"https://example.com/a?utm_source=news&UTM_Medium=mail&gclid=x&id=7", "example.com/a?id=7"},
+		{"https://example.com/", "example.com"},
+		{"https://example.com/a%2Fb", "example.com/a%2Fb"},
+		{" not a url ", "not a url"},
+	}
+
+	for _, tt := range tests {
+		if got := canonicalURL(tt.link); got != tt.want {
+			t.Errorf("canonicalURL(%q) = %q, want %q", tt.link, got, tt.want)
+		}
+	}
+
+	if resultID("http://www.example.com/a/?utm_campaign=x") != resultID("https://example.com/a") {
+		t.Error("equivalent URLs have different result IDs")
+	}
+
+	if resultID("https://example.com/a") == resultID("https://example.com/b") {
+		t.Error("different pages share a result ID")
+	}
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// maxStoredSearches bounds the google_search results kept for get_result and
+// open_result; the least recently used search is forgotten at the limit.
+const maxStoredSearches = 200
+
+// storedSearch is the result list of one google_search call.
+type storedSearch struct {
+	// owner is the MCP session that made the call; other sessions can't see it.
+	owner    string
+	id       string
+	query    string
+	results  []GoogleSearchResult
+	created  time.Time
+	lastUsed time.Time
+}
+
+// storedResult is one stored result as get_result returns it. Rank is zero
+// for a result found only among the bookmarks.
+type storedResult struct {
+	SearchID string             `json:"search_id,omitempty"`
+	Query    string             `json:"query"`
+	Rank     int                `json:"rank,omitempty"`
+	Result   GoogleSearchResult `json:"result"`
+}
+
+// resultStore keeps recent google_search results under a search ID, so
+// follow-up calls can refer to "result 3 of that search" instead of
+// resending URLs. It is safe for concurrent use.
+type resultStore struct {
+	mu       sync.Mutex
+	max      int
+	searches map[string]*storedSearch
+}
+
+// newResultStore creates a store remembering at most max searches.
+func newResultStore(max int) *resultStore {
+	return &resultStore{
+		max:      max,
+		searches: make(map[string]*storedSearch),
+	}
+}
+
+// add stores a copy of results for owner and returns their new search ID.
+func (s *resultStore) add(owner, query string, results []GoogleSearchResult) string {
+	id := newSearchID()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.searches) >= s.max {
+		s.evictOldest()
+	}
+
+	now := time.Now()
+	s.searches[id] = &storedSearch{
+		owner:    owner,
+		id:       id,
+		query:    query,
+		results:  append([]GoogleSearchResult(nil), results...),
+		created:  now,
+		lastUsed: now,
+	}
+
+	return id
+}
+
+// get returns the result at the 1-based rank of owner's search id.
+func (s *resultStore) get(owner, id string, rank int) (storedResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	search, ok := s.searches[id]
+	if !ok || search.owner != owner {
+		return storedResult{}, fmt.Errorf("unknown search_id %q; it may have expired, so search again", id)
+	}
+
+	if rank < 1 || rank > len(search.results) {
+		return storedResult{}, fmt.Errorf("rank must be between 1 and %d for search_id %q", len(search.results), id)
+	}
+
+	search.lastUsed = time.Now()
+
+	return storedResult{SearchID: id, Query: search.query, Rank: rank, Result: search.results[rank-1]}, nil
+}
+
+// find returns the result with the stable ID id from owner's most recent
+// search that has it.
+func (s *resultStore) find(owner, id string) (storedResult, bool) {
+	searches := s.sessionSearches(owner)
+
+	for i := len(searches) - 1; i >= 0; i-- {
+		for rank, result := range searches[i].results {
+			if result.ID == id {
+				return storedResult{SearchID: searches[i].id, Query: searches[i].query, Rank: rank + 1, Result: result}, true
+			}
+		}
+	}
+
+	return storedResult{}, false
+}
+
+// sessionSearches returns owner's stored searches, oldest first.
+func (s *resultStore) sessionSearches(owner string) []storedSearch {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var searches []storedSearch
+
+	for _, search := range s.searches {
+		if search.owner == owner {
+			searches = append(searches, *search)
+		}
+	}
+
+	sort.Slice(searches, func(i, j int) bool {
+		return searches[i].created.Before(searches[j].created)
+	})
+
+	return searches
+}
+
+// evictOldest forgets the least recently used search. Callers must hold mu.
+func (s *resultStore) evictOldest() {
+	var (
+		oldestID string
+		oldest   time.Time
+	)
+
+	for id, search := range s.searches {
+		if oldestID == "" || search.lastUsed.Before(oldest) {
+			oldestID, oldest = id, search.lastUsed
+		}
+	}
+
+	delete(s.searches, oldestID)
+}
+
+// newSearchID returns a random search ID, so IDs neither repeat across
+// restarts nor can be guessed by other sessions.
+func newSearchID() string {
+	var b [8]byte
+
+	// Read never fails as of Go 1.24
+	_, _ = rand.Read(b[:])
+
+	return hex.EncodeToString(b[:])
+}
+
+// sessionOwner identifies the MCP session of a tool call, or "" if there is
+// none, to scope stored results to the client that made the search.
+func sessionOwner(ctx context.Context) string {
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		return session.SessionID()
+	}
+
+	return ""
+}
+
+// storedResultOptions are the arguments selecting a stored result, shared
+// by the follow-up tools.
+func storedResultOptions() []mcp.ToolOption {
+	return []mcp.ToolOption{
+		mcp.WithString("search_id",
+			mcp.Description("The search_id reported with the search results; pass rank with it"),
+		),
+		mcp.WithNumber("rank",
+			mcp.Description("1-based position of the result in that search's output"),
+		),
+		mcp.WithString("result_id",
+			mcp.Description("A result's stable ID instead of search_id and rank; valid for any search in this session and for the results it bookmarked"),
+		),
+	}
+}
+
+// extractStoredResult extracts and validates the search_id and rank, or
+// result_id, parameters and looks the result up. A result_id is looked up in
+// the caller's searches, newest first, and then among the caller's
+// bookmarks.
+func extractStoredResult(ctx context.Context, arguments map[string]interface{}, store *resultStore, list *bookmarks) (storedResult, error) {
+	if resultID, ok := arguments["result_id"].(string); ok && resultID != "" {
+		if stored, ok := store.find(sessionOwner(ctx), resultID); ok {
+			return stored, nil
+		}
+
+		if entry, ok := list.find(sessionOwner(ctx), resultID); ok {
+			return entry.storedResult(), nil
+		}
+
+		return storedResult{}, fmt.Errorf("unknown result_id %q; it is in neither this session's searches nor its bookmarks", resultID)
+	}
+
+	id, _ := arguments["search_id"].(string)
+	if id == "" {
+		return storedResult{}, fmt.Errorf("search_id and rank, or result_id, are required")
+	}
+
+	rank, ok := arguments["rank"].(float64)
+	if !ok || rank != float64(int(rank)) {
+		return storedResult{}, fmt.Errorf("rank must be a whole number")
+	}
+
+	return store.get(sessionOwner(ctx), id, int(rank))
+}
+
+// registerGetResultTool creates and registers the get_result tool with the server.
+func registerGetResultTool(s *server.MCPServer, store *resultStore, list *bookmarks) {
+	s.AddTool(createGetResultTool(), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleGetResultRequest(ctx, request, store, list)
+	})
+}
+
+// createGetResultTool creates and configures the get_result tool.
+func createGetResultTool() mcp.Tool {
+	options := append([]mcp.ToolOption{
+		mcp.WithDescription("Return one result of an earlier search in full, as JSON, including the untrimmed snippet and the structured data Google extracted from the page"),
+	}, storedResultOptions()...)
+
+	return mcp.NewTool("get_result", options...)
+}
+
+// handleGetResultRequest processes a get_result tool request.
+func handleGetResultRequest(ctx context.Context,
+	request mcp.CallToolRequest,
+	store *resultStore,
+	list *bookmarks,
+) (*mcp.CallToolResult, error) {
+	stored, err := extractStoredResult(ctx, request.Params.Arguments, store, list)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode result: %v", err)
+	}
+
+	return mcp.NewToolResultText(string(data)), nil
+}
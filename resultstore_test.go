@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/habuvo/mcp-internet-search/searchtest"
+)
+
+func TestResultStoreFollowUps(t *testing.T) {
+	page := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/article":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte(`<html><head><title>The Article</title><style>p { color: red }</style></head>
+<body><script>track()</script><h1>Heading</h1><p>First &amp; foremost.</p><p>Second   paragraph.</p></body></html>`))
+		case "/report.pdf":
+			w.Header().Set("Content-Type", "application/pdf")
+			w.Write([]byte("%PDF-1.7"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(page.Close)
+
+	api := newFakeSearchAPI()
+	api.Respond(func(params url.Values) []searchtest.Result {
+		results := searchtest.Results(params.Get("q"), 3)
+		results[0].Link = page.URL + "/article"
+		results[1].Link = page.URL + "/report.pdf"
+		results[2].Link = page.URL + "/gone"
+
+		return results
+	})
+
+	// Two clients of one server, to check that searches are per session
+	ts := server.NewTestServer(newConformanceServer(t, api))
+	t.Cleanup(ts.Close)
+
+	c := connectClient(t, ts.URL)
+	other := connectClient(t, ts.URL)
+
+	result, err := callTool(t, c, "google_search", map[string]interface{}{"query": "golang", "output_format": outputJSON})
+	if err != nil {
+		t.Fatalf("google_search: %v", err)
+	}
+
+	var response searchResponse
+	if err := json.Unmarshal([]byte(resultText(t, result)), &response); err != nil {
+		t.Fatalf("JSON output doesn't decode: %v", err)
+	}
+
+	searchID := response.Metadata.SearchID
+	if searchID == "" {
+		t.Fatal("google_search reported no search_id")
+	}
+
+	// Every call gets its own ID, even for the same query
+	result, err = callTool(t, c, "google_search", map[string]interface{}{"query": "golang"})
+	if err != nil {
+		t.Fatalf("google_search (text): %v", err)
+	}
+
+	if text := resultText(t, result); !strings.Contains(text, "search_id: ") || strings.Contains(text, searchID) {
+		t.Errorf("text footer lacks a new search_id:\n%s", text)
+	}
+
+	result, err = callTool(t, c, "get_result", map[string]interface{}{"search_id": searchID, "rank": float64(2)})
+	if err != nil {
+		t.Fatalf("get_result: %v", err)
+	}
+
+	var stored storedResult
+	if err := json.Unmarshal([]byte(resultText(t, result)), &stored); err != nil {
+		t.Fatalf("get_result output doesn't decode: %v", err)
+	}
+
+	if stored.Query != "golang" || stored.Rank != 2 || stored.Result.Title != "golang result 2" {
+		t.Errorf("get_result = %+v", stored)
+	}
+
+	// The same result by its stable ID, which the search output shows
+	if id := response.Results[1].ID; id != resultID(page.URL+"/report.pdf") {
+		t.Errorf("result ID = %q", id)
+	}
+
+	result, err = callTool(t, c, "get_result", map[string]interface{}{"result_id": response.Results[1].ID})
+	if err != nil {
+		t.Fatalf("get_result by result_id: %v", err)
+	}
+
+	// It comes from the newest search that has it, the text one
+	if err := json.Unmarshal([]byte(resultText(t, result)), &stored); err != nil || stored.Rank != 2 || stored.SearchID == searchID {
+		t.Errorf("get_result by result_id = %+v (%v)", stored, err)
+	}
+
+	result, err = callTool(t, c, "open_result", map[string]interface{}{"search_id": searchID, "rank": float64(1)})
+	if err != nil {
+		t.Fatalf("open_result: %v", err)
+	}
+
+	want := "The Article\n" + page.URL + "/article\n\nHeading\nFirst & foremost.\nSecond paragraph.\n"
+	if text := resultText(t, result); text != want {
+		t.Errorf("open_result = %q, want %q", text, want)
+	}
+
+	tests := []struct {
+		name string
+		c    *client.SSEMCPClient
+		tool string
+		args map[string]interface{}
+		want string
+	}{
+		{"no selection", c, "get_result", map[string]interface{}{"rank": float64(1)}, "search_id and rank, or result_id"},
+		{"unknown result", c, "get_result", map[string]interface{}{"result_id": "0123456789abcdef"}, "unknown result_id"},
+		{"unknown search", c, "get_result", map[string]interface{}{"search_id": "0123456789abcdef", "rank": float64(1)}, "unknown search_id"},
+		{"rank out of range", c, "get_result", map[string]interface{}{"search_id": searchID, "rank": float64(4)}, "between 1 and 3"},
+		{"fractional rank", c, "get_result", map[string]interface{}{"search_id": searchID, "rank": 1.5}, "whole number"},
+		{"not html", c, "open_result", map[string]interface{}{"search_id": searchID, "rank": float64(2)}, "application/pdf"},
+		{"missing page", c, "open_result", map[string]interface{}{"search_id": searchID, "rank": float64(3)}, "404 Not Found"},
+		{"other session", other, "get_result", map[string]interface{}{"search_id": searchID, "rank": float64(1)}, "unknown search_id"},
+		{"other session by ID", other, "get_result", map[string]interface{}{"result_id": response.Results[0].ID}, "unknown result_id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := callTool(t, tt.c, tt.tool, tt.args)
+			if err == nil {
+				t.Fatalf("want an error, got %+v", result)
+			}
+
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error %q doesn't mention %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// rightsLicenses are the license filters the rights parameter accepts.
+var rightsLicenses = []string{
+	"cc_publicdomain", "cc_attribute", "cc_sharealike", "cc_noncommercial", "cc_nonderived",
+}
+
+// extractRights extracts and validates the rights parameter, given as one
+// license or an array of licenses. Several licenses match results carrying
+// any of them.
+func extractRights(arguments map[string]interface{}) (string, error) {
+	var licenses []string
+
+	switch raw := arguments["rights"].(type) {
+	case nil:
+		return "", nil
+	case string:
+		licenses = strings.FieldsFunc(raw, func(r rune) bool { return r == ',' || r == '|' || r == ' ' })
+	case []interface{}:
+		for _, item := range raw {
+			license, ok := item.(string)
+			if !ok {
+				return "", fmt.Errorf("rights must be a license or an array of licenses")
+			}
+
+			licenses = append(licenses, license)
+		}
+	default:
+		return "", fmt.Errorf("rights must be a license or an array of licenses")
+	}
+
+	for _, license := range licenses {
+		if !slices.Contains(rightsLicenses, license) {
+			return "", fmt.Errorf("unknown rights value %q (want %s)", license, strings.Join(rightsLicenses, ", "))
+		}
+	}
+
+	return strings.Join(licenses, "|"), nil
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// SafeSearch levels accepted by the safe parameter.
+const (
+	safeOff    = "off"
+	safeActive = "active"
+)
+
+// loadSafeSearch reads the server-wide SafeSearch default from
+// GOOGLE_SEARCH_SAFE. Unset leaves the choice to the API, which doesn't filter.
+func loadSafeSearch() (string, error) {
+	safe := os.Getenv("GOOGLE_SEARCH_SAFE")
+	if safe == "" || safe == safeOff || safe == safeActive {
+		return safe, nil
+	}
+
+	return "", fmt.Errorf("GOOGLE_SEARCH_SAFE must be %s or %s, got %q", safeOff, safeActive, safe)
+}
+
+// extractSafeSearch extracts and validates the safe parameter, falling back
+// to the server default.
+func extractSafeSearch(arguments map[string]interface{}, defaultSafe string) (string, error) {
+	raw, ok := arguments["safe"]
+	if !ok {
+		return defaultSafe, nil
+	}
+
+	safe, _ := raw.(string)
+	if safe != safeOff && safe != safeActive {
+		return "", fmt.Errorf("safe must be %s or %s", safeOff, safeActive)
+	}
+
+	return safe, nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Weights of the two relevance signals; they sum to 1 so scores stay in [0, 1].
+const (
+	rankWeight    = 0.5
+	overlapWeight = 0.5
+)
+
+// scoreResults sets a relevance score on each result, combining a decay over
+// Google's rank with the share of query terms found in the title and snippet.
+// offset is the number of results ranked above the first one (for later pages).
+func scoreResults(query string, results []GoogleSearchResult, offset int) {
+	terms := uniqueTerms(query)
+
+	for i := range results {
+		rank := 1 / (1 + 0.2*float64(offset+i))
+
+		overlap := 0.0
+		if len(terms) > 0 {
+			found := uniqueTerms(results[i].Title + " " + results[i].Snippet)
+
+			matched := 0
+			for term := range terms {
+				if found[term] {
+					matched++
+				}
+			}
+
+			overlap = float64(matched) / float64(len(terms))
+		}
+
+		score := rankWeight*rank + overlapWeight*overlap
+		results[i].Score = math.Round(score*1000) / 1000
+	}
+}
+
+// uniqueTerms splits text into the set of its lowercase words, ignoring
+// punctuation and single-character tokens.
+func uniqueTerms(text string) map[string]bool {
+	terms := make(map[string]bool)
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	for _, word := range words {
+		if len([]rune(word)) > 1 {
+			terms[word] = true
+		}
+	}
+
+	return terms
+}
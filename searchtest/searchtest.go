@@ -0,0 +1,219 @@
+// Package searchtest provides a stand-in for the Google Custom Search JSON
+// API, so code that calls the API (or runs this server against it) can be
+// tested without network access or quota.
+package searchtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Result is one search result as the API returns it.
+type Result struct {
+	Title       string `json:"title"`
+	Link        string `json:"link"`
+	Snippet     string `json:"snippet"`
+	DisplayLink string `json:"displayLink"`
+	// Image is set on image search results.
+	Image *Image `json:"image,omitempty"`
+}
+
+// Image is the image block of an image search result.
+type Image struct {
+	ContextLink     string `json:"contextLink"`
+	Height          int    `json:"height"`
+	Width           int    `json:"width"`
+	ByteSize        int    `json:"byteSize"`
+	ThumbnailLink   string `json:"thumbnailLink"`
+	ThumbnailHeight int    `json:"thumbnailHeight"`
+	ThumbnailWidth  int    `json:"thumbnailWidth"`
+}
+
+// Results returns n canned results for query on example.com, numbered from 1.
+func Results(query string, n int) []Result {
+	return ResultsFrom(query, 1, n)
+}
+
+// ResultsFrom returns n canned results for query on example.com, numbered
+// from first, as the page of results starting at first.
+func ResultsFrom(query string, first, n int) []Result {
+	results := make([]Result, n)
+	for i := range results {
+		results[i] = Result{
+			Title:       fmt.Sprintf("%s result %d", query, first+i),
+			Link:        fmt.Sprintf("https://example.com/%d", first+i),
+			Snippet:     fmt.Sprintf("Snippet %d about %s.", first+i, query),
+			DisplayLink: "example.com",
+		}
+	}
+
+	return results
+}
+
+// API is a fake Custom Search API. By default it answers every query with
+// as many canned results as the num parameter asks for (10 if unset),
+// numbered from the start parameter, and links a full page to the next one
+// in queries.nextPage. Like the real API, it rejects num above 10. It is safe
+// for concurrent use.
+type API struct {
+	// APIKey and EngineID, if set, are the only key and cx accepted; other
+	// requests fail with 400 like an invalid key does.
+	APIKey   string
+	EngineID string
+
+	mu        sync.Mutex
+	respond   func(params url.Values) []Result
+	spellings map[string]string
+	failCode  int
+	failError string
+	requests  []url.Values
+}
+
+// NewAPI returns a fake API serving canned results.
+func NewAPI() *API {
+	return &API{}
+}
+
+// Start serves the API over HTTP until the test ends and returns the
+// server; requests go to its URL.
+func (a *API) Start(tb testing.TB) *httptest.Server {
+	tb.Helper()
+
+	server := httptest.NewServer(a)
+	tb.Cleanup(server.Close)
+
+	return server
+}
+
+// Respond makes the API answer with the results respond returns for the
+// request's parameters (q, num, start, ...). An empty slice means no results.
+func (a *API) Respond(respond func(params url.Values) []Result) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.respond = respond
+}
+
+// Correct makes the API suggest a spelling correction for each query in
+// corrections, mapping the query to its corrected form, as the spelling
+// block of the response.
+func (a *API) Correct(corrections map[string]string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.spellings = corrections
+}
+
+// Fail makes every following request fail with status and an error body
+// carrying message, the way the API reports quota and backend errors.
+// Fail(0, "") restores normal answers.
+func (a *API) Fail(status int, message string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.failCode, a.failError = status, message
+}
+
+// Requests returns the query parameters of every request received so far.
+func (a *API) Requests() []url.Values {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return append([]url.Values(nil), a.requests...)
+}
+
+// ServeHTTP implements http.Handler.
+func (a *API) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	a.mu.Lock()
+	a.requests = append(a.requests, params)
+	respond, spellings, failCode, failError := a.respond, a.spellings, a.failCode, a.failError
+	a.mu.Unlock()
+
+	if (a.APIKey != "" && params.Get("key") != a.APIKey) || (a.EngineID != "" && params.Get("cx") != a.EngineID) {
+		writeError(w, http.StatusBadRequest, "API key not valid. Please pass a valid API key.")
+
+		return
+	}
+
+	if failCode != 0 {
+		writeError(w, failCode, failError)
+
+		return
+	}
+
+	if num, err := strconv.Atoi(params.Get("num")); err == nil && (num < 1 || num > 10) {
+		writeError(w, http.StatusBadRequest, "Invalid Value")
+
+		return
+	}
+
+	num, err := strconv.Atoi(params.Get("num"))
+	if err != nil {
+		num = 10
+	}
+
+	start, err := strconv.Atoi(params.Get("start"))
+	if err != nil {
+		start = 1
+	}
+
+	var results []Result
+	if respond != nil {
+		results = respond(params)
+	} else {
+		results = ResultsFrom(params.Get("q"), start, num)
+	}
+
+	body := map[string]interface{}{
+		"kind": "customsearch#search",
+		"searchInformation": map[string]interface{}{
+			"searchTime":   0.25,
+			"totalResults": strconv.Itoa(len(results)),
+		},
+	}
+
+	// Like the real API, omit items entirely when there are no results
+	if len(results) > 0 {
+		body["items"] = results
+	}
+
+	if corrected, ok := spellings[params.Get("q")]; ok {
+		body["spelling"] = map[string]string{"correctedQuery": corrected, "htmlCorrectedQuery": corrected}
+	}
+
+	// A full page suggests more results follow
+	if len(results) >= num {
+		body["queries"] = map[string]interface{}{
+			"nextPage": []map[string]int{{"startIndex": start + num, "count": num}},
+		}
+	}
+
+	writeJSON(w, http.StatusOK, body)
+}
+
+// writeError writes an API error response.
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":    status,
+			"message": message,
+			"status":  strings.ToUpper(strings.ReplaceAll(http.StatusText(status), " ", "_")),
+		},
+	})
+}
+
+// writeJSON writes v as a JSON response with status.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,63 @@
+package searchtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+// get requests the started API with query and decodes the JSON response.
+func get(t *testing.T, base, query string) (int, map[string]interface{}) {
+	t.Helper()
+
+	resp, err := http.Get(base + "?" + query)
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var body map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	return resp.StatusCode, body
+}
+
+func TestAPI(t *testing.T) {
+	api := NewAPI()
+	api.APIKey, api.EngineID = "key", "cx"
+	server := api.Start(t)
+
+	// Canned results follow num
+	status, body := get(t, server.URL, "key=key&cx=cx&q=go&num=3")
+	if items, _ := body["items"].([]interface{}); status != http.StatusOK || len(items) != 3 {
+		t.Fatalf("status %d, %d items; want 200 with 3 items", status, len(items))
+	}
+
+	// Wrong credentials are rejected
+	if status, _ := get(t, server.URL, "key=other&cx=cx&q=go"); status != http.StatusBadRequest {
+		t.Errorf("bad key: status %d, want 400", status)
+	}
+
+	// Custom answers, including none at all
+	api.Respond(func(url.Values) []Result { return nil })
+
+	if _, body := get(t, server.URL, "key=key&cx=cx&q=go"); body["items"] != nil {
+		t.Errorf("empty answer still has items: %v", body["items"])
+	}
+
+	// Failures carry the API's error shape
+	api.Fail(http.StatusTooManyRequests, "Quota exceeded")
+
+	status, body = get(t, server.URL, "key=key&cx=cx&q=go")
+	apiErr, _ := body["error"].(map[string]interface{})
+	if status != http.StatusTooManyRequests || apiErr["message"] != "Quota exceeded" || apiErr["status"] != "TOO_MANY_REQUESTS" {
+		t.Errorf("failure: status %d, body %v", status, body)
+	}
+
+	if requests := api.Requests(); len(requests) != 4 || requests[0].Get("num") != "3" {
+		t.Errorf("requests = %v", requests)
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/kardianos/service"
+)
+
+const serviceName = "mcp-internet-search"
+
+// program adapts the MCP server to the service manager's start/stop lifecycle.
+type program struct {
+	opts   *Options
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// Start is called by the service manager and must not block.
+func (p *program) Start(_ service.Service) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+
+		if err := run(ctx, p.opts); err != nil {
+			log.Printf("Server error: %v", err)
+		}
+	}()
+
+	return nil
+}
+
+// Stop is called by the service manager and waits for the server to shut down.
+func (p *program) Stop(_ service.Service) error {
+	if p.cancel != nil {
+		p.cancel()
+	}
+	p.wg.Wait()
+
+	return nil
+}
+
+// runServiceCommand handles `service <install|uninstall|start|stop|restart|run>`.
+func runServiceCommand(args []string) error {
+	opts := &Options{Transport: "sse"}
+
+	fs := flag.NewFlagSet("service", flag.ContinueOnError)
+	fs.StringVar(&opts.Addr, "addr", "127.0.0.1:8080", "Listen address for the sse transport")
+	fs.StringVar(&opts.BaseURL, "base-url", "", "Public base URL advertised to sse clients (optional)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s service <install|uninstall|start|stop|restart|run> [flags]\n", serviceName)
+		fs.PrintDefaults()
+	}
+
+	if len(args) == 0 {
+		fs.Usage()
+
+		return fmt.Errorf("missing service action")
+	}
+
+	action := args[0]
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	config, err := serviceConfig(opts)
+	if err != nil {
+		return err
+	}
+
+	if action == "install" && !fileExists(config.EnvVars[configFileEnv]) {
+		fmt.Fprintf(os.Stderr, "Warning: %s doesn't exist; run `%s init` so the service finds its credentials\n", config.EnvVars[configFileEnv], serviceName)
+	}
+
+	svc, err := service.New(&program{opts: opts}, config)
+	if err != nil {
+		return fmt.Errorf("failed to create service: %v", err)
+	}
+
+	if action == "run" {
+		if !service.Interactive() {
+			if err := redirectLogToFile(); err != nil {
+				return err
+			}
+		}
+
+		return svc.Run()
+	}
+
+	if err := service.Control(svc, action); err != nil {
+		return fmt.Errorf("service %s failed: %v", action, err)
+	}
+
+	fmt.Printf("Service %s: %s done\n", serviceName, action)
+
+	return nil
+}
+
+// serviceConfig describes how the service manager should launch the server.
+func serviceConfig(opts *Options) (*service.Config, error) {
+	args := []string{"service", "run", "--addr", opts.Addr}
+	if opts.BaseURL != "" {
+		args = append(args, "--base-url", opts.BaseURL)
+	}
+
+	// Service managers don't inherit the installing shell's environment.
+	// Point the service at the installing user's config file, which only
+	// its owner can read, rather than copying the API key into the unit.
+	path, err := configFilePath()
+	if err != nil {
+		return nil, err
+	}
+
+	path, err = filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve config file path: %v", err)
+	}
+
+	config := &service.Config{
+		Name:        serviceName,
+		DisplayName: "Google Search MCP Server",
+		Description: "MCP server providing Google Custom Search over HTTP/SSE.",
+		Arguments:   args,
+		EnvVars:     map[string]string{configFileEnv: path},
+	}
+
+	// Dependencies are unit file lines for systemd but service names on
+	// Windows and OpenRC
+	if service.Platform() == "linux-systemd" {
+		config.Dependencies = []string{"After=network-online.target"}
+	}
+
+	return config, nil
+}
+
+// redirectLogToFile sends log output to a file in the cache directory, since
+// services on some platforms (notably Windows) have no attached console.
+func redirectLogToFile() error {
+	dir, err := cacheDir()
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(filepath.Join(dir, "service.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open service log: %v", err)
+	}
+
+	log.SetOutput(f)
+
+	return nil
+}
+
+// cacheDir returns the platform-appropriate per-user cache directory for the
+// server (e.g. %LocalAppData% on Windows, ~/.cache on Linux), creating it if needed.
+func cacheDir() (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate cache directory: %v", err)
+	}
+
+	dir := filepath.Join(base, serviceName)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create cache directory: %v", err)
+	}
+
+	return dir, nil
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestServiceConfigKeepsKeyOut points the service at the config file instead
+// of copying the API key into the world-readable service definition.
+func TestServiceConfigKeepsKeyOut(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.env")
+	t.Setenv(configFileEnv, path)
+	t.Setenv("GOOGLE_API_KEY", "secret-key")
+
+	config, err := serviceConfig(&Options{Addr: "127.0.0.1:8080"})
+	if err != nil {
+		t.Fatalf("serviceConfig: %v", err)
+	}
+
+	if len(config.EnvVars) != 1 || config.EnvVars[configFileEnv] != path {
+		t.Errorf("EnvVars = %v, want only %s=%s", config.EnvVars, configFileEnv, path)
+	}
+}
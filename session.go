@@ -0,0 +1,69 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// maxTrackedSessions bounds memory used by session accounting; the least
+// recently active session is forgotten when the limit is reached.
+const maxTrackedSessions = 1000
+
+// sessionEntry is the accumulated usage of one session.
+type sessionEntry struct {
+	apiCalls int
+	lastSeen time.Time
+}
+
+// sessionUsage tracks cumulative API calls per caller-supplied session ID.
+// It is safe for concurrent use.
+type sessionUsage struct {
+	mu       sync.Mutex
+	max      int
+	sessions map[string]*sessionEntry
+}
+
+// newSessionUsage creates a tracker remembering at most max sessions.
+func newSessionUsage(max int) *sessionUsage {
+	return &sessionUsage{
+		max:      max,
+		sessions: make(map[string]*sessionEntry),
+	}
+}
+
+// add records calls against sessionID and returns the session's running total.
+func (u *sessionUsage) add(sessionID string, calls int) int {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	entry, ok := u.sessions[sessionID]
+	if !ok {
+		if len(u.sessions) >= u.max {
+			u.evictOldest()
+		}
+
+		entry = &sessionEntry{}
+		u.sessions[sessionID] = entry
+	}
+
+	entry.apiCalls += calls
+	entry.lastSeen = time.Now()
+
+	return entry.apiCalls
+}
+
+// evictOldest forgets the least recently active session. Callers must hold mu.
+func (u *sessionUsage) evictOldest() {
+	var (
+		oldestID string
+		oldest   time.Time
+	)
+
+	for id, entry := range u.sessions {
+		if oldestID == "" || entry.lastSeen.Before(oldest) {
+			oldestID, oldest = id, entry.lastSeen
+		}
+	}
+
+	delete(u.sessions, oldestID)
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/joho/godotenv"
+)
+
+// configFileEnv names the environment variable that overrides the config file location.
+const configFileEnv = "GOOGLE_SEARCH_CONFIG"
+
+// configFilePath returns the location of the config file: $GOOGLE_SEARCH_CONFIG,
+// or config.env in the per-user config directory.
+func configFilePath() (string, error) {
+	if path := os.Getenv(configFileEnv); path != "" {
+		return path, nil
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config directory: %v", err)
+	}
+
+	return filepath.Join(dir, serviceName, "config.env"), nil
+}
+
+// loadConfigFile fills unset environment variables from the config file, if it exists.
+func loadConfigFile() error {
+	path, err := configFilePath()
+	if err != nil {
+		return err
+	}
+
+	profile := os.Getenv(profileEnv)
+
+	if !fileExists(path) {
+		if profile != "" {
+			return fmt.Errorf("%s selects profile %q, but there is no config file %s", profileEnv, profile, path)
+		}
+
+		return nil
+	}
+
+	values, err := readConfigFile(path, profile)
+	if err != nil {
+		return fmt.Errorf("failed to load config file %s: %v", path, err)
+	}
+
+	// Settings already in the environment win over the file
+	for name, value := range values {
+		if _, ok := os.LookupEnv(name); !ok {
+			os.Setenv(name, value)
+		}
+	}
+
+	return nil
+}
+
+// readConfigFile returns the settings of the config file at path for
+// profile; see configFile.settings.
+func readConfigFile(path, profile string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := parseConfigProfiles(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	return file.settings(profile)
+}
+
+// runInitCommand handles `init`: it interactively collects credentials,
+// verifies them with a test query and writes the config file.
+func runInitCommand(args []string) error {
+	fs := flag.NewFlagSet("init", flag.ContinueOnError)
+	force := fs.Bool("force", false, "Overwrite an existing config file without asking")
+	skipValidation := fs.Bool("skip-validation", false, "Don't run a test query before saving")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	path, err := configFilePath()
+	if err != nil {
+		return err
+	}
+
+	in := bufio.NewReader(os.Stdin)
+
+	if _, err := os.Stat(path); err == nil && !*force {
+		answer, err := prompt(in, fmt.Sprintf("%s already exists. Overwrite? [y/N]: ", path))
+		if err != nil {
+			return err
+		}
+
+		if !strings.EqualFold(answer, "y") && !strings.EqualFold(answer, "yes") {
+			return fmt.Errorf("aborted")
+		}
+	}
+
+	fmt.Println("1. Create an API key at https://console.cloud.google.com/apis/credentials")
+	fmt.Println("   and enable the Custom Search API for the project.")
+
+	apiKey, err := promptRequired(in, "Google API key: ")
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("2. Create a search engine at https://programmablesearchengine.google.com/")
+	fmt.Println("   (enable \"Search the entire web\") and copy its Search engine ID.")
+
+	searchEngineID, err := promptRequired(in, "Search engine ID (cx): ")
+	if err != nil {
+		return err
+	}
+
+	if !*skipValidation {
+		fmt.Println("Running a test query...")
+
+		if _, err := performGoogleSearch(context.Background(), SearchOptions{Query: "test", NumResults: 1}, apiKey, searchEngineID); err != nil {
+			return fmt.Errorf("credentials check failed: %v", err)
+		}
+
+		fmt.Println("Credentials OK.")
+	}
+
+	if err := writeConfigFile(path, map[string]string{
+		"GOOGLE_API_KEY":          apiKey,
+		"GOOGLE_SEARCH_ENGINE_ID": searchEngineID,
+	}); err != nil {
+		return err
+	}
+
+	fmt.Printf("Saved configuration to %s\n", path)
+
+	return nil
+}
+
+// writeConfigFile writes values as a dotenv file readable only by the current user.
+func writeConfigFile(path string, values map[string]string) error {
+	content, err := godotenv.Marshal(values)
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create config directory: %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte(content+"\n"), 0o600); err != nil {
+		return fmt.Errorf("failed to write config file: %v", err)
+	}
+
+	// WriteFile keeps the mode of an existing file, so tighten it explicitly
+	if err := os.Chmod(path, 0o600); err != nil {
+		return fmt.Errorf("failed to set config file permissions: %v", err)
+	}
+
+	return nil
+}
+
+// prompt prints label and reads one trimmed line of input.
+func prompt(in *bufio.Reader, label string) (string, error) {
+	fmt.Print(label)
+
+	line, err := in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", fmt.Errorf("failed to read input: %v", err)
+	}
+
+	return strings.TrimSpace(line), nil
+}
+
+// promptRequired prompts until a non-empty answer is given.
+func promptRequired(in *bufio.Reader, label string) (string, error) {
+	for {
+		answer, err := prompt(in, label)
+		if err != nil {
+			return "", err
+		}
+
+		if answer != "" {
+			return answer, nil
+		}
+	}
+}
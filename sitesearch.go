@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// site_search_filter values and their siteSearchFilter API codes.
+const (
+	siteFilterInclude = "include"
+	siteFilterExclude = "exclude"
+)
+
+// siteFilterCodes maps site_search_filter values to siteSearchFilter codes.
+var siteFilterCodes = map[string]string{
+	siteFilterInclude: "i",
+	siteFilterExclude: "e",
+}
+
+// extractSiteSearch extracts and validates the site_search and
+// site_search_filter parameters into opts.
+func extractSiteSearch(arguments map[string]interface{}, opts *SearchOptions) error {
+	site, _ := arguments["site_search"].(string)
+	site = strings.TrimSpace(site)
+
+	filter, _ := arguments["site_search_filter"].(string)
+
+	if site == "" {
+		if filter != "" {
+			return fmt.Errorf("site_search_filter requires site_search")
+		}
+
+		return nil
+	}
+
+	if strings.ContainsAny(site, " \t\n") {
+		return fmt.Errorf("site_search must be a single site such as example.com or example.com/docs, got %q", site)
+	}
+
+	if filter == "" {
+		filter = siteFilterInclude
+	}
+
+	code, ok := siteFilterCodes[filter]
+	if !ok {
+		return fmt.Errorf("site_search_filter must be %q or %q", siteFilterInclude, siteFilterExclude)
+	}
+
+	opts.SiteSearch = site
+	opts.SiteSearchFilter = code
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// snippetSeparator joins the fragments the CSE stitches into one snippet.
+const snippetSeparator = " ... "
+
+// snippetOptions trades snippet verbosity against token cost. Zero values mean
+// no limit.
+type snippetOptions struct {
+	// Length caps each snippet at this many characters.
+	Length int
+	// Fragments keeps at most this many snippet fragments per result.
+	Fragments int
+}
+
+// loadSnippetOptions reads the server-wide snippet defaults from
+// GOOGLE_SEARCH_SNIPPET_LENGTH and GOOGLE_SEARCH_SNIPPETS_PER_RESULT.
+func loadSnippetOptions() (snippetOptions, error) {
+	var opts snippetOptions
+
+	for _, setting := range []struct {
+		env   string
+		value *int
+	}{
+		{"GOOGLE_SEARCH_SNIPPET_LENGTH", &opts.Length},
+		{"GOOGLE_SEARCH_SNIPPETS_PER_RESULT", &opts.Fragments},
+	} {
+		raw := os.Getenv(setting.env)
+		if raw == "" {
+			continue
+		}
+
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			return opts, fmt.Errorf("%s must be a non-negative integer, got %q", setting.env, raw)
+		}
+
+		*setting.value = n
+	}
+
+	return opts, nil
+}
+
+// extractSnippetOptions applies the snippet_length and snippets_per_result
+// parameters on top of the server defaults.
+func extractSnippetOptions(arguments map[string]interface{}, defaults snippetOptions) (snippetOptions, error) {
+	opts := defaults
+
+	for _, param := range []struct {
+		name  string
+		value *int
+	}{
+		{"snippet_length", &opts.Length},
+		{"snippets_per_result", &opts.Fragments},
+	} {
+		raw, ok := arguments[param.name].(float64)
+		if !ok {
+			continue
+		}
+
+		if raw < 0 || raw != float64(int(raw)) {
+			return opts, fmt.Errorf("%s must be a non-negative integer", param.name)
+		}
+
+		*param.value = int(raw)
+	}
+
+	return opts, nil
+}
+
+// trimSnippets shortens each result's snippet to the configured number of
+// fragments and length.
+func trimSnippets(results []GoogleSearchResult, opts snippetOptions) {
+	for i := range results {
+		results[i].Snippet = trimSnippet(results[i].Snippet, opts)
+	}
+}
+
+// trimSnippet keeps the first opts.Fragments fragments of snippet and cuts it
+// to opts.Length characters at a word boundary, marking any cut with "...".
+func trimSnippet(snippet string, opts snippetOptions) string {
+	if opts.Fragments > 0 {
+		fragments := strings.Split(snippet, snippetSeparator)
+		if len(fragments) > opts.Fragments {
+			snippet = strings.Join(fragments[:opts.Fragments], snippetSeparator) + " ..."
+		}
+	}
+
+	runes := []rune(snippet)
+	if opts.Length <= 0 || len(runes) <= opts.Length {
+		return snippet
+	}
+
+	cut := string(runes[:opts.Length])
+	if space := strings.LastIndex(cut, " "); space > 0 {
+		cut = cut[:space]
+	}
+
+	return strings.TrimRight(cut, " .,;:") + "..."
+}
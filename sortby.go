@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Result orders accepted by sort_by.
+const (
+	sortNewest = "newest"
+	sortOldest = "oldest"
+)
+
+// sortRangeStart starts date ranges given without a from date; no page
+// predates it.
+var sortRangeStart = time.Date(1990, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// sortDateLayouts are the accepted sort_by date formats.
+var sortDateLayouts = []string{"2006-01-02", "20060102"}
+
+// extractSortBy extracts and validates the sort_by parameter, an object with
+// an order and an optional from/to date range, as a sort value such as
+// "date:d,date:r:20240101:20241231". A range missing its end runs until now.
+// A range missing its start reaches back to sortRangeStart.
+func extractSortBy(arguments map[string]interface{}, now time.Time) (string, error) {
+	raw, ok := arguments["sort_by"]
+	if !ok || raw == nil {
+		return "", nil
+	}
+
+	sortBy, ok := raw.(map[string]interface{})
+	if !ok {
+		return "", fmt.Errorf("sort_by must be an object such as {\"order\": \"newest\"}")
+	}
+
+	// Extract and validate order
+	order, _ := sortBy["order"].(string)
+	criteria := []string{"date:d"}
+
+	switch order {
+	case "", sortNewest:
+	case sortOldest:
+		criteria = []string{"date:a"}
+	default:
+		return "", fmt.Errorf("sort_by.order must be %s or %s", sortNewest, sortOldest)
+	}
+
+	// Extract and validate the date range
+	from, err := parseSortDate(sortBy, "from")
+	if err != nil {
+		return "", err
+	}
+
+	to, err := parseSortDate(sortBy, "to")
+	if err != nil {
+		return "", err
+	}
+
+	if from.IsZero() && to.IsZero() {
+		return criteria[0], nil
+	}
+
+	if from.IsZero() {
+		from = sortRangeStart
+	}
+
+	if to.IsZero() {
+		to = now
+	}
+
+	if from.After(to) {
+		return "", fmt.Errorf("sort_by.from must not be after sort_by.to")
+	}
+
+	criteria = append(criteria, fmt.Sprintf("date:r:%s:%s", from.Format("20060102"), to.Format("20060102")))
+
+	return strings.Join(criteria, ","), nil
+}
+
+// parseSortDate parses the named sort_by date, returning the zero time if it
+// is unset.
+func parseSortDate(sortBy map[string]interface{}, name string) (time.Time, error) {
+	value, _ := sortBy[name].(string)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	for _, layout := range sortDateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("sort_by.%s must be a date such as 2024-01-31, got %q", name, value)
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestSpellingCorrection reports the API's spelling correction and, with
+// auto_correct, searches for it instead.
+func TestSpellingCorrection(t *testing.T) {
+	api := newFakeSearchAPI()
+	api.Correct(map[string]string{"golang concurency": "golang concurrency"})
+	c := newConformanceClient(t, api)
+
+	result, err := callTool(t, c, "google_search", map[string]interface{}{"query": "golang concurency"})
+	if err != nil {
+		t.Fatalf("google_search: %v", err)
+	}
+
+	if text := resultText(t, result); !strings.HasPrefix(text, "Did you mean: golang concurrency\n") || !strings.Contains(text, "golang concurency result 1") {
+		t.Errorf("text output lacks the suggestion or the original results:\n%s", text)
+	}
+
+	result, err = callTool(t, c, "google_search", map[string]interface{}{
+		"query":         "golang concurency",
+		"auto_correct":  true,
+		"output_format": outputJSON,
+	})
+	if err != nil {
+		t.Fatalf("google_search with auto_correct: %v", err)
+	}
+
+	var response searchResponse
+	if err := json.Unmarshal([]byte(resultText(t, result)), &response); err != nil {
+		t.Fatalf("JSON output doesn't decode: %v", err)
+	}
+
+	meta := response.Metadata
+	if meta.CorrectedQuery != "golang concurrency" || meta.DidYouMean != "" || meta.APICalls != 2 {
+		t.Errorf("metadata = %+v, want corrected_query golang concurrency after 2 api calls", meta)
+	}
+
+	if response.Results[0].Title != "golang concurrency result 1" {
+		t.Errorf("first result %q, want one for the corrected query", response.Results[0].Title)
+	}
+
+	// Without a suggestion, auto_correct costs nothing extra
+	result, err = callTool(t, c, "google_search", map[string]interface{}{"query": "golang", "auto_correct": true})
+	if err != nil {
+		t.Fatalf("google_search: %v", err)
+	}
+
+	if text := resultText(t, result); strings.Contains(text, "Did you mean") || strings.Contains(text, "instead of") {
+		t.Errorf("text output mentions a correction that wasn't suggested:\n%s", text)
+	}
+}
+
+// TestAutoCorrectErrorKeepsCause lets callers match the cause of a failed
+// auto-corrected search.
+func TestAutoCorrectErrorKeepsCause(t *testing.T) {
+	api := newFakeSearchAPI()
+	api.Correct(map[string]string{"golang concurency": "golang concurrency"})
+	newConformanceServer(t, api)
+
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig: %v", err)
+	}
+
+	searchQuota.Store(&quotaTracker{settings: quotaSettings{Daily: 1}, now: time.Now})
+	t.Cleanup(func() { searchQuota.Store(nil) })
+
+	_, err = searchWithFallbacks(context.Background(), SearchOptions{Query: "golang concurency", NumResults: 5, AutoCorrect: true}, config, nil)
+	if !errors.Is(err, errQuotaExceeded) || isUnavailable(err) {
+		t.Errorf("err = %v, want a quota refusal that isn't an outage", err)
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Custom Search JSON API pricing: the first 100 queries a day are free,
+// further ones cost $5 per 1000.
+const (
+	defaultFreeCallsPerDay = 100
+	defaultPricePer1000    = 5.0
+)
+
+// errSpendCapped matches refusals of paid API calls by the monthly spend cap.
+var errSpendCapped = errors.New("monthly spend cap reached")
+
+// spendCap limits what paid API calls may cost per month.
+type spendCap struct {
+	// Monthly is the most paid calls may cost per month in USD; zero means
+	// no cap.
+	Monthly float64
+	// FreePerDay calls a day are free; the rest cost PricePer1000 USD per
+	// 1000.
+	FreePerDay   int
+	PricePer1000 float64
+}
+
+// cost returns the price of paid calls in USD.
+func (c spendCap) cost(paid int) float64 {
+	return float64(paid) * c.PricePer1000 / 1000
+}
+
+// loadSpendCap reads GOOGLE_SEARCH_MONTHLY_CAP, in USD, and the pricing it
+// is checked against: GOOGLE_SEARCH_FREE_CALLS_PER_DAY and
+// GOOGLE_SEARCH_PRICE_PER_1000, which default to Google's list prices.
+func loadSpendCap() (spendCap, error) {
+	settings := spendCap{FreePerDay: defaultFreeCallsPerDay, PricePer1000: defaultPricePer1000}
+
+	value := os.Getenv("GOOGLE_SEARCH_MONTHLY_CAP")
+	if value != "" {
+		monthly, err := strconv.ParseFloat(value, 64)
+		if err != nil || monthly <= 0 {
+			return spendCap{}, fmt.Errorf("GOOGLE_SEARCH_MONTHLY_CAP must be a positive amount in USD such as 50, got %q", value)
+		}
+
+		settings.Monthly = monthly
+	}
+
+	if free := os.Getenv("GOOGLE_SEARCH_FREE_CALLS_PER_DAY"); free != "" {
+		calls, err := strconv.Atoi(free)
+		if err != nil || calls < 0 {
+			return spendCap{}, fmt.Errorf("GOOGLE_SEARCH_FREE_CALLS_PER_DAY must be a number of API calls, got %q", free)
+		}
+
+		settings.FreePerDay = calls
+	}
+
+	if price := os.Getenv("GOOGLE_SEARCH_PRICE_PER_1000"); price != "" {
+		perThousand, err := strconv.ParseFloat(price, 64)
+		if err != nil || perThousand <= 0 {
+			return spendCap{}, fmt.Errorf("GOOGLE_SEARCH_PRICE_PER_1000 must be a positive amount in USD such as 5, got %q", price)
+		}
+
+		settings.PricePer1000 = perThousand
+	}
+
+	if settings.Monthly == 0 && (os.Getenv("GOOGLE_SEARCH_FREE_CALLS_PER_DAY") != "" || os.Getenv("GOOGLE_SEARCH_PRICE_PER_1000") != "") {
+		return spendCap{}, fmt.Errorf("GOOGLE_SEARCH_FREE_CALLS_PER_DAY and GOOGLE_SEARCH_PRICE_PER_1000 require GOOGLE_SEARCH_MONTHLY_CAP")
+	}
+
+	return settings, nil
+}
+
+// spendCapError refuses a paid API call that would take the month's spend
+// past the cap.
+type spendCapError struct {
+	Cap, Spent float64
+	// FreePerDay calls a day remain available.
+	FreePerDay int
+	// Until is when the next month starts.
+	Until time.Time
+}
+
+// Error implements error.
+func (e *spendCapError) Error() string {
+	return fmt.Sprintf("monthly spend cap of $%.2f reached ($%.2f spent on paid API calls); until %s only the %d free API calls a day are made",
+		e.Cap, e.Spent, e.Until.Format(time.DateOnly), e.FreePerDay)
+}
+
+// Is reports whether target is errSpendCapped or errQuotaExceeded.
+func (e *spendCapError) Is(target error) bool {
+	return target == errSpendCapped || target == errQuotaExceeded
+}
+
+// quotaMonthStart returns the start of the billing month containing t,
+// which follows the quota day.
+func quotaMonthStart(t time.Time) time.Time {
+	local := t.In(quotaResetZone)
+
+	return time.Date(local.Year(), local.Month(), 1, 0, 0, 0, 0, quotaResetZone)
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestSpendCap refuses paid calls past the monthly cap, still makes the
+// free ones, and starts afresh each month.
+func TestSpendCap(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	q := &quotaTracker{
+		// Each paid call costs half a cent, so the cap covers two
+		settings: quotaSettings{Spend: spendCap{Monthly: 0.01, FreePerDay: 2, PricePer1000: 5}},
+		now:      func() time.Time { return now },
+	}
+
+	if err := q.take("google_search", 4); err != nil {
+		t.Fatalf("take 2 free and 2 paid calls: %v", err)
+	}
+
+	err := q.take("google_search", 1)
+
+	var capErr *spendCapError
+	if !errors.As(err, &capErr) || !errors.Is(err, errSpendCapped) || !errors.Is(err, errQuotaExceeded) {
+		t.Fatalf("take past the cap: err = %v, want a spendCapError", err)
+	}
+
+	if capErr.Spent != 0.01 || !strings.Contains(err.Error(), "until 2026-11-01 only the 2 free API calls a day") {
+		t.Errorf("spendCapError = %v", err)
+	}
+
+	// The next day's free calls are still made
+	now = now.AddDate(0, 0, 1)
+	if err := q.take("google_search", 2); err != nil {
+		t.Errorf("take the next day's free calls: %v", err)
+	}
+
+	if err := q.take("google_search", 1); !errors.Is(err, errSpendCapped) {
+		t.Errorf("take a paid call the next day: err = %v, want the cap", err)
+	}
+
+	now = time.Date(2026, 11, 2, 12, 0, 0, 0, time.UTC)
+	if err := q.take("google_search", 4); err != nil {
+		t.Errorf("take paid calls the next month: %v", err)
+	}
+}
+
+// TestSpendCapServesCache answers from recent results once paid calls are
+// refused.
+func TestSpendCapServesCache(t *testing.T) {
+	c := newConformanceClient(t, newFakeSearchAPI())
+	args := map[string]interface{}{"query": "golang", "output_format": outputJSON}
+
+	if _, err := callTool(t, c, "google_search", args); err != nil {
+		t.Fatalf("google_search: %v", err)
+	}
+
+	// No free calls, and a paid one costs more than the cap
+	searchQuota.Store(&quotaTracker{settings: quotaSettings{Spend: spendCap{Monthly: 1, PricePer1000: 5000}}, now: time.Now})
+	t.Cleanup(func() { searchQuota.Store(nil) })
+
+	result, err := callTool(t, c, "google_search", args)
+	if err != nil {
+		t.Fatalf("google_search past the cap: %v", err)
+	}
+
+	var response searchResponse
+	if err := json.Unmarshal([]byte(resultText(t, result)), &response); err != nil {
+		t.Fatalf("JSON output doesn't decode: %v", err)
+	}
+
+	if len(response.Results) == 0 || response.Metadata.StaleSeconds == 0 {
+		t.Errorf("got %d results, stale_seconds %d; want the cached results", len(response.Results), response.Metadata.StaleSeconds)
+	}
+
+	if _, err := callTool(t, c, "google_search", map[string]interface{}{"query": "uncached"}); err == nil || !strings.Contains(err.Error(), "monthly spend cap") {
+		t.Errorf("uncached search past the cap: err = %v", err)
+	}
+}
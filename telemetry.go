@@ -0,0 +1,375 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	telemetryFileName = "telemetry.json"
+	// telemetrySendInterval is how often finished days are sent.
+	telemetrySendInterval = time.Hour
+	telemetryTimeout      = 10 * time.Second
+	// maxTelemetryDays bounds the days kept locally, sent or not.
+	maxTelemetryDays = 30
+	// unknownTool counts calls to tools that don't exist, whose names are
+	// client input and so aren't recorded.
+	unknownTool = "(unknown)"
+)
+
+// loadTelemetry reads GOOGLE_SEARCH_TELEMETRY, which turns on local usage
+// counting, and GOOGLE_SEARCH_TELEMETRY_ENDPOINT, where daily summaries are
+// sent. Telemetry is off unless explicitly enabled.
+func loadTelemetry() (enabled bool, endpoint string, err error) {
+	if value := os.Getenv("GOOGLE_SEARCH_TELEMETRY"); value != "" {
+		enabled, err = strconv.ParseBool(value)
+		if err != nil {
+			return false, "", fmt.Errorf("GOOGLE_SEARCH_TELEMETRY must be true or false, got %q", value)
+		}
+	}
+
+	endpoint = os.Getenv("GOOGLE_SEARCH_TELEMETRY_ENDPOINT")
+	if endpoint == "" {
+		return enabled, "", nil
+	}
+
+	if !enabled {
+		return false, "", fmt.Errorf("GOOGLE_SEARCH_TELEMETRY_ENDPOINT is set, but GOOGLE_SEARCH_TELEMETRY is not true")
+	}
+
+	if u, err := url.Parse(endpoint); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return false, "", fmt.Errorf("GOOGLE_SEARCH_TELEMETRY_ENDPOINT must be an http or https URL, got %q", endpoint)
+	}
+
+	return enabled, endpoint, nil
+}
+
+// toolUsage counts the calls of one tool.
+type toolUsage struct {
+	Calls  int `json:"calls"`
+	Errors int `json:"errors"`
+}
+
+// telemetryDay is the usage summary of one UTC day, as sent to the endpoint.
+// It holds counts only: no queries, URLs, arguments or client details.
+type telemetryDay struct {
+	Date     string                `json:"date"`
+	Version  string                `json:"version"`
+	OS       string                `json:"os"`
+	Arch     string                `json:"arch"`
+	Sessions int                   `json:"sessions"`
+	Tools    map[string]*toolUsage `json:"tools"`
+	// Sent is set once the endpoint accepted the summary; it isn't sent.
+	Sent bool `json:"-"`
+}
+
+// telemetryFile is the on-disk form of the local aggregate.
+type telemetryFile struct {
+	Days []*telemetryDay `json:"days"`
+	// Sent lists the dates already sent, so they aren't sent again.
+	Sent []string `json:"sent,omitempty"`
+}
+
+// telemetry aggregates usage counts per day in the cache directory and
+// sends finished days to the configured endpoint. A nil telemetry records
+// nothing. It is safe for concurrent use.
+type telemetry struct {
+	mu       sync.Mutex
+	path     string
+	endpoint string
+	days     map[string]*telemetryDay
+	now      func() time.Time
+}
+
+// openTelemetry returns the usage aggregate if the configuration enables
+// telemetry, or nil.
+func openTelemetry(config *Config) *telemetry {
+	if !config.Telemetry {
+		return nil
+	}
+
+	t := &telemetry{endpoint: config.TelemetryEndpoint, days: make(map[string]*telemetryDay), now: time.Now}
+
+	dir, err := cacheDir()
+	if err != nil {
+		log.Printf("Telemetry is kept in memory only: %v", err)
+
+		return t
+	}
+
+	t.path = filepath.Join(dir, telemetryFileName)
+	if err := t.load(); err != nil {
+		log.Printf("Starting telemetry afresh: %v", err)
+	}
+
+	return t
+}
+
+// serverOptions returns the hooks counting sessions and tool calls.
+func (t *telemetry) serverOptions() []server.ServerOption {
+	if t == nil {
+		return nil
+	}
+
+	hooks := &server.Hooks{}
+	hooks.AddAfterInitialize(func(_ interface{}, _ *mcp.InitializeRequest, _ *mcp.InitializeResult) {
+		t.record(func(day *telemetryDay) { day.Sessions++ })
+	})
+	hooks.AddAfterCallTool(func(_ interface{}, request *mcp.CallToolRequest, result *mcp.CallToolResult) {
+		t.recordCall(request.Params.Name, result != nil && result.IsError)
+	})
+	hooks.AddOnError(func(_ interface{}, method mcp.MCPMethod, message interface{}, err error) {
+		if method != mcp.MethodToolsCall {
+			return
+		}
+
+		name := unknownTool
+		if request, ok := message.(*mcp.CallToolRequest); ok && request.Params.Name != "" && !errors.Is(err, server.ErrToolNotFound) {
+			name = request.Params.Name
+		}
+
+		t.recordCall(name, true)
+	})
+
+	return []server.ServerOption{server.WithHooks(hooks)}
+}
+
+// recordCall counts one call of the named tool.
+func (t *telemetry) recordCall(name string, failed bool) {
+	t.record(func(day *telemetryDay) {
+		usage, ok := day.Tools[name]
+		if !ok {
+			usage = &toolUsage{}
+			day.Tools[name] = usage
+		}
+
+		usage.Calls++
+		if failed {
+			usage.Errors++
+		}
+	})
+}
+
+// record applies update to today's summary and saves the aggregate.
+func (t *telemetry) record(update func(day *telemetryDay)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	date := t.now().UTC().Format(time.DateOnly)
+
+	day, ok := t.days[date]
+	if !ok {
+		day = &telemetryDay{
+			Date:    date,
+			Version: version,
+			OS:      runtime.GOOS,
+			Arch:    runtime.GOARCH,
+			Tools:   make(map[string]*toolUsage),
+		}
+		t.days[date] = day
+		t.prune()
+	}
+
+	update(day)
+
+	if err := t.save(); err != nil {
+		log.Printf("Failed to save telemetry: %v", err)
+	}
+}
+
+// run sends finished days to the endpoint now and every
+// telemetrySendInterval until ctx is done.
+func (t *telemetry) run(ctx context.Context) {
+	if t.endpoint == "" {
+		return
+	}
+
+	ticker := time.NewTicker(telemetrySendInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := t.send(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Failed to send telemetry: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// send posts each finished, unsent day to the endpoint, oldest first,
+// stopping at the first failure so it is retried later.
+func (t *telemetry) send(ctx context.Context) error {
+	for _, day := range t.pending() {
+		body, err := json.Marshal(day)
+		if err != nil {
+			return fmt.Errorf("failed to encode summary: %v", err)
+		}
+
+		if err := postTelemetry(ctx, t.endpoint, body); err != nil {
+			return err
+		}
+
+		t.markSent(day.Date)
+	}
+
+	return nil
+}
+
+// pending returns copies of the finished days not yet sent, oldest first.
+func (t *telemetry) pending() []telemetryDay {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	today := t.now().UTC().Format(time.DateOnly)
+
+	var days []telemetryDay
+
+	for date, day := range t.days {
+		if date < today && !day.Sent {
+			days = append(days, *day)
+		}
+	}
+
+	sort.Slice(days, func(i, j int) bool { return days[i].Date < days[j].Date })
+
+	return days
+}
+
+// markSent records that date's summary was accepted.
+func (t *telemetry) markSent(date string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if day, ok := t.days[date]; ok {
+		day.Sent = true
+	}
+
+	if err := t.save(); err != nil {
+		log.Printf("Failed to save telemetry: %v", err)
+	}
+}
+
+// postTelemetry sends one summary.
+func postTelemetry(ctx context.Context, endpoint string, body []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, telemetryTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "mcp-internet-search/"+version)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("endpoint returned %s", resp.Status)
+	}
+
+	return nil
+}
+
+// prune forgets the oldest days beyond maxTelemetryDays. Callers must hold mu.
+func (t *telemetry) prune() {
+	dates := make([]string, 0, len(t.days))
+	for date := range t.days {
+		dates = append(dates, date)
+	}
+
+	sort.Strings(dates)
+
+	for _, date := range dates[:max(len(dates)-maxTelemetryDays, 0)] {
+		delete(t.days, date)
+	}
+}
+
+// load reads the aggregate saved by an earlier run.
+func (t *telemetry) load() error {
+	data, err := os.ReadFile(t.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	var file telemetryFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("failed to parse %s: %v", t.path, err)
+	}
+
+	for _, day := range file.Days {
+		if day.Tools == nil {
+			day.Tools = make(map[string]*toolUsage)
+		}
+
+		t.days[day.Date] = day
+	}
+
+	for _, date := range file.Sent {
+		if day, ok := t.days[date]; ok {
+			day.Sent = true
+		}
+	}
+
+	return nil
+}
+
+// save writes the aggregate, replacing the file atomically. Callers must
+// hold mu.
+func (t *telemetry) save() error {
+	if t.path == "" {
+		return nil
+	}
+
+	var file telemetryFile
+
+	for _, day := range t.days {
+		file.Days = append(file.Days, day)
+		if day.Sent {
+			file.Sent = append(file.Sent, day.Date)
+		}
+	}
+
+	sort.Slice(file.Days, func(i, j int) bool { return file.Days[i].Date < file.Days[j].Date })
+	sort.Strings(file.Sent)
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := t.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, t.path)
+}
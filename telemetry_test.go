@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// TestTelemetry counts sessions and tool calls through the server hooks,
+// keeps arguments out of the aggregate and sends each finished day once.
+func TestTelemetry(t *testing.T) {
+	day := time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)
+	usage := &telemetry{
+		path: filepath.Join(t.TempDir(), telemetryFileName),
+		days: make(map[string]*telemetryDay),
+		now:  func() time.Time { return day },
+	}
+
+	s := createServer(usage.serverOptions()...)
+	s.AddTool(mcp.NewTool("echo"), func(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(fmt.Sprint(request.Params.Arguments["query"])), nil
+	})
+	s.AddTool(mcp.NewTool("fail"), func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return nil, fmt.Errorf("failed")
+	})
+
+	ts := server.NewTestServer(s)
+	t.Cleanup(ts.Close)
+
+	c := connectClient(t, ts.URL)
+
+	for _, name := range []string{"echo", "echo", "fail", "no_such_tool"} {
+		_, _ = callTool(t, c, name, map[string]interface{}{"query": "private query"})
+	}
+
+	data, err := os.ReadFile(usage.path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+
+	if strings.Contains(string(data), "private query") || strings.Contains(string(data), "no_such_tool") {
+		t.Errorf("aggregate records client input:\n%s", data)
+	}
+
+	var file telemetryFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if len(file.Days) != 1 {
+		t.Fatalf("aggregate has %d days, want 1:\n%s", len(file.Days), data)
+	}
+
+	got := file.Days[0]
+	if got.Date != "2026-03-14" || got.Version != version || got.Sessions != 1 {
+		t.Errorf("day = %+v", got)
+	}
+
+	want := map[string]toolUsage{"echo": {Calls: 2}, "fail": {Calls: 1, Errors: 1}, unknownTool: {Calls: 1, Errors: 1}}
+	for name, counts := range want {
+		if usage := got.Tools[name]; usage == nil || *usage != counts {
+			t.Errorf("tools[%q] = %+v, want %+v", name, usage, counts)
+		}
+	}
+
+	// Nothing is sent until the day is over
+	var (
+		mu   sync.Mutex
+		sent []telemetryDay
+	)
+
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		var summary telemetryDay
+		if err := json.Unmarshal(body, &summary); err != nil {
+			t.Errorf("endpoint got %s: %v", body, err)
+		}
+
+		mu.Lock()
+		sent = append(sent, summary)
+		mu.Unlock()
+	}))
+	defer endpoint.Close()
+
+	usage.endpoint = endpoint.URL
+
+	if err := usage.send(context.Background()); err != nil || len(sent) != 0 {
+		t.Fatalf("send on the same day: sent %d, err %v", len(sent), err)
+	}
+
+	day = day.Add(24 * time.Hour)
+
+	for range 2 {
+		if err := usage.send(context.Background()); err != nil {
+			t.Fatalf("send: %v", err)
+		}
+	}
+
+	if len(sent) != 1 || sent[0].Date != "2026-03-14" || sent[0].Tools["echo"].Calls != 2 {
+		t.Fatalf("endpoint got %+v, want the 2026-03-14 summary once", sent)
+	}
+
+	// A restarted server doesn't send it again
+	restarted := &telemetry{path: usage.path, endpoint: endpoint.URL, days: make(map[string]*telemetryDay), now: usage.now}
+	if err := restarted.load(); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	if pending := restarted.pending(); len(pending) != 0 {
+		t.Errorf("pending after restart = %+v, want none", pending)
+	}
+}
+
+// TestLoadTelemetry checks that telemetry is off by default and that an
+// endpoint requires opting in.
+func TestLoadTelemetry(t *testing.T) {
+	for _, tc := range []struct {
+		enabled, endpoint string
+		want              bool
+		wantErr           bool
+	}{
+		{"", "", false, false},
+		{"true", "", true, false},
+		{"true", "https://telemetry.example.com/v1", true, false},
+		{"", "https://telemetry.example.com/v1", false, true},
+		{"true", "telemetry.example.com", false, true},
+		{"maybe", "", false, true},
+	} {
+		t.Setenv("GOOGLE_SEARCH_TELEMETRY", tc.enabled)
+		t.Setenv("GOOGLE_SEARCH_TELEMETRY_ENDPOINT", tc.endpoint)
+
+		enabled, _, err := loadTelemetry()
+		if enabled != tc.want || (err != nil) != tc.wantErr {
+			t.Errorf("loadTelemetry(%q, %q) = %v, %v", tc.enabled, tc.endpoint, enabled, err)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// maxTermsLength caps term parameters well below the API's URL length limit.
+const maxTermsLength = 500
+
+// extractTerms extracts and validates a free-text term parameter such as
+// exact_terms. Whitespace is collapsed; the value is URL-encoded by
+// buildSearchParams.
+func extractTerms(arguments map[string]interface{}, name string) (string, error) {
+	raw, ok := arguments[name]
+	if !ok {
+		return "", nil
+	}
+
+	value, ok := raw.(string)
+	if !ok {
+		return "", fmt.Errorf("%s must be a string", name)
+	}
+
+	// Control characters can't be meant literally; treat them as spaces
+	value = strings.Join(strings.FieldsFunc(value, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsControl(r)
+	}), " ")
+
+	if len(value) > maxTermsLength {
+		return "", fmt.Errorf("%s must be at most %d bytes", name, maxTermsLength)
+	}
+
+	return value, nil
+}
+
+// extractOrTerms extracts the or_terms parameter, given either as a string or
+// as an array of terms, which are joined by spaces. An array element of
+// several words is one alternative, so it is quoted as a phrase.
+func extractOrTerms(arguments map[string]interface{}) (string, error) {
+	raw, ok := arguments["or_terms"].([]interface{})
+	if !ok {
+		return extractTerms(arguments, "or_terms")
+	}
+
+	terms := make([]string, 0, len(raw))
+
+	for _, item := range raw {
+		term, ok := item.(string)
+		if !ok {
+			return "", fmt.Errorf("or_terms must be a string or an array of strings")
+		}
+
+		term, err := extractTerms(map[string]interface{}{"or_terms": term}, "or_terms")
+		if err != nil {
+			return "", err
+		}
+
+		switch {
+		case term == "":
+			continue
+		case strings.Contains(term, `"`):
+			return "", fmt.Errorf("or_terms array elements can't contain quotation marks: %q", term)
+		case strings.Contains(term, " "):
+			term = `"` + term + `"`
+		}
+
+		terms = append(terms, term)
+	}
+
+	return extractTerms(map[string]interface{}{"or_terms": strings.Join(terms, " ")}, "or_terms")
+}
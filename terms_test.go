@@ -0,0 +1,32 @@
+package main
+
+import "testing"
+
+func TestExtractOrTerms(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   interface{}
+		want    string
+		wantErr bool
+	}{
+		{name: "string", value: "car  automobile", want: "car automobile"},
+		{name: "words", value: []interface{}{"car", "automobile"}, want: "car automobile"},
+		{name: "phrase", value: []interface{}{"machine learning", "AI"}, want: `"machine learning" AI`},
+		{name: "spaced phrase", value: []interface{}{" deep\tlearning ", ""}, want: `"deep learning"`},
+		{name: "quoted element", value: []interface{}{`say "hi"`}, wantErr: true},
+		{name: "not a string", value: []interface{}{"car", 1.0}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := extractOrTerms(map[string]interface{}{"or_terms": tt.value})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// maxThumbnailBytes bounds a thumbnail's download; larger ones are skipped.
+	maxThumbnailBytes = 256 << 10
+	thumbnailTimeout  = 10 * time.Second
+)
+
+// thumbnailTypes are the image types returned as MCP image content.
+var thumbnailTypes = map[string]bool{"image/jpeg": true, "image/png": true, "image/gif": true, "image/webp": true}
+
+// thumbnail is the thumbnail of one result, by its 1-based rank.
+type thumbnail struct {
+	rank int
+	link string
+}
+
+// thumbnailLinks returns the thumbnails of results: the image block's for
+// image search, else the page's cse_thumbnail. Call it before compactResults,
+// which drops the pagemap.
+func thumbnailLinks(results []GoogleSearchResult) []thumbnail {
+	var thumbnails []thumbnail
+
+	for i, result := range results {
+		link := ""
+
+		switch {
+		case result.Image != nil:
+			link = result.Image.ThumbnailLink
+		case result.Pagemap != nil && len(result.Pagemap.Thumbnails) > 0:
+			link = result.Pagemap.Thumbnails[0].Src
+		}
+
+		if link != "" {
+			thumbnails = append(thumbnails, thumbnail{rank: i + 1, link: link})
+		}
+	}
+
+	return thumbnails
+}
+
+// attachThumbnails downloads thumbnails and appends each to result as image
+// content, after a line naming its result. Thumbnails that fail to download
+// are left out; their links are in the results.
+func attachThumbnails(ctx context.Context, result *mcp.CallToolResult, thumbnails []thumbnail) *mcp.CallToolResult {
+	if len(thumbnails) == 0 {
+		return result
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, thumbnailTimeout)
+	defer cancel()
+
+	images := make([]*mcp.ImageContent, len(thumbnails))
+
+	var wg sync.WaitGroup
+
+	for i, t := range thumbnails {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			images[i], _ = fetchThumbnail(ctx, t.link)
+		}()
+	}
+
+	wg.Wait()
+
+	for i, image := range images {
+		if image != nil {
+			result.Content = append(result.Content,
+				mcp.NewTextContent(fmt.Sprintf("Thumbnail of result %d:", thumbnails[i].rank)),
+				*image,
+			)
+		}
+	}
+
+	return result
+}
+
+// fetchThumbnail downloads the image at link as MCP image content.
+func fetchThumbnail(ctx context.Context, link string) (*mcp.ImageContent, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "image/*")
+
+	resp, err := pageClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("the site returned %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxThumbnailBytes+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) > maxThumbnailBytes {
+		return nil, fmt.Errorf("thumbnail is larger than %d bytes", maxThumbnailBytes)
+	}
+
+	// Trust the image's bytes over a missing or generic Content-Type
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if !thumbnailTypes[mediaType] {
+		mediaType = http.DetectContentType(data)
+	}
+
+	if !thumbnailTypes[mediaType] {
+		return nil, fmt.Errorf("unsupported thumbnail type %s", mediaType)
+	}
+
+	image := mcp.NewImageContent(base64.StdEncoding.EncodeToString(data), mediaType)
+
+	return &image, nil
+}
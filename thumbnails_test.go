@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/habuvo/mcp-internet-search/searchtest"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestIncludeThumbnails(t *testing.T) {
+	var thumb bytes.Buffer
+	if err := png.Encode(&thumb, image.NewGray(image.Rect(0, 0, 2, 2))); err != nil {
+		t.Fatal(err)
+	}
+
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/thumb" {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		// No Content-Type: the type is sniffed
+		w.Header()["Content-Type"] = nil
+		w.Write(thumb.Bytes())
+	}))
+	defer site.Close()
+
+	api := newFakeSearchAPI()
+	api.Respond(func(params url.Values) []searchtest.Result {
+		results := searchtest.Results(params.Get("q"), 3)
+		results[0].Image = &searchtest.Image{ThumbnailLink: site.URL + "/thumb"}
+		results[1].Image = &searchtest.Image{ThumbnailLink: site.URL + "/gone"}
+
+		return results
+	})
+
+	c := newConformanceClient(t, api)
+
+	// Without the flag only text is returned
+	result, err := callTool(t, c, "google_image_search", map[string]interface{}{"query": "cat"})
+	if err != nil {
+		t.Fatalf("google_image_search: %v", err)
+	}
+
+	resultText(t, result)
+
+	result, err = callTool(t, c, "google_image_search", map[string]interface{}{"query": "cat", "include_thumbnails": true})
+	if err != nil {
+		t.Fatalf("google_image_search with thumbnails: %v", err)
+	}
+
+	// The text, then the first result's thumbnail; the second's is missing
+	if len(result.Content) != 3 {
+		t.Fatalf("got %d content blocks, want 3: %+v", len(result.Content), result.Content)
+	}
+
+	if caption, ok := result.Content[1].(mcp.TextContent); !ok || caption.Text != "Thumbnail of result 1:" {
+		t.Errorf("caption = %+v", result.Content[1])
+	}
+
+	img, ok := result.Content[2].(mcp.ImageContent)
+	if !ok {
+		t.Fatalf("content is %T, want mcp.ImageContent", result.Content[2])
+	}
+
+	if data, err := base64.StdEncoding.DecodeString(img.Data); err != nil || !bytes.Equal(data, thumb.Bytes()) || img.MIMEType != "image/png" {
+		t.Errorf("image = %s, %d bytes (err %v)", img.MIMEType, len(data), err)
+	}
+}
+
+func TestThumbnailLinks(t *testing.T) {
+	results := []GoogleSearchResult{
+		{Link: "https://example.com/a", Pagemap: &ResultPagemap{Thumbnails: []PagemapImage{{Src: "https://encrypted-tbn0.gstatic.com/a"}}}},
+		{Link: "https://example.com/b"},
+		{Link: "https://example.com/c.png", Image: &ResultImage{ThumbnailLink: "https://encrypted-tbn0.gstatic.com/c"}},
+	}
+
+	got := thumbnailLinks(results)
+	want := []thumbnail{{1, "https://encrypted-tbn0.gstatic.com/a"}, {3, "https://encrypted-tbn0.gstatic.com/c"}}
+
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("thumbnailLinks = %+v, want %+v", got, want)
+	}
+}
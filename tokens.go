@@ -0,0 +1,32 @@
+package main
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// defaultTokenEstimator is used when GOOGLE_SEARCH_TOKEN_ESTIMATOR is unset.
+const defaultTokenEstimator = "chars"
+
+// tokenEstimators maps heuristic names to functions approximating the number
+// of LLM tokens in a text. They are deliberately cheap and tokenizer-agnostic.
+var tokenEstimators = map[string]func(text string) int{
+	// About four characters per token for English prose.
+	"chars": func(text string) int {
+		return (utf8.RuneCountInString(text) + 3) / 4
+	},
+	// About four tokens per three words of English.
+	"words": func(text string) int {
+		return (len(strings.Fields(text))*4 + 2) / 3
+	},
+}
+
+// estimateTokens approximates the token count of text using the named heuristic.
+func estimateTokens(estimator, text string) int {
+	estimate, ok := tokenEstimators[estimator]
+	if !ok {
+		estimate = tokenEstimators[defaultTokenEstimator]
+	}
+
+	return estimate(text)
+}
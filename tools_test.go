@@ -0,0 +1,39 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// TestToolSchemaGolden snapshots every tool definition (name, description and
+// input schema) as clients receive it, so any change to the public tool
+// contract shows up in review. Optional features are enabled to cover every
+// argument. Run with -update to accept a deliberate change.
+func TestToolSchemaGolden(t *testing.T) {
+	allFeatures := featureSet{featureCluster: true, featureEntities: true, featureSEOTools: true, featureTranslation: true}
+
+	for _, tool := range []mcp.Tool{
+		createGoogleSearchTool(allFeatures),
+		createImageSearchTool(),
+		createRankCheckTool(),
+		createCompareDomainsTool(),
+		createKeywordCoverageTool(),
+		createQueryAnalyticsTool(),
+		createGetResultTool(),
+		createOpenResultTool(),
+		createBookmarkResultTool(),
+		createListBookmarksTool(),
+		createExportReportTool(),
+	} {
+		t.Run(tool.Name, func(t *testing.T) {
+			data, err := json.MarshalIndent(tool, "", "  ")
+			if err != nil {
+				t.Fatalf("failed to marshal %s: %v", tool.Name, err)
+			}
+
+			checkGolden(t, "tool_"+tool.Name+".golden", string(data)+"\n")
+		})
+	}
+}
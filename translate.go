@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// translateURL is the Cloud Translation API (Basic, v2) endpoint.
+	translateURL     = "https://translation.googleapis.com/language/translate/v2"
+	translateTimeout = 15 * time.Second
+	// maxTranslateSegments is how many texts one Translation API request
+	// may carry.
+	maxTranslateSegments = 128
+)
+
+// translateClient sends Cloud Translation API requests. The API is on
+// another host than Custom Search, so it doesn't share searchClient.
+var translateClient = &http.Client{Timeout: translateTimeout}
+
+// translateLanguage matches the language codes Cloud Translation takes,
+// e.g. "en", "de" or "zh-TW".
+var translateLanguage = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,4})?$`)
+
+// ResultTranslation is a machine translation of a result's title and
+// snippet, shown next to the originals.
+type ResultTranslation struct {
+	// Language is the language translated to; SourceLanguage is the one
+	// the API detected.
+	Language       string `json:"language"`
+	SourceLanguage string `json:"source_language,omitempty"`
+	Title          string `json:"title"`
+	Snippet        string `json:"snippet"`
+}
+
+// extractTranslateTo extracts and validates the translate_results_to
+// parameter.
+func extractTranslateTo(arguments map[string]interface{}) (string, error) {
+	language, _ := arguments["translate_results_to"].(string)
+	language = strings.TrimSpace(language)
+
+	if language == "" {
+		return "", nil
+	}
+
+	if !translateLanguage.MatchString(language) {
+		return "", fmt.Errorf("invalid translate_results_to %q (want a language code such as en, de or zh-TW)", language)
+	}
+
+	return language, nil
+}
+
+// translationResponse is the Translation API's response body.
+type translationResponse struct {
+	Data struct {
+		Translations []struct {
+			TranslatedText         string `json:"translatedText"`
+			DetectedSourceLanguage string `json:"detectedSourceLanguage"`
+		} `json:"translations"`
+	} `json:"data"`
+}
+
+// translateResults sets a translation into language on each result not
+// already in it. The titles and snippets go to the API together, in as
+// few requests as its segment limit allows.
+func translateResults(ctx context.Context, apiKey, language string, results []GoogleSearchResult) error {
+	texts := make([]string, 0, 2*len(results))
+	for _, result := range results {
+		texts = append(texts, result.Title, result.Snippet)
+	}
+
+	translated := make([]string, 0, len(texts))
+	detected := make([]string, 0, len(texts))
+
+	for start := 0; start < len(texts); start += maxTranslateSegments {
+		end := min(start+maxTranslateSegments, len(texts))
+
+		resp, err := requestTranslation(ctx, apiKey, language, texts[start:end])
+		if err != nil {
+			return err
+		}
+
+		if len(resp.Data.Translations) != end-start {
+			return fmt.Errorf("translation API returned %d translations for %d texts", len(resp.Data.Translations), end-start)
+		}
+
+		for _, t := range resp.Data.Translations {
+			translated = append(translated, t.TranslatedText)
+			detected = append(detected, t.DetectedSourceLanguage)
+		}
+	}
+
+	for i := range results {
+		// A snippet says more about the language than a short title
+		source := detected[2*i+1]
+		if strings.EqualFold(primaryLanguage(source), primaryLanguage(language)) {
+			continue
+		}
+
+		results[i].Translation = &ResultTranslation{
+			Language:       language,
+			SourceLanguage: source,
+			Title:          translated[2*i],
+			Snippet:        translated[2*i+1],
+		}
+	}
+
+	return nil
+}
+
+// primaryLanguage returns the language of a code without its region, e.g.
+// "pt" for "pt-BR".
+func primaryLanguage(code string) string {
+	language, _, _ := strings.Cut(code, "-")
+
+	return language
+}
+
+// requestTranslation sends one Translation API request.
+func requestTranslation(ctx context.Context, apiKey, language string, texts []string) (*translationResponse, error) {
+	body, err := json.Marshal(map[string]interface{}{"q": texts, "target": language, "format": "text"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode translation request: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, translateURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create translation request: %v", err)
+	}
+
+	// Send the key in a header so errors quoting the URL can't leak it
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Goog-Api-Key", apiKey)
+
+	resp, err := translateClient.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+
+		return nil, fmt.Errorf("translation request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read translation response: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr apiErrorResponse
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Error.Message != "" {
+			return nil, fmt.Errorf("translation API returned %d: %s", resp.StatusCode, apiErr.Error.Message)
+		}
+
+		return nil, fmt.Errorf("translation API returned %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	var translation translationResponse
+	if err := json.Unmarshal(data, &translation); err != nil {
+		return nil, fmt.Errorf("failed to parse translation response: %v", err)
+	}
+
+	return &translation, nil
+}
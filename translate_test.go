@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fakeTranslationAPI serves Translation API requests by upper-casing each
+// text and detecting English for texts starting with "en:", else Spanish.
+// It returns the number of requests served so far.
+func fakeTranslationAPI(t *testing.T, status int) func() int {
+	t.Helper()
+
+	requests := 0
+
+	previous := translateClient
+	translateClient = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		requests++
+
+		if key := req.Header.Get("X-Goog-Api-Key"); key != "test-key" || req.URL.Query().Get("key") != "" {
+			t.Errorf("API key sent as header %q and query %q, want the header only", key, req.URL.Query().Get("key"))
+		}
+
+		rec := httptest.NewRecorder()
+		if status != http.StatusOK {
+			rec.WriteHeader(status)
+			fmt.Fprint(rec, `{"error": {"code": 403, "message": "Cloud Translation API has not been used in project 1 before or it is disabled."}}`)
+
+			return rec.Result(), nil
+		}
+
+		var body struct {
+			Q      []string `json:"q"`
+			Target string   `json:"target"`
+		}
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			t.Fatalf("decoding request: %v", err)
+		}
+
+		var resp translationResponse
+		for _, text := range body.Q {
+			source := "es"
+			if strings.HasPrefix(text, "en:") {
+				source = "en"
+			}
+
+			resp.Data.Translations = append(resp.Data.Translations, struct {
+				TranslatedText         string `json:"translatedText"`
+				DetectedSourceLanguage string `json:"detectedSourceLanguage"`
+			}{body.Target + ":" + strings.ToUpper(text), source})
+		}
+
+		json.NewEncoder(rec).Encode(resp)
+
+		return rec.Result(), nil
+	})}
+	t.Cleanup(func() { translateClient = previous })
+
+	return func() int { return requests }
+}
+
+func TestTranslateResults(t *testing.T) {
+	requests := fakeTranslationAPI(t, http.StatusOK)
+
+	// 70 results are 140 texts: two requests
+	results := make([]GoogleSearchResult, 70)
+	for i := range results {
+		results[i] = GoogleSearchResult{Title: fmt.Sprintf("título %d", i), Snippet: fmt.Sprintf("resumen %d", i)}
+	}
+
+	results[1].Snippet = "en: already English"
+
+	if err := translateResults(context.Background(), "test-key", "en-US", results); err != nil {
+		t.Fatalf("translateResults: %v", err)
+	}
+
+	if n := requests(); n != 2 {
+		t.Errorf("requests = %d, want 2", n)
+	}
+
+	want := ResultTranslation{Language: "en-US", SourceLanguage: "es", Title: "en-US:TÍTULO 0", Snippet: "en-US:RESUMEN 0"}
+	if got := results[0].Translation; got == nil || *got != want {
+		t.Errorf("results[0].Translation = %+v, want %+v", got, want)
+	}
+
+	if got := results[1].Translation; got != nil {
+		t.Errorf("result already in English got translation %+v", got)
+	}
+
+	if got := results[69].Translation; got == nil || got.Snippet != "en-US:RESUMEN 69" {
+		t.Errorf("results[69].Translation = %+v, want the last text translated", got)
+	}
+}
+
+func TestTranslateResultsError(t *testing.T) {
+	fakeTranslationAPI(t, http.StatusForbidden)
+
+	results := []GoogleSearchResult{{Title: "título", Snippet: "resumen"}}
+
+	err := translateResults(context.Background(), "test-key", "en", results)
+	if err == nil || !strings.Contains(err.Error(), "Cloud Translation API has not been used") {
+		t.Errorf("err = %v, want the API's message", err)
+	}
+
+	if results[0].Translation != nil {
+		t.Errorf("translation set despite the error: %+v", results[0].Translation)
+	}
+}
+
+func TestExtractTranslateTo(t *testing.T) {
+	for value, want := range map[string]string{"": "", "de": "de", " zh-TW ": "zh-TW", "German": "", "en_US": ""} {
+		got, err := extractTranslateTo(map[string]interface{}{"translate_results_to": value})
+		if want == "" && value != "" {
+			if err == nil {
+				t.Errorf("%q: no error", value)
+			}
+
+			continue
+		}
+
+		if err != nil || got != want {
+			t.Errorf("%q: got %q, %v, want %q", value, got, err, want)
+		}
+	}
+}
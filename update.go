@@ -0,0 +1,312 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	releasesURL    = "https://api.github.com/repos/habuvo/mcp-internet-search/releases/latest"
+	checksumsAsset = "checksums.txt"
+	updateTimeout  = 2 * time.Minute
+)
+
+// githubRelease is the subset of the GitHub release API response we use.
+type githubRelease struct {
+	TagName string         `json:"tag_name"`
+	Assets  []releaseAsset `json:"assets"`
+}
+
+// releaseAsset is a downloadable file attached to a release.
+type releaseAsset struct {
+	Name               string `json:"name"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+}
+
+// runUpdateCommand handles `update`: it replaces the running binary with the latest release.
+// The checksum is fetched from the same release, so it detects corrupted
+// downloads, not tampered releases.
+func runUpdateCommand(args []string) error {
+	fs := flag.NewFlagSet("update", flag.ContinueOnError)
+	checkOnly := fs.Bool("check", false, "Only report whether an update is available")
+	force := fs.Bool("force", false, "Install the latest release even if it isn't newer than this version")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s update [--check] [--force]\n", serviceName)
+		fmt.Fprintf(fs.Output(), "Installs the latest GitHub release after checking its SHA-256 against the release's %s.\n", checksumsAsset)
+		fmt.Fprintln(fs.Output(), "The checksum only guards against corrupted downloads: it comes from the same release, so it")
+		fmt.Fprintln(fs.Output(), "doesn't protect against a tampered release.")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: updateTimeout}
+
+	release, err := fetchLatestRelease(client)
+	if err != nil {
+		return err
+	}
+
+	latest := strings.TrimPrefix(release.TagName, "v")
+
+	order, err := compareVersions(latest, version)
+	if err != nil && !*force {
+		return fmt.Errorf("can't compare release %s with this version (%s): %v; use --force to install it anyway", latest, version, err)
+	}
+
+	switch {
+	case *force:
+	case order == 0:
+		fmt.Printf("Already up to date (%s)\n", version)
+
+		return nil
+	case order < 0:
+		fmt.Printf("This version (%s) is newer than the latest release (%s); use --force to downgrade\n", version, latest)
+
+		return nil
+	}
+
+	if *checkOnly {
+		fmt.Printf("Update available: %s -> %s\n", version, latest)
+
+		return nil
+	}
+
+	binary, checksums, err := findReleaseAssets(release)
+	if err != nil {
+		return err
+	}
+
+	want, err := fetchChecksum(client, checksums.BrowserDownloadURL, binary.Name)
+	if err != nil {
+		return err
+	}
+
+	if err := replaceExecutable(client, binary.BrowserDownloadURL, want); err != nil {
+		return err
+	}
+
+	fmt.Printf("Updated %s -> %s\n", version, latest)
+
+	return nil
+}
+
+// compareVersions compares semantic versions such as 1.2.0 and
+// 1.3.0-rc.1, returning -1, 0 or 1 as a is older than, the same as or
+// newer than b. A pre-release is older than its release; pre-releases
+// compare as strings.
+func compareVersions(a, b string) (int, error) {
+	aCore, aPre, err := parseVersion(a)
+	if err != nil {
+		return 0, err
+	}
+
+	bCore, bPre, err := parseVersion(b)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range aCore {
+		if aCore[i] != bCore[i] {
+			return cmp.Compare(aCore[i], bCore[i]), nil
+		}
+	}
+
+	switch {
+	case aPre == bPre:
+		return 0, nil
+	case aPre == "":
+		return 1, nil
+	case bPre == "":
+		return -1, nil
+	default:
+		return strings.Compare(aPre, bPre), nil
+	}
+}
+
+// parseVersion splits a MAJOR.MINOR.PATCH[-PRERELEASE][+BUILD] version
+// into its numbers and pre-release.
+func parseVersion(v string) ([3]int, string, error) {
+	var core [3]int
+
+	v, _, _ = strings.Cut(strings.TrimPrefix(v, "v"), "+")
+	v, pre, _ := strings.Cut(v, "-")
+
+	parts := strings.Split(v, ".")
+	if len(parts) != len(core) {
+		return core, "", fmt.Errorf("%q is not a MAJOR.MINOR.PATCH version", v)
+	}
+
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return core, "", fmt.Errorf("%q is not a MAJOR.MINOR.PATCH version", v)
+		}
+
+		core[i] = n
+	}
+
+	return core, pre, nil
+}
+
+// fetchLatestRelease queries GitHub for the latest published release.
+func fetchLatestRelease(client *http.Client) (*githubRelease, error) {
+	resp, err := client.Get(releasesURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for updates: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("release lookup returned non-200 status: %d", resp.StatusCode)
+	}
+
+	var release githubRelease
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return nil, fmt.Errorf("failed to parse release: %v", err)
+	}
+
+	return &release, nil
+}
+
+// findReleaseAssets picks the binary for this platform and the checksums file.
+func findReleaseAssets(release *githubRelease) (binary, checksums releaseAsset, err error) {
+	name := fmt.Sprintf("%s_%s_%s", serviceName, runtime.GOOS, runtime.GOARCH)
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+
+	var foundBinary, foundChecksums bool
+
+	for _, asset := range release.Assets {
+		switch asset.Name {
+		case name:
+			binary, foundBinary = asset, true
+		case checksumsAsset:
+			checksums, foundChecksums = asset, true
+		}
+	}
+
+	if !foundBinary {
+		return binary, checksums, fmt.Errorf("release %s has no binary for %s/%s", release.TagName, runtime.GOOS, runtime.GOARCH)
+	}
+
+	if !foundChecksums {
+		return binary, checksums, fmt.Errorf("release %s has no %s; refusing to install unverified binary", release.TagName, checksumsAsset)
+	}
+
+	return binary, checksums, nil
+}
+
+// fetchChecksum downloads the checksums file and returns the SHA-256 listed for name.
+func fetchChecksum(client *http.Client, url, name string) (string, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", fmt.Errorf("failed to download checksums: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("checksums download returned non-200 status: %d", resp.StatusCode)
+	}
+
+	// Format: "<hex sha256>  <file name>" per line
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[1] == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read checksums: %v", err)
+	}
+
+	return "", fmt.Errorf("no checksum listed for %s", name)
+}
+
+// replaceExecutable downloads the new binary, verifies its checksum and swaps it
+// in place of the running executable.
+func replaceExecutable(client *http.Client, url, wantSHA256 string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate executable: %v", err)
+	}
+
+	exe, err = filepath.EvalSymlinks(exe)
+	if err != nil {
+		return fmt.Errorf("failed to resolve executable: %v", err)
+	}
+
+	resp, err := client.Get(url)
+	if err != nil {
+		return fmt.Errorf("failed to download update: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("update download returned non-200 status: %d", resp.StatusCode)
+	}
+
+	// Stage next to the executable so the final rename stays on one filesystem
+	tmp, err := os.CreateTemp(filepath.Dir(exe), filepath.Base(exe)+".new-*")
+	if err != nil {
+		return fmt.Errorf("failed to stage update: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, hash), resp.Body); err != nil {
+		tmp.Close()
+
+		return fmt.Errorf("failed to download update: %v", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to stage update: %v", err)
+	}
+
+	if got := hex.EncodeToString(hash.Sum(nil)); got != wantSHA256 {
+		return fmt.Errorf("checksum mismatch: got %s, want %s", got, wantSHA256)
+	}
+
+	if err := os.Chmod(tmp.Name(), 0o755); err != nil {
+		return fmt.Errorf("failed to stage update: %v", err)
+	}
+
+	// Windows can't overwrite a running executable, but it can rename it
+	old := exe + ".old"
+	_ = os.Remove(old)
+
+	if err := os.Rename(exe, old); err != nil {
+		return fmt.Errorf("failed to replace executable: %v", err)
+	}
+
+	if err := os.Rename(tmp.Name(), exe); err != nil {
+		_ = os.Rename(old, exe)
+
+		return fmt.Errorf("failed to replace executable: %v", err)
+	}
+
+	if runtime.GOOS != "windows" {
+		_ = os.Remove(old)
+	}
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package main
+
+import "testing"
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0.0", "1.0.0", 0},
+		{"v1.2.0", "1.2.0", 0},
+		{"1.10.0", "1.9.3", 1},
+		{"1.0.0", "1.0.1", -1},
+		{"2.0.0-rc.1", "2.0.0", -1},
+		{"2.0.0", "2.0.0-rc.1", 1},
+		{"2.0.0-rc.2", "2.0.0-rc.1", 1},
+		{"1.0.0+build.5", "1.0.0", 0},
+	}
+
+	for _, tt := range tests {
+		got, err := compareVersions(tt.a, tt.b)
+		if err != nil || got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, %v, want %d", tt.a, tt.b, got, err, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"", "1.0", "1.x.0", "dev"} {
+		if _, err := compareVersions(bad, "1.0.0"); err == nil {
+			t.Errorf("compareVersions(%q, ...) accepted an invalid version", bad)
+		}
+	}
+}
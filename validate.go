@@ -0,0 +1,262 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// configSettings are the settings a config file may hold. Other
+// GOOGLE_SEARCH_ and GOOGLE_ names are reported as unknown by validate.
+var configSettings = map[string]bool{
+	"GOOGLE_API_KEY":                     true,
+	"GOOGLE_SEARCH_ENGINE_ID":            true,
+	"GOOGLE_SEARCH_APPEND_TERMS":         true,
+	"GOOGLE_SEARCH_CHAOS":                true,
+	"GOOGLE_SEARCH_DAILY_QUOTA":          true,
+	"GOOGLE_SEARCH_DNS_TTL":              true,
+	"GOOGLE_SEARCH_FALLBACKS":            true,
+	"GOOGLE_SEARCH_FEATURES":             true,
+	"GOOGLE_SEARCH_FREE_CALLS_PER_DAY":   true,
+	"GOOGLE_SEARCH_HEDGE_DELAY":          true,
+	"GOOGLE_SEARCH_HISTORY":              true,
+	"GOOGLE_SEARCH_IP_FAMILY":            true,
+	"GOOGLE_SEARCH_KEEP_WARM":            true,
+	"GOOGLE_SEARCH_LOCALE":               true,
+	"GOOGLE_SEARCH_MONTHLY_CAP":          true,
+	"GOOGLE_SEARCH_PAYWALLED_DOMAINS":    true,
+	"GOOGLE_SEARCH_PRICE_PER_1000":       true,
+	"GOOGLE_SEARCH_QUESTIONABLE_DOMAINS": true,
+	"GOOGLE_SEARCH_QUOTA_RESERVE":        true,
+	"GOOGLE_SEARCH_REPORT_DIR":           true,
+	"GOOGLE_SEARCH_SAFE":                 true,
+	"GOOGLE_SEARCH_SNIPPETS_PER_RESULT":  true,
+	"GOOGLE_SEARCH_SNIPPET_LENGTH":       true,
+	"GOOGLE_SEARCH_TELEMETRY":            true,
+	"GOOGLE_SEARCH_TELEMETRY_ENDPOINT":   true,
+	"GOOGLE_SEARCH_TOOL_QUOTAS":          true,
+	"GOOGLE_SEARCH_TOKEN_ESTIMATOR":      true,
+	"GOOGLE_SEARCH_TRUSTED_DOMAINS":      true,
+}
+
+// runValidateCommand handles `validate`: it checks a config file, as the
+// server would load it, and fails if any check fails, for use in CI.
+func runValidateCommand(args []string) error {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	path := fs.String("config", "", "Config file to check (default: the file the server loads)")
+	offline := fs.Bool("offline", false, "Don't send a test query to check the credentials")
+	profile := fs.String("profile", os.Getenv(profileEnv), "Config file profile to check (default: $"+profileEnv+")")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *path == "" {
+		defaultPath, err := configFilePath()
+		if err != nil {
+			return err
+		}
+
+		*path = defaultPath
+	}
+
+	report := &doctorReport{}
+	validateConfigFile(report, *path, *profile, *offline)
+
+	if report.failed {
+		return fmt.Errorf("%s is not valid", *path)
+	}
+
+	fmt.Printf("%s is valid.\n", *path)
+
+	return nil
+}
+
+// validateConfigFile reports the syntax, settings, values and conflicts of
+// profile of the config file at path, then checks the credentials unless
+// offline.
+func validateConfigFile(report *doctorReport, path, profile string, offline bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		report.add(checkFail, "config file", err.Error())
+
+		return
+	}
+
+	file, err := parseConfigProfiles(string(data))
+	if err != nil {
+		report.add(checkFail, "config file", err.Error())
+
+		return
+	}
+
+	values, err := file.settings(profile)
+	if err != nil {
+		report.add(checkFail, "config file", err.Error())
+
+		return
+	}
+
+	report.add(checkPass, "config file", fmt.Sprintf("%s: %d settings", path, len(values)))
+
+	if profile == "" && len(file.names) > 0 {
+		report.add(checkWarn, "profiles", fmt.Sprintf("only the common settings were checked; pass --profile to check %s", strings.Join(file.names, ", ")))
+	}
+
+	checkSettingNames(report, values)
+
+	// Load the file the way the server does: the environment wins
+	for _, name := range sortedKeys(values) {
+		if env, ok := os.LookupEnv(name); ok && env != values[name] {
+			report.add(checkWarn, "override", name+" is also set in the environment, which takes precedence")
+		}
+	}
+
+	if err := os.Setenv(configFileEnv, path); err != nil {
+		report.add(checkFail, "config file", err.Error())
+
+		return
+	}
+
+	os.Setenv(profileEnv, profile)
+
+	if err := loadConfigFile(); err != nil {
+		report.add(checkFail, "config file", err.Error())
+
+		return
+	}
+
+	checkSettingValues(report)
+	checkConflicts(report)
+
+	apiKey, searchEngineID := os.Getenv("GOOGLE_API_KEY"), os.Getenv("GOOGLE_SEARCH_ENGINE_ID")
+	for _, v := range []struct{ name, value string }{
+		{"GOOGLE_API_KEY", apiKey},
+		{"GOOGLE_SEARCH_ENGINE_ID", searchEngineID},
+	} {
+		if v.value == "" {
+			report.add(checkFail, "credentials", v.name+" is not set")
+		}
+	}
+
+	if offline {
+		report.add(checkSkip, "api key", "offline")
+		report.add(checkSkip, "search engine id", "offline")
+
+		return
+	}
+
+	checkCredentials(report, apiKey, searchEngineID)
+}
+
+// checkSettingNames reports settings the server doesn't know, which are
+// usually misspelled.
+func checkSettingNames(report *doctorReport, values map[string]string) {
+	for _, name := range sortedKeys(values) {
+		switch {
+		case name == configFileEnv || name == profileEnv:
+			report.add(checkWarn, "setting", name+" has no effect inside a config file")
+		case configSettings[name]:
+		case strings.HasPrefix(name, "GOOGLE_"):
+			report.add(checkFail, "setting", "unknown setting "+name)
+		}
+	}
+}
+
+// checkSettingValues reports every setting whose value the server would
+// reject, rather than stopping at the first as loadConfig does.
+func checkSettingValues(report *doctorReport) {
+	checks := []struct {
+		name  string
+		check func() error
+	}{
+		{"token estimator", func() error {
+			if name := os.Getenv("GOOGLE_SEARCH_TOKEN_ESTIMATOR"); name != "" && tokenEstimators[name] == nil {
+				return fmt.Errorf("unknown GOOGLE_SEARCH_TOKEN_ESTIMATOR %q (want chars or words)", name)
+			}
+
+			return nil
+		}},
+		{"credibility", func() error { _, err := loadCredibilityLists(); return err }},
+		{"paywalls", func() error { _, err := loadPaywalledDomains(); return err }},
+		{"fallbacks", func() error { _, err := loadFallbacks(); return err }},
+		{"features", func() error { _, err := loadFeatures(); return err }},
+		{"locale", func() error { _, err := loadLocale(); return err }},
+		{"snippets", func() error { _, err := loadSnippetOptions(); return err }},
+		{"safe search", func() error { _, err := loadSafeSearch(); return err }},
+		{"append terms", func() error { _, err := loadAppendTerms(); return err }},
+		{"report dir", func() error { _, err := loadReportDir(); return err }},
+		{"telemetry", func() error { _, _, err := loadTelemetry(); return err }},
+		{"hedging", func() error { _, err := loadHedgeDelay(); return err }},
+		{"dialer", func() error { _, _, err := loadDialerSettings(); return err }},
+		{"keep-warm", func() error { _, err := loadKeepWarmInterval(); return err }},
+		{"chaos", func() error { _, err := loadChaos(); return err }},
+		{"quota", func() error { _, err := loadQuota(); return err }},
+		{"history", func() error {
+			if value := os.Getenv("GOOGLE_SEARCH_HISTORY"); value != "" {
+				if _, err := strconv.ParseBool(value); err != nil {
+					return fmt.Errorf("GOOGLE_SEARCH_HISTORY must be true or false, got %q", value)
+				}
+			}
+
+			return nil
+		}},
+	}
+
+	for _, c := range checks {
+		if err := c.check(); err != nil {
+			report.add(checkFail, c.name, err.Error())
+		} else {
+			report.add(checkPass, c.name, "ok")
+		}
+	}
+}
+
+// checkConflicts reports settings that are valid alone but contradict each
+// other or don't belong in a deployment.
+func checkConflicts(report *doctorReport) {
+	trusted, _ := readDomainList(os.Getenv("GOOGLE_SEARCH_TRUSTED_DOMAINS"))
+	questionable, _ := readDomainList(os.Getenv("GOOGLE_SEARCH_QUESTIONABLE_DOMAINS"))
+
+	inTrusted := make(map[string]bool, len(trusted))
+	for _, domain := range trusted {
+		inTrusted[domain] = true
+	}
+
+	var both []string
+
+	for _, domain := range questionable {
+		if inTrusted[domain] {
+			both = append(both, domain)
+		}
+	}
+
+	if len(both) > 0 {
+		report.add(checkFail, "conflict", "trusted and questionable at once: "+strings.Join(both, ", "))
+	}
+
+	if os.Getenv("GOOGLE_SEARCH_CHAOS") != "" {
+		report.add(checkWarn, "chaos", "GOOGLE_SEARCH_CHAOS injects faults into search API requests; it is meant for testing")
+	}
+
+	if dir := os.Getenv("GOOGLE_SEARCH_REPORT_DIR"); dir != "" {
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			report.add(checkWarn, "report dir", dir+" is not an existing directory")
+		}
+	}
+}
+
+// sortedKeys returns the keys of m in sorted order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// TestConfigSettingsComplete checks that validate knows every setting the
+// server reads, so it doesn't reject a new one as misspelled.
+func TestConfigSettingsComplete(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	setting := regexp.MustCompile(`"(GOOGLE_[A-Z_]+)"`)
+
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+
+		src, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, match := range setting.FindAllStringSubmatch(string(src), -1) {
+			if name := match[1]; name != configFileEnv && name != profileEnv && !configSettings[name] {
+				t.Errorf("%s reads %s, which configSettings lacks", file, name)
+			}
+		}
+	}
+}
+
+func TestValidateConfigFile(t *testing.T) {
+	// Start from an environment without settings
+	for name := range configSettings {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+
+	t.Setenv(configFileEnv, "")
+	t.Setenv(profileEnv, "")
+	t.Setenv("UNRELATED_SETTING", "")
+	t.Setenv("GOOGLE_SEARCH_SAFESEARCH", "")
+
+	tests := []struct {
+		name    string
+		content string
+		valid   bool
+	}{
+		{"valid", "GOOGLE_API_KEY=key\nGOOGLE_SEARCH_ENGINE_ID=cx\nGOOGLE_SEARCH_SAFE=active\nUNRELATED_SETTING=1\n", true},
+		{"missing credentials", "GOOGLE_SEARCH_SAFE=active\n", false},
+		{"misspelled setting", "GOOGLE_API_KEY=key\nGOOGLE_SEARCH_ENGINE_ID=cx\nGOOGLE_SEARCH_SAFESEARCH=active\n", false},
+		{"bad value", "GOOGLE_API_KEY=key\nGOOGLE_SEARCH_ENGINE_ID=cx\nGOOGLE_SEARCH_KEEP_WARM=often\n", false},
+		{"conflict", "GOOGLE_API_KEY=key\nGOOGLE_SEARCH_ENGINE_ID=cx\n" +
+			"GOOGLE_SEARCH_TRUSTED_DOMAINS=example.com,go.dev\nGOOGLE_SEARCH_QUESTIONABLE_DOMAINS=Example.com\n", false},
+		{"syntax error", "GOOGLE_API_KEY='key\n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.env")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			// Forget the settings the previous file loaded
+			for name := range configSettings {
+				os.Unsetenv(name)
+			}
+
+			report := &doctorReport{}
+			validateConfigFile(report, path, "", true)
+
+			if report.failed == tt.valid {
+				t.Errorf("failed = %v, want %v", report.failed, !tt.valid)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultWorkers    = 32
+	defaultQueueDepth = 128
+	// shedRetryAfter is the Retry-After hint sent with shed requests.
+	shedRetryAfter = time.Second
+)
+
+// workerPool bounds how many MCP messages are processed at once. Messages
+// beyond the workers wait in a bounded queue; when the queue is full they are
+// shed with 503 and Retry-After so bursts degrade gracefully instead of
+// piling up. It is safe for concurrent use.
+type workerPool struct {
+	name   string
+	slots  chan struct{}
+	queue  chan struct{}
+	active atomic.Int64
+	queued atomic.Int64
+	done   atomic.Int64
+	shed   atomic.Int64
+}
+
+// newWorkerPool creates a pool with the given number of workers and queue depth.
+func newWorkerPool(name string, workers, queueDepth int) *workerPool {
+	return &workerPool{
+		name:  name,
+		slots: make(chan struct{}, workers),
+		queue: make(chan struct{}, queueDepth),
+	}
+}
+
+// wrap runs next's POST requests (MCP messages) on the pool. Other requests,
+// such as the long-lived SSE stream, bypass it.
+func (p *workerPool) wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		if !p.acquire(r) {
+			p.shed.Add(1)
+			w.Header().Set("Retry-After", strconv.Itoa(int(shedRetryAfter.Seconds())))
+			http.Error(w, "server overloaded, retry later", http.StatusServiceUnavailable)
+
+			return
+		}
+		defer p.release()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// acquire takes a worker, waiting in the queue if all are busy. It reports
+// false if the queue is full or the client gave up while waiting.
+func (p *workerPool) acquire(r *http.Request) bool {
+	select {
+	case p.slots <- struct{}{}:
+		p.active.Add(1)
+
+		return true
+	default:
+	}
+
+	select {
+	case p.queue <- struct{}{}:
+	default:
+		return false
+	}
+
+	p.queued.Add(1)
+	defer func() {
+		<-p.queue
+		p.queued.Add(-1)
+	}()
+
+	select {
+	case p.slots <- struct{}{}:
+		p.active.Add(1)
+
+		return true
+	case <-r.Context().Done():
+		return false
+	}
+}
+
+// release returns a worker to the pool.
+func (p *workerPool) release() {
+	p.active.Add(-1)
+	p.done.Add(1)
+	<-p.slots
+}
+
+// handleMetrics serves the pool's metrics in the Prometheus text format.
+func (p *workerPool) handleMetrics(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	for _, metric := range []struct {
+		name, kind, help string
+		value            int64
+	}{
+		{"mcp_pool_workers", "gauge", "Number of workers.", int64(cap(p.slots))},
+		{"mcp_pool_queue_capacity", "gauge", "Maximum number of queued messages.", int64(cap(p.queue))},
+		{"mcp_pool_active", "gauge", "Messages being processed.", p.active.Load()},
+		{"mcp_pool_queued", "gauge", "Messages waiting for a worker.", p.queued.Load()},
+		{"mcp_pool_completed_total", "counter", "Messages processed.", p.done.Load()},
+		{"mcp_pool_shed_total", "counter", "Messages rejected with 503 because the pool was overloaded.", p.shed.Load()},
+	} {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s{pool=%q} %d\n",
+			metric.name, metric.help, metric.name, metric.kind, metric.name, p.name, metric.value)
+	}
+}